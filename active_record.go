@@ -1,8 +1,11 @@
 package goar
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
@@ -24,6 +27,16 @@ type Persister interface {
 	DbSearch(results interface{}) error
 }
 
+// UnsupportedError is returned by an adapter's stubs, so callers don't mistake them for empty results
+type UnsupportedError struct {
+	Adapter   string // EX: cloudant
+	Operation string // EX: DbSearch
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("%s does not support %s", e.Adapter, e.Operation)
+}
+
 type RDBMSer interface {
 	SpExecResultSet(spName string, params map[string]interface{}, results interface{}) error
 }
@@ -58,8 +71,14 @@ type Timestamps struct {
 
 type ActiveRecord struct {
 	validations.Validation
-	self  ActiveRecordInterfacer
-	query *Query
+	self     ActiveRecordInterfacer
+	query    *Query
+	connName string
+	connEnv  string
+	ctx      context.Context
+
+	validationContexts []string // named contexts passed to SaveWithContext
+	errorCodes         map[*validations.ValidationError]errorCode
 }
 
 func (ar *ActiveRecord) ModelName() string {
@@ -140,6 +159,11 @@ func (ar *ActiveRecord) Order(orderBy OrderBy) *ActiveRecord {
 	return ar
 }
 
+func (ar *ActiveRecord) Limit(limit int) *ActiveRecord {
+	ar.Query().Limit = strconv.Itoa(limit)
+	return ar
+}
+
 func (ar *ActiveRecord) Run(results interface{}) error {
 	err := ar.Self().(Persister).DbSearch(results)
 	if err == nil {
@@ -151,6 +175,7 @@ func (ar *ActiveRecord) Run(results interface{}) error {
 }
 
 func (ar *ActiveRecord) Valid() bool {
+	ar.validateTags() // goar struct tags are applied before the model's own validations
 	ar.self.Validate()
 	return !ar.Validation.HasErrors()
 }
@@ -166,6 +191,13 @@ func (ar *ActiveRecord) Save() (success bool, err error) {
 		return false, err
 	}
 
+	if err = checkOutbox(ar.self); err != nil {
+		return false, err
+	}
+	if err = checkVersioned(ar.self); err != nil {
+		return false, err
+	}
+
 	if ar.Valid() {
 		// set timestamps
 		//  1) CreatedAt is set upon create
@@ -215,6 +247,14 @@ func (ar *ActiveRecord) Save() (success bool, err error) {
 
 		// error handling
 		if err == nil {
+			// NOTE: a versioning error is returned after the bookkeeping b/c the record was saved,
+			// so the cache must not keep serving the old version
+			err = recordVersion(ar.self, false)
+
+			if cacheErr := Invalidate(ar.self); cacheErr != nil {
+				log.Println(cacheErr) // don't return the error at this point b/c the db operation was successful
+			}
+
 			if afterSaveErr := Callback("AfterSave", e.Addr(), nil); afterSaveErr != nil {
 				log.Println(afterSaveErr) // don't return the error at this point b/c the db operation was successful
 			}
@@ -224,7 +264,23 @@ func (ar *ActiveRecord) Save() (success bool, err error) {
 	return !ar.Validation.HasErrors() && err == nil, err
 }
 func (ar *ActiveRecord) Delete() error {
-	return ar.self.(Persister).DbDelete()
+	if err := checkOutbox(ar.self); err != nil {
+		return err
+	}
+	if err := checkVersioned(ar.self); err != nil {
+		return err
+	}
+
+	err := ar.self.(Persister).DbDelete()
+	if err == nil {
+		err = recordVersion(ar.self, true) // the record was deleted, but the audit trail may be incomplete
+
+		if cacheErr := Invalidate(ar.self); cacheErr != nil {
+			log.Println(cacheErr) // don't return the error at this point b/c the db operation was successful
+		}
+	}
+
+	return err
 }
 
 func Callback(name string, eptr reflect.Value, arg []reflect.Value) error {
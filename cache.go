@@ -0,0 +1,199 @@
+package goar
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"sync"
+)
+
+// Cache stores serialized models by key.  Implementations decide how long
+// entries live, EX: cache.NewLRU(10000, time.Minute) or cache.NewRedis(...)
+type Cache interface {
+	Get(key string) (value []byte, found bool, err error)
+	Set(key string, value []byte) error
+	Delete(key string) error
+}
+
+// Cacher is implemented by models whose Find should read through a cache.
+// Save and Delete invalidate the cached entry.
+type Cacher interface {
+	Cache() Cache
+}
+
+var cacheLoads = &loadGroup{calls: map[string]*loadCall{}}
+
+// ReadThrough is called by the adapters' Find methods.  It returns the cached
+// copy of the record when one exists, otherwise it calls load and caches the
+// result.  Concurrent misses for the same key are collapsed into a single load.
+func ReadThrough(ari ActiveRecordInterfacer, id interface{}, out interface{}, load func() error) error {
+	c := modelCache(ari)
+	if c == nil {
+		return load()
+	}
+
+	key := cacheKey(ari, fmt.Sprint(id))
+	if value, found, err := c.Get(key); err != nil {
+		log.Println("goar cache get failed:", err) // fall through to the db
+	} else if found {
+		if err = json.Unmarshal(value, out); err == nil {
+			return nil
+		}
+		log.Println("goar cache entry could not be decoded:", err)
+	}
+
+	loaded := false
+	value, err := cacheLoads.do(key, func() ([]byte, error) {
+		loaded = true
+		if err := load(); err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(out)
+	}, func(value []byte) {
+		if err := c.Set(key, value); err != nil {
+			log.Println("goar cache set failed:", err)
+		}
+	})
+
+	if err != nil || loaded {
+		return err
+	}
+
+	return json.Unmarshal(value, out)
+}
+
+// Invalidate removes the model's cached entry, if any
+func Invalidate(ari ActiveRecordInterfacer) error {
+	c := modelCache(ari)
+	if c == nil {
+		return nil
+	}
+
+	id, ok := modelID(ari)
+	if !ok {
+		return nil
+	}
+
+	key := cacheKey(ari, id)
+	cacheLoads.forget(key) // a load that's running may have read the old record
+
+	return c.Delete(key)
+}
+
+func modelCache(ari ActiveRecordInterfacer) Cache {
+	if c, ok := ari.(Cacher); ok {
+		return c.Cache()
+	}
+
+	return nil
+}
+
+// cacheKey scopes the id by environment, connection and model so tenants can't read each other's entries.
+// The connection is the finder's, see finderOf, so Save and Delete invalidate the key Find read.
+func cacheKey(ari ActiveRecordInterfacer, id string) string {
+	connName, env := ResolveConnection(finderOf(ari))
+	if s, ok := ari.(Sharder); ok { // Find routes sharded models by id
+		connName = s.ShardMap().Shard(id)
+	}
+
+	return env + ":" + connName + ":" + ari.ModelName() + ":" + id
+}
+
+// finderOf returns a blank model like the one Find is called on, EX: ToAR(&Vehicle{}), w/ the
+// record's connection override and context, which are all a finder has to route by
+func finderOf(ari ActiveRecordInterfacer) ActiveRecordInterfacer {
+	t := reflect.TypeOf(ari)
+	if t.Kind() != reflect.Ptr {
+		return ari
+	}
+
+	finder, ok := reflect.New(t.Elem()).Interface().(ActiveRecordInterfacer)
+	if !ok {
+		return ari
+	}
+	ToAR(finder)
+
+	ar, ok := activeRecordOf(ari)
+	far, fok := activeRecordOf(finder)
+	if ok && fok {
+		far.connName, far.connEnv, far.ctx = ar.connName, ar.connEnv, ar.ctx
+	}
+
+	return finder
+}
+
+func modelID(ari ActiveRecordInterfacer) (string, bool) {
+	v := reflect.ValueOf(ari)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	f := v.FieldByName("ID")
+	if !f.IsValid() || f.Interface() == reflect.Zero(f.Type()).Interface() {
+		return "", false
+	}
+
+	return fmt.Sprint(f.Interface()), true
+}
+
+// loadGroup collapses concurrent loads of the same key into a single call
+type loadGroup struct {
+	mutex sync.Mutex
+	calls map[string]*loadCall
+}
+
+type loadCall struct {
+	wg    sync.WaitGroup
+	value []byte
+	err   error
+	stale bool // invalidated while loading, so it isn't stored
+}
+
+// do calls fn once for concurrent callers, then store unless the key was forgotten meanwhile.
+// NOTE: store runs under the group's mutex, so forget can't slip in between the check and the Set.
+func (g *loadGroup) do(key string, fn func() ([]byte, error), store func(value []byte)) ([]byte, error) {
+	g.mutex.Lock()
+	if call, found := g.calls[key]; found {
+		g.mutex.Unlock()
+		call.wg.Wait()
+		return call.value, call.err
+	}
+
+	call := &loadCall{}
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mutex.Unlock()
+
+	defer func() {
+		g.mutex.Lock()
+		if g.calls[key] == call {
+			delete(g.calls, key)
+		}
+		g.mutex.Unlock()
+		call.wg.Done()
+	}()
+
+	call.value, call.err = fn()
+	if call.err == nil {
+		g.mutex.Lock()
+		if !call.stale {
+			store(call.value)
+		}
+		g.mutex.Unlock()
+	}
+
+	return call.value, call.err
+}
+
+// forget marks the key's running load as stale, so it isn't stored and later callers load again
+func (g *loadGroup) forget(key string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if call, found := g.calls[key]; found {
+		call.stale = true
+		delete(g.calls, key)
+	}
+}
@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRU is an in-memory cache that evicts the least recently used entry once
+// it holds capacity entries.  Entries also expire after ttl (0 => never).
+type LRU struct {
+	capacity int
+	ttl      time.Duration
+	mutex    sync.Mutex
+	entries  *list.List
+	index    map[string]*list.Element
+	now      func() time.Time // this facilitates unit testing
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+func NewLRU(capacity int, ttl time.Duration) *LRU {
+	return &LRU{
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  list.New(),
+		index:    map[string]*list.Element{},
+		now:      time.Now,
+	}
+}
+
+func (c *LRU) Get(key string) ([]byte, bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, found := c.index[key]
+	if !found {
+		return nil, false, nil
+	}
+
+	entry := element.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && c.now().After(entry.expiresAt) {
+		c.remove(element)
+		return nil, false, nil
+	}
+
+	c.entries.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+func (c *LRU) Set(key string, value []byte) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var expiresAt time.Time
+	if c.ttl > 0 {
+		expiresAt = c.now().Add(c.ttl)
+	}
+
+	if element, found := c.index[key]; found {
+		entry := element.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.entries.MoveToFront(element)
+		return nil
+	}
+
+	c.index[key] = c.entries.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	for c.capacity > 0 && c.entries.Len() > c.capacity {
+		c.remove(c.entries.Back())
+	}
+
+	return nil
+}
+
+func (c *LRU) Delete(key string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, found := c.index[key]; found {
+		c.remove(element)
+	}
+
+	return nil
+}
+
+// Len returns the number of entries, including expired entries that haven't been evicted yet
+func (c *LRU) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.entries.Len()
+}
+
+func (c *LRU) remove(element *list.Element) {
+	c.entries.Remove(element)
+	delete(c.index, element.Value.(*lruEntry).key)
+}
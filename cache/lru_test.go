@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LRU", func() {
+
+	var (
+		lru *LRU
+		now time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Now()
+		lru = NewLRU(2, time.Minute)
+		lru.now = func() time.Time { return now }
+	})
+
+	It("should get a cached value", func() {
+		Ω(lru.Set("a", []byte("porsche"))).Should(Succeed())
+
+		value, found, err := lru.Get("a")
+		Ω(err).NotTo(HaveOccurred())
+		Ω(found).Should(BeTrue())
+		Ω(string(value)).Should(Equal("porsche"))
+	})
+
+	It("should miss an unknown key", func() {
+		_, found, err := lru.Get("a")
+		Ω(err).NotTo(HaveOccurred())
+		Ω(found).Should(BeFalse())
+	})
+
+	It("should evict the least recently used entry", func() {
+		lru.Set("a", []byte("porsche"))
+		lru.Set("b", []byte("tesla"))
+		lru.Get("a") // a is now more recently used than b
+		lru.Set("c", []byte("austin healey"))
+
+		Ω(lru.Len()).Should(Equal(2))
+		_, found, _ := lru.Get("b")
+		Ω(found).Should(BeFalse())
+		_, found, _ = lru.Get("a")
+		Ω(found).Should(BeTrue())
+	})
+
+	It("should expire entries after the ttl", func() {
+		lru.Set("a", []byte("porsche"))
+
+		now = now.Add(59 * time.Second)
+		_, found, _ := lru.Get("a")
+		Ω(found).Should(BeTrue())
+
+		now = now.Add(2 * time.Second)
+		_, found, _ = lru.Get("a")
+		Ω(found).Should(BeFalse())
+		Ω(lru.Len()).Should(Equal(0))
+	})
+
+	It("should delete an entry", func() {
+		lru.Set("a", []byte("porsche"))
+		Ω(lru.Delete("a")).Should(Succeed())
+		Ω(lru.Delete("does not exist")).Should(Succeed())
+
+		_, found, _ := lru.Get("a")
+		Ω(found).Should(BeFalse())
+	})
+})
@@ -0,0 +1,198 @@
+package cache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// Redis is a cache backed by any server that speaks the redis protocol (RESP)
+type Redis struct {
+	Address  string
+	Password string
+	DB       int
+	Prefix   string        // prepended to every key, EX: "goar:"
+	TTL      time.Duration // 0 => entries never expire
+	Timeout  time.Duration // dial, read and write timeout
+	idle     chan *redisConn
+}
+
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewRedis keeps up to maxIdle connections open for reuse
+func NewRedis(address string, ttl time.Duration, maxIdle int) *Redis {
+	if maxIdle < 1 {
+		maxIdle = 1
+	}
+
+	return &Redis{
+		Address: address,
+		TTL:     ttl,
+		Timeout: 5 * time.Second,
+		idle:    make(chan *redisConn, maxIdle),
+	}
+}
+
+func (c *Redis) Get(key string) ([]byte, bool, error) {
+	reply, err := c.do("GET", c.Prefix+key)
+	if err != nil || reply == nil {
+		return nil, false, err
+	}
+
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, errors.New(fmt.Sprintf("redis GET returned an unexpected reply: %v", reply))
+	}
+
+	return value, true, nil
+}
+
+func (c *Redis) Set(key string, value []byte) (err error) {
+	if c.TTL > 0 {
+		_, err = c.do("SET", c.Prefix+key, string(value), "PX", strconv.FormatInt(int64(c.TTL/time.Millisecond), 10))
+	} else {
+		_, err = c.do("SET", c.Prefix+key, string(value))
+	}
+
+	return err
+}
+
+func (c *Redis) Delete(key string) error {
+	_, err := c.do("DEL", c.Prefix+key)
+	return err
+}
+
+// Close closes all idle connections
+func (c *Redis) Close() error {
+	for {
+		select {
+		case rc := <-c.idle:
+			rc.conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+func (c *Redis) do(args ...string) (interface{}, error) {
+	rc, err := c.conn()
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := rc.do(c.Timeout, args...)
+	if _, isServerErr := err.(redisError); err != nil && !isServerErr {
+		rc.conn.Close() // the connection's state is unknown, so don't reuse it
+		return nil, err
+	}
+
+	select {
+	case c.idle <- rc:
+	default:
+		rc.conn.Close()
+	}
+
+	return reply, err
+}
+
+func (c *Redis) conn() (*redisConn, error) {
+	select {
+	case rc := <-c.idle:
+		return rc, nil
+	default:
+	}
+
+	conn, err := net.DialTimeout("tcp", c.Address, c.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	rc := &redisConn{conn: conn, reader: bufio.NewReader(conn)}
+
+	if c.Password != "" {
+		if _, err = rc.do(c.Timeout, "AUTH", c.Password); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if c.DB != 0 {
+		if _, err = rc.do(c.Timeout, "SELECT", strconv.Itoa(c.DB)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return rc, nil
+}
+
+type redisError string
+
+func (e redisError) Error() string {
+	return string(e)
+}
+
+func (rc *redisConn) do(timeout time.Duration, args ...string) (interface{}, error) {
+	if timeout > 0 {
+		rc.conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	cmd := "*" + strconv.Itoa(len(args)) + "\r\n"
+	for _, arg := range args {
+		cmd += "$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n"
+	}
+	if _, err := io.WriteString(rc.conn, cmd); err != nil {
+		return nil, err
+	}
+
+	return readReply(rc.reader)
+}
+
+func readReply(reader *bufio.Reader) (interface{}, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, errors.New("redis protocol error: malformed reply")
+	}
+	line = line[:len(line)-2]
+
+	switch line[0] {
+	case '+': // simple string
+		return line[1:], nil
+	case '-': // error
+		return nil, redisError(line[1:])
+	case ':': // integer
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$': // bulk string
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err = io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*': // array
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		replies := make([]interface{}, n)
+		for i := range replies {
+			if replies[i], err = readReply(reader); err != nil {
+				return nil, err
+			}
+		}
+		return replies, nil
+	}
+
+	return nil, errors.New("redis protocol error: unexpected reply type " + line[:1])
+}
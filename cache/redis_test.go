@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeRedis is a local stand-in that speaks just enough RESP for the Redis cache
+type fakeRedis struct {
+	listener net.Listener
+	mutex    sync.Mutex
+	values   map[string]string
+	ttls     map[string]string
+}
+
+func newFakeRedis() *fakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).NotTo(HaveOccurred())
+
+	f := &fakeRedis{listener: listener, values: map[string]string{}, ttls: map[string]string{}}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+
+	return f
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	for {
+		reply, err := readReply(reader)
+		if err != nil {
+			return
+		}
+
+		args := []string{}
+		for _, arg := range reply.([]interface{}) {
+			args = append(args, string(arg.([]byte)))
+		}
+
+		f.mutex.Lock()
+		switch strings.ToUpper(args[0]) {
+		case "AUTH":
+			if args[1] == "secret" {
+				conn.Write([]byte("+OK\r\n"))
+			} else {
+				conn.Write([]byte("-ERR invalid password\r\n"))
+			}
+		case "GET":
+			if value, found := f.values[args[1]]; found {
+				conn.Write([]byte("$" + strconv.Itoa(len(value)) + "\r\n" + value + "\r\n"))
+			} else {
+				conn.Write([]byte("$-1\r\n"))
+			}
+		case "SET":
+			f.values[args[1]] = args[2]
+			if len(args) == 5 {
+				f.ttls[args[1]] = args[4]
+			}
+			conn.Write([]byte("+OK\r\n"))
+		case "DEL":
+			delete(f.values, args[1])
+			conn.Write([]byte(":1\r\n"))
+		default:
+			conn.Write([]byte("-ERR unknown command\r\n"))
+		}
+		f.mutex.Unlock()
+	}
+}
+
+var _ = Describe("Redis", func() {
+
+	var (
+		server *fakeRedis
+		redis  *Redis
+	)
+
+	BeforeEach(func() {
+		server = newFakeRedis()
+		redis = NewRedis(server.listener.Addr().String(), time.Minute, 2)
+		redis.Prefix = "goar:"
+	})
+
+	AfterEach(func() {
+		redis.Close()
+		server.listener.Close()
+	})
+
+	It("should set and get a value", func() {
+		Ω(redis.Set("vehicles:1", []byte(`{"make":"porsche"}`))).Should(Succeed())
+		Ω(server.values).Should(HaveKey("goar:vehicles:1"))
+		Ω(server.ttls["goar:vehicles:1"]).Should(Equal("60000"))
+
+		value, found, err := redis.Get("vehicles:1")
+		Ω(err).NotTo(HaveOccurred())
+		Ω(found).Should(BeTrue())
+		Ω(string(value)).Should(Equal(`{"make":"porsche"}`))
+	})
+
+	It("should miss an unknown key", func() {
+		_, found, err := redis.Get("vehicles:2")
+		Ω(err).NotTo(HaveOccurred())
+		Ω(found).Should(BeFalse())
+	})
+
+	It("should delete a value", func() {
+		redis.Set("vehicles:1", []byte("porsche"))
+		Ω(redis.Delete("vehicles:1")).Should(Succeed())
+
+		_, found, _ := redis.Get("vehicles:1")
+		Ω(found).Should(BeFalse())
+	})
+
+	It("should reuse idle connections", func() {
+		redis.Set("vehicles:1", []byte("porsche"))
+		Ω(len(redis.idle)).Should(Equal(1))
+		redis.Get("vehicles:1")
+		Ω(len(redis.idle)).Should(Equal(1))
+	})
+
+	It("should authenticate", func() {
+		redis.Password = "secret"
+		Ω(redis.Set("vehicles:1", []byte("porsche"))).Should(Succeed())
+
+		other := NewRedis(server.listener.Addr().String(), 0, 1)
+		other.Password = "wrong"
+		err := other.Set("vehicles:1", []byte("porsche"))
+		Ω(err).To(HaveOccurred())
+		Ω(err.Error()).Should(Equal("ERR invalid password"))
+	})
+
+	It("should return an error when the server is unreachable", func() {
+		server.listener.Close()
+		unreachable := NewRedis(server.listener.Addr().String(), 0, 1)
+		_, _, err := unreachable.Get("vehicles:1")
+		Ω(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,176 @@
+package goar
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/obieq/goar/cache"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var automobileCache = cache.NewLRU(100, time.Minute)
+
+type CachedAutomobile struct {
+	ActiveRecordAutomobile
+	ID string `json:"id,omitempty"`
+}
+
+var cachedAutomobileDb = struct {
+	sync.Mutex
+	rows  map[string]CachedAutomobile
+	loads int
+}{rows: map[string]CachedAutomobile{}}
+
+func (m *CachedAutomobile) Cache() Cache {
+	return automobileCache
+}
+
+func (m *CachedAutomobile) Find(id interface{}, out interface{}) error {
+	return ReadThrough(m, id, out, func() error {
+		cachedAutomobileDb.Lock()
+		defer cachedAutomobileDb.Unlock()
+
+		cachedAutomobileDb.loads++
+		row, found := cachedAutomobileDb.rows[id.(string)]
+		if !found {
+			return errors.New("record not found")
+		}
+		*out.(*CachedAutomobile) = row
+		return nil
+	})
+}
+
+func (m *CachedAutomobile) DbSave() error {
+	cachedAutomobileDb.Lock()
+	defer cachedAutomobileDb.Unlock()
+
+	cachedAutomobileDb.rows[m.ID] = *m
+	return nil
+}
+
+func (m *CachedAutomobile) DbDelete() error {
+	cachedAutomobileDb.Lock()
+	defer cachedAutomobileDb.Unlock()
+
+	delete(cachedAutomobileDb.rows, m.ID)
+	return nil
+}
+
+var _ = Describe("Cache", func() {
+
+	var auto *CachedAutomobile
+
+	loads := func() int {
+		cachedAutomobileDb.Lock()
+		defer cachedAutomobileDb.Unlock()
+		return cachedAutomobileDb.loads
+	}
+
+	BeforeEach(func() {
+		cachedAutomobileDb.loads = 0
+		auto = ToAR(&CachedAutomobile{ID: "carrera"}).(*CachedAutomobile)
+		auto.Year, auto.Make, auto.Model = 2007, "porsche", "carrera gt"
+		Ω(auto.Save()).Should(BeTrue())
+	})
+
+	It("should read through the cache", func() {
+		var out CachedAutomobile
+		Ω(ToAR(&CachedAutomobile{}).Find("carrera", &out)).Should(Succeed())
+		Ω(out.Model).Should(Equal("carrera gt"))
+
+		out = CachedAutomobile{}
+		Ω(ToAR(&CachedAutomobile{}).Find("carrera", &out)).Should(Succeed())
+		Ω(out.Model).Should(Equal("carrera gt"))
+		Ω(loads()).Should(Equal(1))
+	})
+
+	It("should invalidate the cache on save", func() {
+		var out CachedAutomobile
+		ToAR(&CachedAutomobile{}).Find("carrera", &out)
+
+		auto.Model = "918 spyder"
+		Ω(auto.Save()).Should(BeTrue())
+
+		out = CachedAutomobile{}
+		ToAR(&CachedAutomobile{}).Find("carrera", &out)
+		Ω(out.Model).Should(Equal("918 spyder"))
+		Ω(loads()).Should(Equal(2))
+	})
+
+	It("should invalidate the cache on delete", func() {
+		var out CachedAutomobile
+		ToAR(&CachedAutomobile{}).Find("carrera", &out)
+
+		Ω(auto.Delete()).Should(Succeed())
+
+		err := ToAR(&CachedAutomobile{}).Find("carrera", &out)
+		Ω(err).To(HaveOccurred())
+	})
+
+	It("should invalidate the key Find read when the record is routed by its fields", func() {
+		SetConnectionResolver(func(ari ActiveRecordInterfacer) (string, string) {
+			if auto, ok := ari.(*CachedAutomobile); ok && auto.Make != "" {
+				return "tenant_" + auto.Make, ""
+			}
+			return "", ""
+		})
+		defer SetConnectionResolver(nil)
+
+		var out CachedAutomobile
+		ToAR(&CachedAutomobile{}).Find("carrera", &out)
+
+		auto.Model = "918 spyder"
+		Ω(auto.Save()).Should(BeTrue())
+
+		out = CachedAutomobile{}
+		ToAR(&CachedAutomobile{}).Find("carrera", &out)
+		Ω(out.Model).Should(Equal("918 spyder"))
+	})
+
+	It("should not cache a load that was invalidated while it ran", func() {
+		cachedAutomobileDb.Lock() // hold the db so the load is running when the record is invalidated
+
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			var out CachedAutomobile
+			Ω(ToAR(&CachedAutomobile{}).Find("carrera", &out)).Should(Succeed())
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+		Ω(Invalidate(auto)).Should(Succeed())
+		cachedAutomobileDb.Unlock()
+		<-done
+
+		var out CachedAutomobile
+		Ω(ToAR(&CachedAutomobile{}).Find("carrera", &out)).Should(Succeed())
+		Ω(loads()).Should(Equal(2))
+	})
+
+	It("should collapse concurrent misses into one load", func() {
+		cachedAutomobileDb.Lock() // hold the db so every reader misses at the same time
+
+		var wg sync.WaitGroup
+		results := make([]CachedAutomobile, 10)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				Ω(ToAR(&CachedAutomobile{}).Find("carrera", &results[i])).Should(Succeed())
+			}(i)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+		cachedAutomobileDb.Unlock()
+		wg.Wait()
+
+		Ω(loads()).Should(Equal(1))
+		for _, result := range results {
+			Ω(result.Model).Should(Equal("carrera gt"))
+		}
+	})
+})
@@ -0,0 +1,206 @@
+// Package cli implements the goar command.  cmd/goar registers every adapter, but the
+// migrate, schema:diff, db:truncate, db:seed and console commands only see what's compiled in,
+// so an app builds its own goar with its migrations and models, EX:
+//
+//	import _ "myapp/migrations"
+//
+//	func main() {
+//		cli.Register(goar.RETHINKDB, cli.Adapter{...})
+//		schema.Register(&models.Vehicle{})
+//		if err := cli.Run(os.Args[1:], os.Stdout); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	goar "github.com/obieq/goar"
+	"github.com/obieq/goar/migration"
+)
+
+const usage = `usage: goar <command> [args] [--adapter=<adapter>] [--env=<env>] [--conn=default]
+
+commands:
+  generate model <Name> [field:type ...]   writes the model and its ginkgo spec, EX: generate model Vehicle year:int make:string
+  db:create                                creates the connection's database
+  db:drop                                  drops the connection's database
+  db:truncate                              truncates every model registered via schema.Register
+  db:seed [--truncate] [path ...]          saves the fixtures in db/seeds, or the given paths
+  migrate [up|down|status|redo] [n]        runs the registered migrations, up by default
+  schema:diff [migration file]             compares the registered models with the database
+  ping                                     checks the connection
+  health [--timeout=10s]                   checks every connection in the environment, see goar.HealthCheck
+  config:check                             validates the config, see goar.Configuration
+  console                                  finds and queries the models registered via schema.Register`
+
+// Adapter wires an adapter into the goar command
+type Adapter struct {
+	Import string // EX: github.com/obieq/goar/db/rethinkdb
+	Model  string // the type models embed, EX: rethinkdb.ArRethinkDb
+	Driver func(connName string, env string) migration.Driver
+	// MaintenanceDriver runs db:create and db:drop, EX: connected to postgres' postgres database,
+	// for adapters that can't create or drop the database they're connected to.  Driver by default.
+	MaintenanceDriver func(connName string, env string) migration.Driver
+}
+
+var (
+	adapters      = map[string]Adapter{}
+	adaptersMutex sync.RWMutex
+)
+
+// Register adds an adapter, named by its config.json section, EX: goar.RETHINKDB
+func Register(name string, a Adapter) {
+	adaptersMutex.Lock()
+	defer adaptersMutex.Unlock()
+
+	adapters[name] = a
+}
+
+// options are the --name=value args
+type options map[string]string
+
+// Run runs a goar command, writing its output to out
+func Run(args []string, out io.Writer) error {
+	positional, opts := parse(args)
+	if len(positional) == 0 {
+		return fmt.Errorf(usage)
+	}
+
+	switch command, rest := positional[0], positional[1:]; command {
+	case "generate":
+		return generate(rest, opts, out)
+	case "db:create", "db:drop", "db:truncate", "db:seed", "migrate", "schema:diff", "ping":
+		return runTask(command, rest, opts, out)
+	case "health":
+		return health(opts, out)
+	case "config:check":
+		return checkConfig(out)
+	case "console":
+		return console(opts, out)
+	}
+
+	return fmt.Errorf("unknown command %q\n%s", positional[0], usage)
+}
+
+// parse splits the args into positional ones and options, which can appear anywhere, EX: --adapter=rethinkdb
+func parse(args []string) ([]string, options) {
+	positional, opts := []string{}, options{}
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--") {
+			positional = append(positional, arg)
+			continue
+		}
+
+		arg = strings.TrimPrefix(arg, "--")
+		if i := strings.Index(arg, "="); i >= 0 {
+			opts[arg[:i]] = arg[i+1:]
+		} else {
+			opts[arg] = "true"
+		}
+	}
+
+	return positional, opts
+}
+
+func (o options) get(name string, defaultValue string) string {
+	if v, ok := o[name]; ok && v != "" {
+		return v
+	}
+
+	return defaultValue
+}
+
+// adapter returns the named adapter, or the only one with a connection when no name is given
+func adapter(name string, connName string, env string) (string, Adapter, error) {
+	adaptersMutex.RLock()
+	defer adaptersMutex.RUnlock()
+
+	if name != "" {
+		a, ok := adapters[name]
+		if !ok {
+			return "", a, fmt.Errorf("unknown adapter %q, expected one of: %s", name, strings.Join(adapterNames(), ", "))
+		}
+		return name, a, nil
+	}
+
+	found := []string{}
+	for _, n := range adapterNames() {
+		if configured(n, connName, env) {
+			found = append(found, n)
+		}
+	}
+
+	if len(found) != 1 {
+		return "", Adapter{}, fmt.Errorf("%d adapters have a %s connection in %s, so pick one via --adapter=<%s>",
+			len(found), connName, env, strings.Join(adapterNames(), "|"))
+	}
+
+	return found[0], adapters[found[0]], nil
+}
+
+func adapterNames() []string {
+	names := []string{}
+	for name := range adapters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// configured checks goar.Config for the connection, EX: test_rethinkdb_aws
+func configured(adapterName string, connName string, env string) bool {
+	key := env + "_" + adapterName + "_" + connName
+	c := goar.Configuration()
+
+	var ok bool
+	switch adapterName {
+	case goar.MSSQL:
+		_, ok = c.MSSQLDBs[key]
+	case goar.RETHINKDB:
+		_, ok = c.RethinkDBs[key]
+	case goar.POSTGRESQL:
+		_, ok = c.PostgresqlDBs[key]
+	case goar.DYNAMODB:
+		_, ok = c.DynamoDBs[key]
+	case goar.ORCHESTRATE:
+		_, ok = c.OrchestrateDBs[key]
+	case goar.COUCHBASE:
+		_, ok = c.CouchbaseDBs[key]
+	}
+
+	return ok
+}
+
+// dbName returns the configured database, or bucket, of the connection
+func dbName(adapterName string, connName string, env string) string {
+	key := env + "_" + adapterName + "_" + connName
+	c := goar.Configuration()
+
+	switch adapterName {
+	case goar.MSSQL:
+		if m, ok := c.MSSQLDBs[key]; ok {
+			return m.DBName
+		}
+	case goar.RETHINKDB:
+		if m, ok := c.RethinkDBs[key]; ok {
+			return m.DBName
+		}
+	case goar.POSTGRESQL:
+		if m, ok := c.PostgresqlDBs[key]; ok {
+			return m.DBName
+		}
+	case goar.COUCHBASE:
+		if m, ok := c.CouchbaseDBs[key]; ok {
+			return m.BucketName
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+
+	goar "github.com/obieq/goar"
+	"github.com/obieq/goar/migration"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestCli(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cli Suite")
+}
+
+// the specs' connections, so they don't depend on a config.json
+var _ = BeforeSuite(func() {
+	Ω(goar.Configure(goar.Options{
+		Environment: "test",
+		RethinkDBs: map[string]*goar.RethinkDBConfig{
+			"aws": {Addresses: []string{"localhost:28015"}, DBName: "goar_test", MaxIdleConnections: 1, MaxOpenConnections: 5},
+		},
+		PostgresqlDBs: map[string]*goar.PostgresqlDBConfig{
+			"aws": {Server: "localhost", Port: 5432, DBName: "goar_test", MaxIdleConnections: 1, MaxOpenConnections: 5},
+		},
+	})).Should(Succeed())
+})
+
+// memoryDriver records the db tasks run against it
+type memoryDriver struct {
+	connName string
+	env      string
+	created  []string
+	dropped  []string
+	versions []int64
+	pingErr  error
+}
+
+func (d *memoryDriver) CreateDb(dbName string) error {
+	d.created = append(d.created, dbName)
+	return nil
+}
+
+func (d *memoryDriver) DropDb(dbName string) error {
+	d.dropped = append(d.dropped, dbName)
+	return nil
+}
+
+func (d *memoryDriver) CreateTable(tableName string) error { return nil }
+func (d *memoryDriver) DropTable(tableName string) error   { return nil }
+func (d *memoryDriver) AddIndex(tableName string, fields []string, opts map[string]interface{}) error {
+	return nil
+}
+func (d *memoryDriver) EnsureLedger() error               { return nil }
+func (d *memoryDriver) AppliedVersions() ([]int64, error) { return d.versions, nil }
+func (d *memoryDriver) RecordVersion(version int64) error { return nil }
+func (d *memoryDriver) RemoveVersion(version int64) error { return nil }
+func (d *memoryDriver) Ping() error                       { return d.pingErr }
+
+var (
+	driver *memoryDriver
+	_      migration.Driver = driver
+)
+
+// registerMemoryAdapter registers the memory driver as the rethinkdb adapter
+func registerMemoryAdapter() {
+	driver = &memoryDriver{}
+	Register("rethinkdb", Adapter{
+		Import: "github.com/obieq/goar/db/rethinkdb",
+		Model:  "rethinkdb.ArRethinkDb",
+		Driver: func(connName string, env string) migration.Driver {
+			if connName == "unreachable" {
+				panic("could not connect")
+			}
+			driver.connName, driver.env = connName, env
+			return driver
+		},
+	})
+}
+
+var errPing = errors.New("connection refused")
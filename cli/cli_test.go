@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+
+	goar "github.com/obieq/goar"
+	"github.com/obieq/goar/migration"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cli", func() {
+	var out *bytes.Buffer
+
+	BeforeEach(func() {
+		registerMemoryAdapter()
+		out = &bytes.Buffer{}
+	})
+
+	It("should require a known command", func() {
+		Ω(Run([]string{}, out)).Should(MatchError(usage))
+		Ω(Run([]string{"deploy"}, out)).Should(HaveOccurred())
+	})
+
+	It("should parse options anywhere", func() {
+		positional, opts := parse([]string{"migrate", "--env=qa", "down", "--force", "2"})
+		Ω(positional).Should(Equal([]string{"migrate", "down", "2"}))
+		Ω(opts).Should(Equal(options{"env": "qa", "force": "true"}))
+	})
+
+	It("should require a known adapter", func() {
+		Ω(Run([]string{"ping", "--adapter=oracle"}, out)).Should(HaveOccurred())
+	})
+
+	It("should require an adapter when it can't pick the connection's", func() {
+		Ω(Run([]string{"ping", "--conn=nowhere"}, out)).Should(HaveOccurred())
+	})
+
+	It("should ping the connection", func() {
+		Ω(Run([]string{"ping", "--adapter=rethinkdb", "--conn=aws"}, out)).Should(Succeed())
+		Ω(out.String()).Should(Equal("ok rethinkdb " + goar.Configuration().Environment + "/aws\n"))
+
+		driver.pingErr = errPing
+		Ω(Run([]string{"ping", "--adapter=rethinkdb"}, out)).Should(Equal(errPing))
+	})
+
+	It("should return connection panics as errors", func() {
+		err := Run([]string{"ping", "--adapter=rethinkdb", "--conn=unreachable"}, out)
+		Ω(err).Should(MatchError("could not connect to " + goar.Configuration().Environment + "/unreachable: could not connect"))
+	})
+
+	It("should create and drop the connection's database", func() {
+		Ω(Run([]string{"db:create", "--adapter=rethinkdb", "--conn=nowhere"}, out)).Should(Succeed())
+		Ω(driver.created).Should(HaveLen(1))
+		Ω(Run([]string{"db:drop", "--adapter=rethinkdb", "--conn=nowhere"}, out)).Should(Succeed())
+		Ω(driver.dropped).Should(HaveLen(1))
+		Ω(driver.connName).Should(Equal("nowhere"))
+	})
+
+	It("should create and drop via the maintenance driver when there is one", func() {
+		maintenance := &memoryDriver{}
+		Register("rethinkdb", Adapter{
+			Driver: func(connName string, env string) migration.Driver { return driver },
+			MaintenanceDriver: func(connName string, env string) migration.Driver {
+				maintenance.connName = connName
+				return maintenance
+			},
+		})
+
+		Ω(Run([]string{"db:create", "--adapter=rethinkdb", "--conn=aws"}, out)).Should(Succeed())
+		Ω(Run([]string{"db:drop", "--adapter=rethinkdb", "--conn=aws"}, out)).Should(Succeed())
+		Ω(maintenance.created).Should(Equal([]string{"goar_test"}))
+		Ω(maintenance.dropped).Should(Equal([]string{"goar_test"}))
+		Ω(maintenance.connName).Should(Equal("aws"))
+		Ω(driver.created).Should(BeEmpty())
+
+		Ω(Run([]string{"migrate", "--adapter=rethinkdb"}, out)).Should(Succeed())
+		Ω(maintenance.versions).Should(BeEmpty())
+	})
+
+	It("should refuse to drop a protected environment without --force", func() {
+		Ω(Run([]string{"db:drop", "--adapter=rethinkdb", "--env=production"}, out)).Should(HaveOccurred())
+		Ω(driver.dropped).Should(BeEmpty())
+
+		Ω(Run([]string{"db:drop", "--adapter=rethinkdb", "--env=production", "--force"}, out)).Should(Succeed())
+		Ω(driver.env).Should(Equal("production"))
+
+		Ω(Run([]string{"db:truncate", "--env=Production"}, out)).Should(MatchError("refusing to db:truncate the Production environment without --force"))
+	})
+
+	It("should migrate up by default", func() {
+		Ω(Run([]string{"migrate", "--adapter=rethinkdb"}, out)).Should(Succeed())
+		Ω(Run([]string{"migrate", "status", "--adapter=rethinkdb"}, out)).Should(Succeed())
+		Ω(Run([]string{"migrate", "sideways", "--adapter=rethinkdb"}, out)).Should(HaveOccurred())
+	})
+
+	It("should require an inspector to diff the schema", func() {
+		Ω(Run([]string{"schema:diff", "--adapter=rethinkdb"}, out)).Should(MatchError("the rethinkdb adapter can't inspect its schema"))
+	})
+
+	It("should require registered models to truncate", func() {
+		Ω(Run([]string{"db:truncate"}, out)).Should(MatchError("no models are registered, see schema.Register"))
+	})
+
+	It("should check the health of every connection", func() {
+		var healthErr error
+		goar.RegisterHealthCheck(goar.RETHINKDB, func(ctx context.Context, connName string, env string) error { return healthErr })
+		env := goar.Configuration().Environment
+
+		Ω(Run([]string{"health"}, out)).Should(Succeed())
+		Ω(out.String()).Should(MatchRegexp(`unchecked\s+postgresql\s+%s/aws`, env))
+		Ω(out.String()).Should(MatchRegexp(`ok\s+rethinkdb\s+%s/aws`, env))
+
+		healthErr = errPing
+		Ω(Run([]string{"health", "--timeout=1s"}, out)).Should(MatchError("1 of 2 connections failed"))
+		Ω(out.String()).Should(MatchRegexp(`failed\s+rethinkdb\s+%s/aws\s+\S+\s+connection refused`, env))
+
+		Ω(Run([]string{"health", "--timeout=soon"}, out)).Should(HaveOccurred())
+	})
+
+	It("should check the config", func() {
+		Ω(Run([]string{"config:check"}, out)).Should(Succeed())
+		Ω(out.String()).Should(Equal("ok " + goar.Configuration().Environment + "\n"))
+	})
+})
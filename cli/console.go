@@ -0,0 +1,260 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	goar "github.com/obieq/goar"
+	"github.com/obieq/goar/schema"
+)
+
+const consoleHelp = `commands:
+  models                                   lists the models registered via schema.Register
+  find <model> <id>                        prints the record
+  all <model> [limit]                      prints the model's records, 100 by default
+  where <model> <key><op><value> ...       prints the records matching every condition, EX: where vehicles make=tesla year>=2012
+                                           ops: = != < <= > >=
+  help
+  exit`
+
+// consoleInput is where the console reads its commands
+var consoleInput io.Reader = os.Stdin
+
+// registeredModels are the models the console can read
+var registeredModels = schema.Registered
+
+// consoleOperators are the where conditions' operators, longest first so <= isn't read as <
+var consoleOperators = []struct {
+	token    string
+	operator goar.EnumRelationalOperators
+}{
+	{"!=", goar.NE}, {"<=", goar.LTE}, {">=", goar.GTE}, {"=", goar.EQ}, {"<", goar.LT}, {">", goar.GT},
+}
+
+// console reads the registered models of the --env/--conn connection, one command per line.
+// NOTE: go can't evaluate go at runtime, so the console only finds and queries records, it doesn't save them.
+func console(opts options, out io.Writer) error {
+	env := opts.get("env", goar.Configuration().Environment)
+	if env != goar.Configuration().Environment {
+		goar.Configuration().LoadEnvironment(env)
+	}
+
+	models := map[string]reflect.Type{}
+	for _, m := range registeredModels() {
+		models[goar.ToAR(m).ModelName()] = reflect.TypeOf(m).Elem()
+	}
+	if len(models) == 0 {
+		return fmt.Errorf("no models are registered, see schema.Register")
+	}
+
+	c := &consoleSession{models: models, connName: opts["conn"], env: env, out: out}
+	fmt.Fprintf(out, "goar console (%s), type help for the commands\n", env)
+
+	scanner := bufio.NewScanner(consoleInput)
+	for {
+		fmt.Fprintf(out, "%s> ", env)
+		if !scanner.Scan() {
+			break
+		}
+
+		args := strings.Fields(scanner.Text())
+		if len(args) == 0 {
+			continue
+		} else if args[0] == "exit" || args[0] == "quit" {
+			return nil
+		}
+
+		if err := c.run(args[0], args[1:]); err != nil {
+			fmt.Fprintln(out, "error:", err)
+		}
+	}
+	fmt.Fprintln(out)
+
+	return scanner.Err()
+}
+
+type consoleSession struct {
+	models   map[string]reflect.Type
+	connName string
+	env      string
+	out      io.Writer
+}
+
+func (c *consoleSession) run(command string, args []string) error {
+	switch command {
+	case "help":
+		fmt.Fprintln(c.out, consoleHelp)
+		return nil
+	case "models":
+		names := []string{}
+		for name := range c.models {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintln(c.out, strings.Join(names, "\n"))
+		return nil
+	case "find", "all", "where":
+		if len(args) == 0 {
+			return fmt.Errorf("%s needs a model, see models", command)
+		}
+	default:
+		return fmt.Errorf("unknown command %q, see help", command)
+	}
+
+	m, err := c.model(args[0])
+	if err != nil {
+		return err
+	}
+	t, args := c.models[args[0]], args[1:]
+
+	switch command {
+	case "find":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: find <model> <id>")
+		}
+		record := reflect.New(t).Interface()
+		if err = m.Find(args[0], record); err != nil {
+			return err
+		}
+		return c.print(record)
+	case "all":
+		limit := 100
+		if len(args) > 0 {
+			if limit, err = strconv.Atoi(args[0]); err != nil {
+				return fmt.Errorf("the limit must be a number, not %q", args[0])
+			}
+		}
+		records := reflect.New(reflect.SliceOf(reflect.PtrTo(t)))
+		if err = m.All(records.Interface(), map[string]interface{}{"limit": limit}); err != nil {
+			return err
+		}
+		return c.print(records.Interface())
+	}
+
+	// where
+	if len(args) == 0 {
+		return fmt.Errorf("where needs a condition, EX: make=tesla")
+	}
+	for i, arg := range args {
+		condition, err := consoleCondition(t, arg)
+		if err != nil {
+			return err
+		} else if i > 0 {
+			condition.LogicalOperator = goar.AND
+		}
+		m.Where(condition)
+	}
+	records := reflect.New(reflect.SliceOf(reflect.PtrTo(t)))
+	if err = m.Run(records.Interface()); err != nil {
+		return err
+	}
+	return c.print(records.Interface())
+}
+
+// model returns a blank instance of the named model, connected to the console's connection
+func (c *consoleSession) model(name string) (goar.ActiveRecordInterfacer, error) {
+	t, ok := c.models[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown model %q, see models", name)
+	}
+
+	m := goar.ToAR(reflect.New(t).Interface().(goar.ActiveRecordInterfacer))
+	if u, ok := m.(interface {
+		UseConnection(connName string, env string) *goar.ActiveRecord
+	}); ok {
+		u.UseConnection(c.connName, c.env)
+	}
+
+	return m, nil
+}
+
+func (c *consoleSession) print(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(c.out, string(data))
+	return err
+}
+
+// consoleCondition parses a condition, EX: year>=2012, converting the value to the type
+// of the model's field whose json name or field name matches the key
+func consoleCondition(t reflect.Type, arg string) (goar.QueryCondition, error) {
+	for _, op := range consoleOperators {
+		i := strings.Index(arg, op.token)
+		if i <= 0 {
+			continue
+		}
+
+		key, text := arg[:i], arg[i+len(op.token):]
+		value, err := consoleValue(t, key, text)
+		if err != nil {
+			return goar.QueryCondition{}, err
+		}
+
+		return goar.QueryCondition{Key: key, RelationalOperator: op.operator, Value: value}, nil
+	}
+
+	return goar.QueryCondition{}, fmt.Errorf("conditions look like key=value, not %q", arg)
+}
+
+func consoleValue(t reflect.Type, key string, text string) (interface{}, error) {
+	f, ok := consoleField(t, key)
+	if !ok {
+		return text, nil
+	}
+
+	var value interface{}
+	var err error
+	switch f.Type.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		n, err = strconv.ParseInt(text, 10, 64)
+		value = reflect.ValueOf(n).Convert(f.Type).Interface()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		n, err = strconv.ParseUint(text, 10, 64)
+		value = reflect.ValueOf(n).Convert(f.Type).Interface()
+	case reflect.Float32, reflect.Float64:
+		var n float64
+		n, err = strconv.ParseFloat(text, 64)
+		value = reflect.ValueOf(n).Convert(f.Type).Interface()
+	case reflect.Bool:
+		value, err = strconv.ParseBool(text)
+	default:
+		return text, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s is a %s, not %q", key, f.Type, text)
+	}
+
+	return value, nil
+}
+
+// consoleField finds the field by its json name or its field name, including embedded structs' fields
+func consoleField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if embedded, ok := consoleField(f.Type, key); ok {
+				return embedded, true
+			}
+			continue
+		}
+
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == key || strings.EqualFold(f.Name, key) {
+			return f, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	goar "github.com/obieq/goar"
+	"github.com/obieq/goar/fixtures"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// ConsoleVehicle is an in memory model, which records the environment it's read from or saved to
+type ConsoleVehicle struct {
+	goar.ActiveRecord
+	ID   string `json:"id,omitempty"`
+	Make string `json:"make"`
+	Year int    `json:"year"`
+}
+
+var (
+	consoleVehicles []ConsoleVehicle
+	consoleEnvs     []string
+)
+
+func (m *ConsoleVehicle) CustomModelName() string         { return "vehicles" }
+func (m *ConsoleVehicle) DBConnectionName() string        { return "default" }
+func (m *ConsoleVehicle) DBConnectionEnvironment() string { return "test" }
+func (m *ConsoleVehicle) SetKey(key string)               { m.ID = key }
+func (m *ConsoleVehicle) Validate()                       {}
+func (m *ConsoleVehicle) DbDelete() error                 { return nil }
+func (m *ConsoleVehicle) Truncate() (int, error)          { return 0, nil }
+
+func (m *ConsoleVehicle) route() {
+	_, env := goar.ResolveConnection(m)
+	consoleEnvs = append(consoleEnvs, env)
+}
+
+func (m *ConsoleVehicle) DbSave() error {
+	m.route()
+	consoleVehicles = append(consoleVehicles, *m)
+	return nil
+}
+
+func (m *ConsoleVehicle) Find(id interface{}, out interface{}) error {
+	m.route()
+	for _, v := range consoleVehicles {
+		if v.ID == id {
+			out.(*ConsoleVehicle).ID, out.(*ConsoleVehicle).Make, out.(*ConsoleVehicle).Year = v.ID, v.Make, v.Year
+			return nil
+		}
+	}
+	return errors.New("record not found")
+}
+
+func (m *ConsoleVehicle) All(results interface{}, opts map[string]interface{}) error {
+	m.route()
+	for i := 0; i < len(consoleVehicles) && i < opts["limit"].(int); i++ {
+		v := consoleVehicles[i]
+		*results.(*[]*ConsoleVehicle) = append(*results.(*[]*ConsoleVehicle), &v)
+	}
+	return nil
+}
+
+// DbSearch supports the conditions the console's specs use
+func (m *ConsoleVehicle) DbSearch(results interface{}) error {
+	m.route()
+	for i := range consoleVehicles {
+		v := consoleVehicles[i]
+		matches := true
+		for _, where := range m.Query().WhereConditions {
+			switch {
+			case where.Key == "make" && where.RelationalOperator == goar.EQ:
+				matches = matches && v.Make == where.Value
+			case where.Key == "year" && where.RelationalOperator == goar.GTE:
+				matches = matches && v.Year >= where.Value.(int)
+			}
+		}
+		if matches {
+			*results.(*[]*ConsoleVehicle) = append(*results.(*[]*ConsoleVehicle), &v)
+		}
+	}
+	m.SetQuery(goar.NewQuery())
+	return nil
+}
+
+var _ = Describe("Console", func() {
+	var out *bytes.Buffer
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+		consoleVehicles = []ConsoleVehicle{{ID: "id1", Make: "tesla", Year: 2012}, {ID: "id2", Make: "tesla", Year: 2009}, {ID: "id3", Make: "porsche", Year: 2010}}
+		consoleEnvs = nil
+		registeredModels = func() []goar.ActiveRecordInterfacer { return []goar.ActiveRecordInterfacer{&ConsoleVehicle{}} }
+	})
+
+	AfterEach(func() {
+		registeredModels = nil
+		consoleInput = nil
+	})
+
+	console := func(input string, args ...string) error {
+		consoleInput = strings.NewReader(input)
+		return Run(append([]string{"console"}, args...), out)
+	}
+
+	It("should find and query the registered models", func() {
+		Ω(console("models\nfind vehicles id3\nall vehicles 1\nwhere vehicles make=tesla year>=2010\nexit\n")).Should(Succeed())
+		Ω(out.String()).Should(ContainSubstring("> vehicles\n"))
+		Ω(out.String()).Should(ContainSubstring(`"make": "porsche"`))
+		Ω(strings.Count(out.String(), `"id": "id1"`)).Should(Equal(2))
+		Ω(out.String()).ShouldNot(ContainSubstring(`"id": "id2"`))
+	})
+
+	It("should read the --env connection", func() {
+		Ω(console("all vehicles\n", "--env=qa")).Should(Succeed())
+		Ω(consoleEnvs).Should(Equal([]string{"qa"}))
+	})
+
+	It("should print errors and carry on", func() {
+		Ω(console("find trucks id1\nwhere vehicles year>=soon\nfind vehicles id9\nfly\n")).Should(Succeed())
+		Ω(out.String()).Should(ContainSubstring(`error: unknown model "trucks", see models`))
+		Ω(out.String()).Should(ContainSubstring(`error: year is a int, not "soon"`))
+		Ω(out.String()).Should(ContainSubstring("error: record not found"))
+		Ω(out.String()).Should(ContainSubstring(`error: unknown command "fly", see help`))
+	})
+
+	It("should require registered models", func() {
+		registeredModels = func() []goar.ActiveRecordInterfacer { return nil }
+		Ω(console("")).Should(MatchError("no models are registered, see schema.Register"))
+	})
+})
+
+var _ = Describe("Seed", func() {
+	var out *bytes.Buffer
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+		consoleVehicles, consoleEnvs = nil, nil
+		fixtures.Register(&ConsoleVehicle{})
+	})
+
+	It("should seed the --env environment", func() {
+		Ω(Run([]string{"db:seed", "--env=qa", "testdata/seeds"}, out)).Should(Succeed())
+		Ω(out.String()).Should(Equal("seeded 1 vehicles\n"))
+		Ω(consoleVehicles).Should(HaveLen(1))
+		Ω(consoleEnvs).Should(ConsistOf("qa"))
+	})
+
+	It("should refuse to seed a protected --env, whatever its case", func() {
+		Ω(Run([]string{"db:seed", "--env=Production", "testdata/seeds"}, out)).Should(MatchError("refusing to load vehicles into the Production environment"))
+		Ω(consoleVehicles).Should(BeEmpty())
+	})
+})
@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+
+	goar "github.com/obieq/goar"
+)
+
+// fieldTypes maps the generate types onto go types
+var fieldTypes = map[string]string{
+	"string":    "string",
+	"text":      "string",
+	"int":       "int",
+	"integer":   "int",
+	"int64":     "int64",
+	"bigint":    "int64",
+	"float":     "float64",
+	"float64":   "float64",
+	"decimal":   "float64",
+	"bool":      "bool",
+	"boolean":   "bool",
+	"time":      "time.Time",
+	"datetime":  "time.Time",
+	"timestamp": "time.Time",
+}
+
+type field struct {
+	Name string // EX: SafetyRating
+	Type string
+	Tags string
+}
+
+type model struct {
+	Package  string
+	Name     string // EX: Vehicle
+	Var      string // EX: vehicle
+	Adapter  Adapter
+	ConnName string
+	Fields   []field
+	Time     bool // imports time
+}
+
+var modelTemplate = template.Must(template.New("model").Parse(`package {{.Package}}
+
+import (
+{{if .Time}}	"time"
+{{end}}
+	goar "github.com/obieq/goar"
+	"{{.Adapter.Import}}"
+)
+
+type {{.Name}} struct {
+	{{.Adapter.Model}}
+{{range .Fields}}	{{.Name}} {{.Type}} {{.Tags}}
+{{end}}}
+
+func (m {{.Name}}) ToActiveRecord() *{{.Name}} {
+	return goar.ToAR(&m).(*{{.Name}})
+}
+
+func (m *{{.Name}}) DBConnectionName() string {
+	return "{{.ConnName}}"
+}
+
+func (m *{{.Name}}) DBConnectionEnvironment() string {
+	return goar.Configuration().Environment
+}
+
+func (m *{{.Name}}) Validate() {
+}
+`))
+
+var specTemplate = template.Must(template.New("spec").Parse(`package {{.Package}}
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("{{.Name}}", func() {
+
+	It("should save and delete a {{.Var}}", func() {
+		{{.Var}} := {{.Name}}{}.ToActiveRecord()
+
+		success, err := {{.Var}}.Save()
+		Ω(err).NotTo(HaveOccurred())
+		Ω(success).Should(BeTrue())
+
+		Ω({{.Var}}.Delete()).Should(Succeed())
+	})
+})
+`))
+
+// generate writes a model and its spec, EX: generate model Vehicle year:int make:string --adapter=rethinkdb
+func generate(args []string, opts options, out io.Writer) error {
+	if len(args) < 2 || args[0] != "model" {
+		return fmt.Errorf(usage)
+	}
+
+	name := args[1]
+	if !unicode.IsUpper([]rune(name)[0]) || strings.ContainsAny(name, "_- ") {
+		return fmt.Errorf("model names are exported CamelCase go types, not %q", name)
+	}
+
+	adapterName := opts["adapter"]
+	if adapterName == "" {
+		return fmt.Errorf("pick the model's adapter via --adapter=<%s>", strings.Join(adapterNames(), "|"))
+	}
+	adapterName, a, err := adapter(adapterName, "", "")
+	if err != nil {
+		return err
+	}
+
+	dir := opts.get("dir", ".")
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	m := &model{
+		Package:  opts.get("package", filepath.Base(absDir)),
+		Name:     name,
+		Var:      strings.ToLower(name[:1]) + name[1:],
+		Adapter:  a,
+		ConnName: opts.get("conn", "default"),
+		Fields:   []field{},
+	}
+	for _, arg := range args[2:] {
+		f, err := parseField(arg, adapterName)
+		if err != nil {
+			return err
+		}
+		m.Time = m.Time || f.Type == "time.Time"
+		m.Fields = append(m.Fields, f)
+	}
+
+	file := filepath.Join(dir, snakeCase(name)+".go")
+	if err = write(file, modelTemplate, m, out); err != nil {
+		return err
+	}
+
+	return write(filepath.Join(dir, snakeCase(name)+"_test.go"), specTemplate, m, out)
+}
+
+// parseField parses name:type, EX: safety_rating:int => SafetyRating int `json:"safety_rating,omitempty"`
+func parseField(arg string, adapterName string) (field, error) {
+	parts := strings.Split(arg, ":")
+	if len(parts) != 2 || parts[0] == "" {
+		return field{}, fmt.Errorf("fields are name:type, not %q", arg)
+	}
+
+	column := strings.ToLower(parts[0])
+	t, ok := fieldTypes[strings.ToLower(parts[1])]
+	if !ok {
+		return field{}, fmt.Errorf("unknown type %q for %s", parts[1], column)
+	}
+
+	tags := fmt.Sprintf(`json:"%s,omitempty"`, column)
+	switch adapterName {
+	case goar.RETHINKDB:
+		tags += fmt.Sprintf(` gorethink:"%s,omitempty"`, column)
+	case goar.POSTGRESQL:
+		tags += fmt.Sprintf(` gorm:"column:%s"`, column)
+	case goar.MSSQL:
+		tags += fmt.Sprintf(` xorm:"'%s'"`, column)
+	}
+
+	return field{Name: camelCase(column), Type: t, Tags: "`" + tags + "`"}, nil
+}
+
+// write renders the template into a new file, so it never overwrites an existing model
+func write(file string, t *template.Template, m *model, out io.Writer) error {
+	src := &bytes.Buffer{}
+	if err := t.Execute(src, m); err != nil {
+		return err
+	}
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err = f.Write(formatted); err == nil {
+		fmt.Fprintf(out, "created %s\n", file)
+	}
+
+	return err
+}
+
+// camelCase names a field, EX: safety_rating => SafetyRating, vin => Vin
+func camelCase(s string) string {
+	out := ""
+	for _, part := range strings.Split(s, "_") {
+		if part != "" {
+			out += strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+
+	return out
+}
+
+// snakeCase names a file, EX: SafetyRating => safety_rating
+func snakeCase(s string) string {
+	out := []rune{}
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				out = append(out, '_')
+			}
+			r = unicode.ToLower(r)
+		}
+		out = append(out, r)
+	}
+
+	return string(out)
+}
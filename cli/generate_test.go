@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var (
+		dir string
+		out *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		registerMemoryAdapter()
+		out = &bytes.Buffer{}
+
+		var err error
+		dir, err = ioutil.TempDir("", "models")
+		Ω(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	read := func(name string) string {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		Ω(err).NotTo(HaveOccurred())
+		return string(b)
+	}
+
+	It("should generate a model and its spec", func() {
+		args := []string{"generate", "model", "SafeVehicle", "year:int", "make:string", "sold_at:datetime",
+			"--adapter=rethinkdb", "--dir=" + dir, "--package=models", "--conn=aws"}
+		Ω(Run(args, out)).Should(Succeed())
+		Ω(out.String()).Should(ContainSubstring("created " + filepath.Join(dir, "safe_vehicle.go")))
+
+		src := read("safe_vehicle.go")
+		Ω(src).Should(HavePrefix("package models\n\nimport (\n\t\"time\"\n\n\tgoar \"github.com/obieq/goar\"\n\t\"github.com/obieq/goar/db/rethinkdb\"\n)"))
+		Ω(src).Should(ContainSubstring("type SafeVehicle struct {\n\trethinkdb.ArRethinkDb\n"))
+		Ω(src).Should(ContainSubstring("Year   int       `json:\"year,omitempty\" gorethink:\"year,omitempty\"`"))
+		Ω(src).Should(ContainSubstring("SoldAt time.Time `json:\"sold_at,omitempty\" gorethink:\"sold_at,omitempty\"`"))
+		Ω(src).Should(ContainSubstring("func (m SafeVehicle) ToActiveRecord() *SafeVehicle {"))
+		Ω(src).Should(ContainSubstring("return \"aws\""))
+
+		spec := read("safe_vehicle_test.go")
+		Ω(spec).Should(ContainSubstring(`var _ = Describe("SafeVehicle", func() {`))
+		Ω(spec).Should(ContainSubstring("safeVehicle := SafeVehicle{}.ToActiveRecord()"))
+	})
+
+	It("should not overwrite a model", func() {
+		args := []string{"generate", "model", "Vehicle", "--adapter=rethinkdb", "--dir=" + dir}
+		Ω(Run(args, out)).Should(Succeed())
+		Ω(read("vehicle.go")).Should(HavePrefix("package " + filepath.Base(dir) + "\n\nimport (\n\tgoar"))
+		Ω(Run(args, out)).Should(HaveOccurred())
+	})
+
+	It("should require an adapter, a model name and typed fields", func() {
+		Ω(Run([]string{"generate", "model", "Vehicle", "--dir=" + dir}, out)).Should(HaveOccurred())
+		Ω(Run([]string{"generate", "model", "--adapter=rethinkdb"}, out)).Should(HaveOccurred())
+		Ω(Run([]string{"generate", "model", "vehicle", "--adapter=rethinkdb"}, out)).Should(HaveOccurred())
+		Ω(Run([]string{"generate", "model", "Vehicle", "year", "--adapter=rethinkdb"}, out)).Should(HaveOccurred())
+		Ω(Run([]string{"generate", "model", "Vehicle", "year:decimal128", "--adapter=rethinkdb"}, out)).Should(HaveOccurred())
+	})
+
+	It("should tag columns for the adapter", func() {
+		Ω(parseField("safety_rating:int", "postgresql")).Should(Equal(field{Name: "SafetyRating", Type: "int", Tags: "`json:\"safety_rating,omitempty\" gorm:\"column:safety_rating\"`"}))
+		Ω(parseField("vin:string", "mssql")).Should(Equal(field{Name: "Vin", Type: "string", Tags: "`json:\"vin,omitempty\" xorm:\"'vin'\"`"}))
+		Ω(parseField("vin:string", "dynamodb")).Should(Equal(field{Name: "Vin", Type: "string", Tags: "`json:\"vin,omitempty\"`"}))
+	})
+})
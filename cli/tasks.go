@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"text/tabwriter"
+	"time"
+
+	goar "github.com/obieq/goar"
+	"github.com/obieq/goar/fixtures"
+	"github.com/obieq/goar/migration"
+	"github.com/obieq/goar/schema"
+)
+
+// Pinger is implemented by the adapters' migration drivers
+type Pinger interface {
+	Ping() error
+}
+
+// runTask runs a command against a connection.  db:drop and db:truncate refuse
+// fixtures.ProtectedEnvironments unless --force is given.
+func runTask(command string, args []string, opts options, out io.Writer) error {
+	env := opts.get("env", goar.Configuration().Environment)
+	connName := opts.get("conn", "default")
+	if env != goar.Configuration().Environment {
+		goar.Configuration().LoadEnvironment(env)
+	}
+
+	if (command == "db:drop" || command == "db:truncate") && fixtures.Protected(env) && opts["force"] != "true" {
+		return fmt.Errorf("refusing to %s the %s environment without --force", command, env)
+	}
+
+	switch command {
+	case "db:truncate":
+		return truncate(env, out)
+	case "db:seed":
+		flags := []string{"--env=" + env} // so the seeded environment is the one that's checked
+		if opts["conn"] != "" {
+			flags = append(flags, "--conn="+opts["conn"])
+		}
+		if opts["truncate"] == "true" {
+			flags = append(flags, "--truncate")
+		}
+		return fixtures.Command(append(append([]string{command}, flags...), args...), out)
+	}
+
+	name, a, err := adapter(opts["adapter"], connName, env)
+	if err != nil {
+		return err
+	}
+
+	driver, maintenance := a.Driver, false
+	if (command == "db:create" || command == "db:drop") && a.MaintenanceDriver != nil {
+		driver, maintenance = a.MaintenanceDriver, true
+	}
+
+	d, err := connect(driver, connName, env)
+	if err != nil {
+		return err
+	}
+	if c, ok := d.(io.Closer); ok && maintenance { // Driver's connections are shared
+		defer c.Close()
+	}
+
+	switch command {
+	case "db:create":
+		if err = d.CreateDb(dbName(name, connName, env)); err == nil {
+			fmt.Fprintf(out, "created %s (%s %s/%s)\n", dbName(name, connName, env), name, env, connName)
+		}
+		return err
+	case "db:drop":
+		if err = d.DropDb(dbName(name, connName, env)); err == nil {
+			fmt.Fprintf(out, "dropped %s (%s %s/%s)\n", dbName(name, connName, env), name, env, connName)
+		}
+		return err
+	case "migrate":
+		if len(args) == 0 {
+			args = []string{"up"}
+		}
+		return migration.Command(d, args, out)
+	case "schema:diff":
+		in, ok := d.(schema.Inspector)
+		if !ok {
+			return fmt.Errorf("the %s adapter can't inspect its schema", name)
+		}
+		return schema.Command(in, append([]string{"diff"}, args...), out)
+	}
+
+	// ping
+	if p, ok := d.(Pinger); ok {
+		err = p.Ping()
+	}
+	if err == nil {
+		fmt.Fprintf(out, "ok %s %s/%s\n", name, env, connName)
+	}
+
+	return err
+}
+
+// connect returns one of the adapter's drivers.  The adapters panic when they can't connect,
+// so the panic is returned as an error.
+func connect(driver func(connName string, env string) migration.Driver, connName string, env string) (d migration.Driver, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("could not connect to %s/%s: %v", env, connName, r)
+		}
+	}()
+
+	return driver(connName, env), nil
+}
+
+// truncate truncates the registered models in the environment
+func truncate(env string, out io.Writer) error {
+	models := schema.Registered()
+	if len(models) == 0 {
+		return fmt.Errorf("no models are registered, see schema.Register")
+	}
+
+	for _, prototype := range models {
+		m := goar.ToAR(reflect.New(reflect.TypeOf(prototype).Elem()).Interface().(goar.ActiveRecordInterfacer))
+		if c, ok := m.(interface {
+			UseConnection(connName string, env string) *goar.ActiveRecord
+		}); ok {
+			c.UseConnection("", env)
+		}
+
+		if _, err := m.Truncate(); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "truncated %s\n", m.ModelName())
+	}
+
+	return nil
+}
+
+// health prints the environment's goar.HealthReport, returning an error when it isn't healthy.
+// NOTE: only the adapters compiled in register their health checks.
+func health(opts options, out io.Writer) (err error) {
+	timeout, err := time.ParseDuration(opts.get("timeout", "10s"))
+	if err != nil {
+		return fmt.Errorf("--timeout must be a duration, EX: 5s")
+	}
+	if err = checkConfig(ioutil.Discard); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	report := goar.HealthCheck(ctx)
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	failed := 0
+	for _, h := range report.Connections {
+		fmt.Fprintf(w, "%s\t%s\t%s/%s\t%s\t%s\n", h.Status, h.Adapter, report.Environment, h.Connection, h.Duration.Round(time.Millisecond), h.Error)
+		if h.Status == goar.HealthFailed {
+			failed++
+		}
+	}
+	w.Flush()
+
+	if !report.Healthy {
+		return fmt.Errorf("%d of %d connections failed", failed, len(report.Connections))
+	}
+
+	return nil
+}
+
+// checkConfig loads the config, which panics when it's invalid, so the panic is returned as an error
+func checkConfig(out io.Writer) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	c := goar.Configuration()
+	if err = c.Validate(); err == nil {
+		fmt.Fprintf(out, "ok %s\n", c.Environment)
+	}
+
+	return err
+}
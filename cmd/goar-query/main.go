@@ -0,0 +1,53 @@
+// Command goar-query generates typed query fields and finders for a package's models, EX:
+//
+//	//go:generate goar-query -type=Vehicle,Dealer
+//
+// writes vehicle_query.go next to the models, so queries read
+//
+//	m.Where(VehicleQ.Year.Gte(2010)).Where(VehicleQ.Make.In("Honda", "Acura")).Run(&results)
+//
+// W/o -type, it generates every model in the package that isn't embedded by another.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/obieq/goar/querygen"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma separated models, EX: Vehicle,Dealer")
+	output := flag.String("output", "", "the generated file, EX: vehicle_query.go")
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	names := []string{}
+	for _, name := range strings.Split(*typeNames, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	src, err := querygen.Generate(dir, names)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "goar-query:", err)
+		os.Exit(1)
+	}
+
+	file := *output
+	if file == "" {
+		file = querygen.Output(dir, names)
+	}
+	if err = ioutil.WriteFile(file, src, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "goar-query:", err)
+		os.Exit(1)
+	}
+}
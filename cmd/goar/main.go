@@ -0,0 +1,67 @@
+// Command goar generates models and runs db tasks against the connections in config.json, EX:
+//
+//	goar generate model Vehicle year:int make:string --adapter=rethinkdb
+//	goar db:create --adapter=postgresql --env=dev
+//	goar migrate status --env=qa --conn=aws
+//	goar ping
+//	goar health --timeout=5s
+//	goar console --env=qa
+//
+// NOTE: migrate, schema:diff, db:truncate, db:seed and console only see the migrations and models
+// compiled in, so apps build their own goar via the cli package.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	goar "github.com/obieq/goar"
+	"github.com/obieq/goar/cli"
+	"github.com/obieq/goar/db/couchbase"
+	"github.com/obieq/goar/db/dynamodb"
+	"github.com/obieq/goar/db/mssql"
+	"github.com/obieq/goar/db/postgres"
+	"github.com/obieq/goar/db/rethinkdb"
+	"github.com/obieq/goar/migration"
+)
+
+func init() {
+	cli.Register(goar.RETHINKDB, cli.Adapter{
+		Import: "github.com/obieq/goar/db/rethinkdb",
+		Model:  "rethinkdb.ArRethinkDb",
+		Driver: func(connName string, env string) migration.Driver { return rethinkdb.NewMigrationDriver(connName, env) },
+	})
+	cli.Register(goar.POSTGRESQL, cli.Adapter{
+		Import: "github.com/obieq/goar/db/postgres",
+		Model:  "postgres.ArPostgres",
+		Driver: func(connName string, env string) migration.Driver { return postgres.NewMigrationDriver(connName, env) },
+		MaintenanceDriver: func(connName string, env string) migration.Driver {
+			return postgres.NewMaintenanceDriver(connName, env)
+		},
+	})
+	cli.Register(goar.MSSQL, cli.Adapter{
+		Import: "github.com/obieq/goar/db/mssql",
+		Model:  "mssql.ArMsSql",
+		Driver: func(connName string, env string) migration.Driver { return mssql.NewMigrationDriver(connName, env) },
+		MaintenanceDriver: func(connName string, env string) migration.Driver {
+			return mssql.NewMaintenanceDriver(connName, env)
+		},
+	})
+	cli.Register(goar.DYNAMODB, cli.Adapter{
+		Import: "github.com/obieq/goar/db/dynamodb",
+		Model:  "dynamodb.ArDynamodb",
+		Driver: func(connName string, env string) migration.Driver { return dynamodb.NewMigrationDriver(connName, env) },
+	})
+	cli.Register(goar.COUCHBASE, cli.Adapter{
+		Import: "github.com/obieq/goar/db/couchbase",
+		Model:  "couchbase.ArCouchbase",
+		Driver: func(connName string, env string) migration.Driver { return couchbase.NewMigrationDriver(connName, env) },
+	})
+}
+
+func main() {
+	if err := cli.Run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
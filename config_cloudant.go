@@ -0,0 +1,36 @@
+package goar
+
+import (
+	"github.com/obieq/gas"
+	"github.com/spf13/viper"
+)
+
+// CloudantConfig => contains cloudant db connection info
+type CloudantConfig struct {
+	ConnectionName string
+	URL            string
+	APIKey         string
+	APIPassword    string
+}
+
+func (c *config) loadCloudant() {
+	for connKey := range viper.GetStringMap(c.Environment + "." + CLOUDANT) {
+		connName := c.Environment + "_" + CLOUDANT + "_" + connKey
+		path := c.Environment + "." + CLOUDANT + "." + connKey + "."
+		cloudant := &CloudantConfig{}
+		cloudant.ConnectionName = connName
+		cloudant.URL = gas.GetString(path + "url")
+		cloudant.APIKey = gas.GetString(path + "apikey")
+		cloudant.APIPassword = gas.GetString(path + "apipassword")
+
+		c.CloudantDBs[connName] = cloudant
+	}
+}
+
+// problems lists the connection's missing settings
+func (m *CloudantConfig) problems() []string {
+	c := connectionChecks{}
+	c.required("url", m.URL)
+
+	return c
+}
@@ -1,16 +1,22 @@
 package goar
 
 import (
+	"time"
+
 	"github.com/obieq/gas"
 	"github.com/spf13/viper"
 )
 
 // CouchbaseConfig => contains couchbase db connection info
 type CouchbaseConfig struct {
-	ConnectionName string
-	ClusterAddress string
-	BucketName     string
-	BucketPassword string
+	ConnectionName  string
+	ClusterAddress  string
+	BucketName      string
+	BucketPassword  string
+	TLS             TLSConfig
+	DialTimeout     time.Duration
+	ReadTimeout     time.Duration
+	ApplicationName string
 }
 
 func (c *config) loadCouchbase() {
@@ -22,7 +28,21 @@ func (c *config) loadCouchbase() {
 		couchbase.ClusterAddress = gas.GetString(path + "cluster_address")
 		couchbase.BucketName = gas.GetString(path + "bucket_name")
 		couchbase.BucketPassword = gas.GetString(path + "bucket_password")
+		couchbase.TLS = loadTLS(path)
+		couchbase.DialTimeout = getDuration(path + "dialtimeout")
+		couchbase.ReadTimeout = getDuration(path + "readtimeout")
+		couchbase.ApplicationName = gas.GetString(path + "applicationname")
 
 		c.CouchbaseDBs[connName] = couchbase
 	}
 }
+
+// problems lists the connection's missing or impossible settings
+func (m *CouchbaseConfig) problems() []string {
+	c := connectionChecks{}
+	c.required("cluster_address", m.ClusterAddress)
+	c.required("bucket_name", m.BucketName)
+	c.options(m.TLS, m.DialTimeout, m.ReadTimeout)
+
+	return c
+}
@@ -1,18 +1,37 @@
 package goar
 
 import (
+	"net/url"
+	"regexp"
+
 	"github.com/obieq/gas"
 	"github.com/spf13/viper"
 )
 
-// DynamoDBConfig => contains dynamodb db connection info
+// DynamoDBConfig => contains dynamodb db connection info.
+// Credentials are the access and secret keys, else the profile, else the
+// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY variables, the instance's role or the default profile.
 type DynamoDBConfig struct {
-	ConnectionName string
-	Region         string
-	AccessKey      string
-	SecretKey      string
+	ConnectionName  string
+	Region          string // EX: eu-west-1, or useast, uswest and uswest2
+	Endpoint        string // overrides the region's, EX: http://localhost:8000 for DynamoDB Local
+	AccessKey       string
+	SecretKey       string
+	SessionToken    string // of temporary keys
+	Profile         string // in the shared credentials file, defaults to AWS_PROFILE
+	CredentialsFile string // defaults to AWS_SHARED_CREDENTIALS_FILE, then ~/.aws/credentials
+	TablePrefix     string // of every table name, EX: test_ so environments can share a region
+}
+
+// dynamoDBRegions are the region names goar first supported
+var dynamoDBRegions = map[string]string{
+	"useast":  "us-east-1",
+	"uswest":  "us-west-1",
+	"uswest2": "us-west-2",
 }
 
+var awsRegion = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-[0-9]+$`)
+
 func (c *config) loadDynamoDB() {
 	for connKey := range viper.GetStringMap(c.Environment + "." + DYNAMODB) {
 		connName := c.Environment + "_" + DYNAMODB + "_" + connKey
@@ -20,9 +39,43 @@ func (c *config) loadDynamoDB() {
 		dynamodb := &DynamoDBConfig{}
 		dynamodb.ConnectionName = connName
 		dynamodb.Region = gas.GetString(path + "region")
+		dynamodb.Endpoint = gas.GetString(path + "endpoint")
 		dynamodb.AccessKey = gas.GetString(path + "accesskey")
 		dynamodb.SecretKey = gas.GetString(path + "secretkey")
+		dynamodb.SessionToken = gas.GetString(path + "sessiontoken")
+		dynamodb.Profile = gas.GetString(path + "profile")
+		dynamodb.CredentialsFile = gas.GetString(path + "credentialsfile")
+		dynamodb.TablePrefix = gas.GetString(path + "tableprefix")
 
 		c.DynamoDBs[connName] = dynamodb
 	}
 }
+
+// AWSRegion returns the region's name, EX: us-east-1 for useast
+func (m *DynamoDBConfig) AWSRegion() string {
+	if name, ok := dynamoDBRegions[m.Region]; ok {
+		return name
+	}
+
+	return m.Region
+}
+
+// problems lists the connection's missing settings
+func (m *DynamoDBConfig) problems() []string {
+	c := connectionChecks{}
+	c.required("region", m.Region)
+	if m.Region != "" && !awsRegion.MatchString(m.AWSRegion()) {
+		c.add("region must be an aws region, EX: us-west-2, not %q", m.Region)
+	}
+	if u, err := url.Parse(m.Endpoint); m.Endpoint != "" && (err != nil || u.Host == "" || u.Scheme != "http" && u.Scheme != "https") {
+		c.add("endpoint must be a url, EX: http://localhost:8000, not %q", m.Endpoint)
+	}
+	if (m.AccessKey == "") != (m.SecretKey == "") {
+		c.add("accesskey and secretkey go together")
+	}
+	if m.AccessKey != "" && (m.Profile != "" || m.CredentialsFile != "") {
+		c.add("accesskey and profile are alternatives, so set one or the other")
+	}
+
+	return c
+}
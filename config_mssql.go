@@ -1,6 +1,8 @@
 package goar
 
 import (
+	"time"
+
 	"github.com/obieq/gas"
 	"github.com/spf13/viper"
 )
@@ -18,6 +20,10 @@ type MSSQLConfig struct {
 	MaxIdleConnections int
 	MaxOpenConnections int
 	Debug              bool
+	TLS                TLSConfig
+	DialTimeout        time.Duration
+	ReadTimeout        time.Duration
+	ApplicationName    string
 }
 
 func (c *config) loadMSSQL() {
@@ -39,7 +45,27 @@ func (c *config) loadMSSQL() {
 		mssql.MaxIdleConnections = gas.GetInt(path + "maxidleconnections")
 		mssql.MaxOpenConnections = gas.GetInt(path + "maxopenconnections")
 		mssql.Debug = gas.GetBool(path + "debug")
+		mssql.TLS = loadTLS(path)
+		mssql.DialTimeout = getDuration(path + "dialtimeout")
+		mssql.ReadTimeout = getDuration(path + "readtimeout")
+		mssql.ApplicationName = gas.GetString(path + "applicationname")
 
 		c.MSSQLDBs[connName] = mssql
 	}
 }
+
+// problems lists the connection's missing or impossible settings
+func (m *MSSQLConfig) problems() []string {
+	c := connectionChecks{}
+	c.required("server", m.Server)
+	c.required("dbname", m.DBName)
+	c.port("port", m.Port)
+	c.port("failoverport", m.FailoverPort)
+	if m.FailoverPort != 0 && m.FailoverPartner == "" {
+		c.add("failoverport needs a failoverpartner")
+	}
+	c.pool(m.MaxIdleConnections, m.MaxOpenConnections)
+	c.options(m.TLS, m.DialTimeout, m.ReadTimeout)
+
+	return c
+}
@@ -22,3 +22,11 @@ func (c *config) loadOrchestrate() {
 		c.OrchestrateDBs[connName] = orchestrate
 	}
 }
+
+// problems lists the connection's missing settings
+func (m *OrchestrateConfig) problems() []string {
+	c := connectionChecks{}
+	c.required("apikey", m.APIKey)
+
+	return c
+}
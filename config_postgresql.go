@@ -1,6 +1,8 @@
 package goar
 
 import (
+	"time"
+
 	"github.com/obieq/gas"
 	"github.com/spf13/viper"
 )
@@ -16,6 +18,10 @@ type PostgresqlDBConfig struct {
 	MaxIdleConnections int
 	MaxOpenConnections int
 	Debug              bool
+	TLS                TLSConfig
+	DialTimeout        time.Duration
+	ReadTimeout        time.Duration
+	ApplicationName    string
 }
 
 func (c *config) loadPostgresql() {
@@ -32,7 +38,23 @@ func (c *config) loadPostgresql() {
 		postgresql.MaxIdleConnections = gas.GetInt(path + "maxidleconnections")
 		postgresql.MaxOpenConnections = gas.GetInt(path + "maxopenconnections")
 		postgresql.Debug = gas.GetBool(path + "debug")
+		postgresql.TLS = loadTLS(path)
+		postgresql.DialTimeout = getDuration(path + "dialtimeout")
+		postgresql.ReadTimeout = getDuration(path + "readtimeout")
+		postgresql.ApplicationName = gas.GetString(path + "applicationname")
 
 		c.PostgresqlDBs[connName] = postgresql
 	}
 }
+
+// problems lists the connection's missing or impossible settings
+func (m *PostgresqlDBConfig) problems() []string {
+	c := connectionChecks{}
+	c.required("server", m.Server)
+	c.required("dbname", m.DBName)
+	c.port("port", m.Port)
+	c.pool(m.MaxIdleConnections, m.MaxOpenConnections)
+	c.options(m.TLS, m.DialTimeout, m.ReadTimeout)
+
+	return c
+}
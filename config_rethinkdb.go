@@ -1,6 +1,8 @@
 package goar
 
 import (
+	"time"
+
 	"strings"
 
 	"github.com/obieq/gas"
@@ -17,6 +19,10 @@ type RethinkDBConfig struct {
 	MaxIdleConnections int
 	MaxOpenConnections int
 	Debug              bool
+	TLS                TLSConfig
+	DialTimeout        time.Duration
+	ReadTimeout        time.Duration
+	ApplicationName    string
 }
 
 // "addresses": "ec2-52-7-204-235.compute-1.amazonaws.com:28015",
@@ -39,11 +45,34 @@ func (c *config) loadRethinkDB() {
 		rethinkdb.MaxIdleConnections = gas.GetInt(path + "maxidleconnections")
 		rethinkdb.MaxOpenConnections = gas.GetInt(path + "maxopenconnections")
 		rethinkdb.Debug = gas.GetBool(path + "debug")
+		rethinkdb.TLS = loadTLS(path)
+		rethinkdb.DialTimeout = getDuration(path + "dialtimeout")
+		rethinkdb.ReadTimeout = getDuration(path + "readtimeout")
+		rethinkdb.ApplicationName = gas.GetString(path + "applicationname")
 
 		c.RethinkDBs[connName] = rethinkdb
 	}
 }
 
+// problems lists the connection's missing or impossible settings
+func (m *RethinkDBConfig) problems() []string {
+	c := connectionChecks{}
+	if len(m.Addresses) == 0 {
+		c.add("addresses is required")
+	}
+	for _, address := range m.Addresses {
+		if strings.TrimSpace(address) == "" {
+			c.add("addresses cannot be blank, EX: db1:28015,db2:28015")
+			break
+		}
+	}
+	c.required("dbname", m.DBName)
+	c.pool(m.MaxIdleConnections, m.MaxOpenConnections)
+	c.options(m.TLS, m.DialTimeout, m.ReadTimeout)
+
+	return c
+}
+
 func parseRethinkDbAddresses(configValue string) []string {
 	addresses := []string{}
 	for _, address := range strings.Split(configValue, ",") {
@@ -0,0 +1,242 @@
+package goar
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// ConfigError lists every problem found in the config, rather than just the first
+type ConfigError struct {
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	return "invalid goar config:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// the kinds of config file values
+const (
+	stringKey   = "a string"
+	intKey      = "an integer"
+	boolKey     = "true or false"
+	durationKey = "a duration, EX: 5s"
+)
+
+// connectionKeys are shared by the sql, rethinkdb and couchbase connections
+var connectionKeys = map[string]string{
+	"tlsmode":         stringKey,
+	"tlscafile":       stringKey,
+	"tlscertfile":     stringKey,
+	"tlskeyfile":      stringKey,
+	"tlsservername":   stringKey,
+	"dialtimeout":     durationKey,
+	"readtimeout":     durationKey,
+	"applicationname": stringKey,
+}
+
+// configKeys are the keys each adapter's connections can have in the config file, EX: test.mssql.default.server
+var configKeys = map[string]map[string]string{
+	MSSQL: withConnectionKeys(map[string]string{
+		"server": stringKey, "port": intKey, "failoverpartner": stringKey, "failoverport": intKey,
+		"dbname": stringKey, "username": stringKey, "password": stringKey,
+		"maxidleconnections": intKey, "maxopenconnections": intKey, "debug": boolKey,
+	}),
+	POSTGRESQL: withConnectionKeys(map[string]string{
+		"server": stringKey, "port": intKey, "dbname": stringKey, "username": stringKey, "password": stringKey,
+		"maxidleconnections": intKey, "maxopenconnections": intKey, "debug": boolKey,
+	}),
+	RETHINKDB: withConnectionKeys(map[string]string{
+		"addresses": stringKey, "dbname": stringKey, "authkey": stringKey, "discoverhosts": boolKey,
+		"maxidleconnections": intKey, "maxopenconnections": intKey, "debug": boolKey,
+	}),
+	COUCHBASE: withConnectionKeys(map[string]string{
+		"cluster_address": stringKey, "bucket_name": stringKey, "bucket_password": stringKey,
+	}),
+	DYNAMODB: {
+		"region": stringKey, "endpoint": stringKey, "accesskey": stringKey, "secretkey": stringKey, "sessiontoken": stringKey,
+		"profile": stringKey, "credentialsfile": stringKey, "tableprefix": stringKey,
+	},
+	ORCHESTRATE: {
+		"apikey": stringKey,
+	},
+	CLOUDANT: {
+		"url": stringKey, "apikey": stringKey, "apipassword": stringKey,
+	},
+}
+
+func withConnectionKeys(keys map[string]string) map[string]string {
+	for key, kind := range connectionKeys {
+		keys[key] = kind
+	}
+
+	return keys
+}
+
+// Validate reports the environment's unknown or mistyped config file keys and its connections'
+// missing or impossible settings.  Loading the config panics with the same error, and Configure returns it.
+func (c *config) Validate() error {
+	return c.validate(c.Environment)
+}
+
+func (c *config) validate(env string) error {
+	problems, malformed := []string{}, map[string]bool{}
+	if c.file != "" {
+		problems, malformed = fileProblems(env)
+	}
+
+	add := func(connName string, connProblems []string) {
+		if strings.HasPrefix(connName, env+"_") && !malformed[connName] {
+			for _, problem := range connProblems {
+				problems = append(problems, connName+": "+problem)
+			}
+		}
+	}
+	for connName, m := range c.MSSQLDBs {
+		add(connName, m.problems())
+	}
+	for connName, m := range c.PostgresqlDBs {
+		add(connName, m.problems())
+	}
+	for connName, m := range c.RethinkDBs {
+		add(connName, m.problems())
+	}
+	for connName, m := range c.CouchbaseDBs {
+		add(connName, m.problems())
+	}
+	for connName, m := range c.DynamoDBs {
+		add(connName, m.problems())
+	}
+	for connName, m := range c.OrchestrateDBs {
+		add(connName, m.problems())
+	}
+	for connName, m := range c.CloudantDBs {
+		add(connName, m.problems())
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+
+	return &ConfigError{Problems: problems}
+}
+
+// fileProblems checks the environment's config file keys, EX: a maxopenconnection typo would otherwise be 0.
+// It also returns the connections that aren't objects, which are skipped rather than checked.
+func fileProblems(env string) ([]string, map[string]bool) {
+	problems, malformed := []string{}, map[string]bool{}
+	for adapter, connections := range viper.GetStringMap(env) {
+		keys, ok := configKeys[adapter]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s.%s is not an adapter, expected one of: %s", env, adapter, strings.Join(adapterNames(), ", ")))
+			continue
+		}
+
+		connMap, _ := connections.(map[string]interface{})
+		for connKey, settings := range connMap {
+			path := env + "." + adapter + "." + connKey
+			settingMap, ok := settings.(map[string]interface{})
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s should be a connection, EX: %s.%s.default.%s", path, env, adapter, connKey))
+				malformed[env+"_"+adapter+"_"+connKey] = true
+				continue
+			}
+
+			for key, value := range settingMap {
+				kind, ok := keys[key]
+				if !ok {
+					problems = append(problems, fmt.Sprintf("%s.%s is not a %s setting", path, key, adapter))
+				} else if !isKind(value, kind) {
+					problems = append(problems, fmt.Sprintf("%s.%s must be %s, not %v", path, key, kind, value))
+				}
+			}
+		}
+	}
+
+	return problems, malformed
+}
+
+func adapterNames() []string {
+	names := []string{}
+	for name := range configKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// isKind checks a config file value, which is converted the way gas converts it
+func isKind(value interface{}, kind string) bool {
+	switch v := value.(type) {
+	case string:
+		switch kind {
+		case intKey:
+			_, err := strconv.Atoi(v)
+			return err == nil
+		case boolKey:
+			_, err := strconv.ParseBool(v)
+			return err == nil
+		case durationKey:
+			_, err := time.ParseDuration(v)
+			return err == nil || v == ""
+		}
+		return true
+	case bool:
+		return kind == boolKey
+	case float64:
+		return kind == intKey && v == float64(int(v))
+	case int, int64:
+		return kind == intKey
+	}
+
+	return false
+}
+
+// connectionChecks collects a connection's problems
+type connectionChecks []string
+
+func (c *connectionChecks) add(format string, args ...interface{}) {
+	*c = append(*c, fmt.Sprintf(format, args...))
+}
+
+func (c *connectionChecks) required(key string, value string) {
+	if strings.TrimSpace(value) == "" {
+		c.add("%s is required", key)
+	}
+}
+
+// port allows 0, which is the adapter's default port
+func (c *connectionChecks) port(key string, port int) {
+	if port < 0 || port > 65535 {
+		c.add("%s must be between 1 and 65535, not %d", key, port)
+	}
+}
+
+func (c *connectionChecks) pool(maxIdle int, maxOpen int) {
+	if maxIdle < 0 {
+		c.add("maxidleconnections cannot be negative")
+	}
+	if maxOpen < 0 {
+		c.add("maxopenconnections cannot be negative")
+	} else if maxOpen > 0 && maxIdle > maxOpen {
+		c.add("maxidleconnections (%d) cannot exceed maxopenconnections (%d)", maxIdle, maxOpen)
+	}
+}
+
+func (c *connectionChecks) options(t TLSConfig, dialTimeout time.Duration, readTimeout time.Duration) {
+	if err := t.Validate(); err != nil {
+		c.add("%v", err)
+	}
+	if dialTimeout < 0 {
+		c.add("dialtimeout cannot be negative")
+	}
+	if readTimeout < 0 {
+		c.add("readtimeout cannot be negative")
+	}
+}
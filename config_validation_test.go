@@ -0,0 +1,112 @@
+package goar
+
+import (
+	"io/ioutil"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+)
+
+var _ = Describe("Config Validation", func() {
+	var saved *config
+
+	BeforeEach(func() {
+		saved = Config
+	})
+
+	AfterEach(func() {
+		Config = saved
+	})
+
+	// load reads the json the way loadConfig does
+	load := func(json string) *config {
+		f, err := ioutil.TempFile("", "goar-config")
+		Ω(err).NotTo(HaveOccurred())
+		defer os.Remove(f.Name())
+		_, err = f.WriteString(json)
+		Ω(err).NotTo(HaveOccurred())
+		f.Close()
+
+		viper.Reset()
+		viper.SetConfigType("json")
+		viper.SetConfigFile(f.Name())
+		Ω(viper.ReadInConfig()).Should(Succeed())
+
+		c := newConfig("qa")
+		c.file, c.loaded = f.Name(), true
+		c.loadConnections()
+
+		return c
+	}
+
+	problems := func(err error) []string {
+		Ω(err).Should(HaveOccurred())
+		return err.(*ConfigError).Problems
+	}
+
+	It("should accept a valid config", func() {
+		c := load(`{"qa": {"mssql": {"default": {"server": "db1", "port": 1433, "dbname": "app", "maxidleconnections": 5,
+			"maxopenconnections": "50", "debug": "true", "dialtimeout": "5s"}}}}`)
+		Ω(c.Validate()).Should(Succeed())
+		Ω(c.MSSQLDBs["qa_mssql_default"].MaxOpenConnections).Should(Equal(50))
+	})
+
+	It("should accept dynamodb's endpoint, credentials and table prefix", func() {
+		c := load(`{"qa": {"dynamodb": {"local": {"region": "us-east-1", "endpoint": "http://localhost:8000",
+			"profile": "dev", "credentialsfile": "/etc/aws/credentials", "tableprefix": "qa_"}}}}`)
+		Ω(c.Validate()).Should(Succeed())
+		Ω(*c.DynamoDBs["qa_dynamodb_local"]).Should(Equal(DynamoDBConfig{ConnectionName: "qa_dynamodb_local", Region: "us-east-1",
+			Endpoint: "http://localhost:8000", Profile: "dev", CredentialsFile: "/etc/aws/credentials", TablePrefix: "qa_"}))
+	})
+
+	It("should report unknown and mistyped keys", func() {
+		c := load(`{"qa": {
+			"mssql": {"default": {"server": "db1", "dbname": "app", "maxopenconnection": 50, "port": "x", "dialtimeout": "5"}},
+			"postgres": {"default": {"server": "db1"}},
+			"rethinkdb": {"addresses": "db1:28015"}}}`)
+		Ω(problems(c.Validate())).Should(Equal([]string{
+			"qa.mssql.default.dialtimeout must be a duration, EX: 5s, not 5",
+			"qa.mssql.default.maxopenconnection is not a mssql setting",
+			"qa.mssql.default.port must be an integer, not x",
+			"qa.postgres is not an adapter, expected one of: cloudant, couchbase, dynamodb, mssql, orchestrate, postgresql, rethinkdb",
+			"qa.rethinkdb.addresses should be a connection, EX: qa.rethinkdb.default.addresses",
+		}))
+	})
+
+	It("should only check the environment's keys", func() {
+		c := load(`{"qa": {"rethinkdb": {"default": {"addresses": "db1:28015", "dbname": "app"}}}, "prod": {"rethinkdb": {"default": {"dbnme": "app"}}}}`)
+		Ω(c.Validate()).Should(Succeed())
+		Ω(func() { c.LoadEnvironment("prod") }).Should(Panic())
+	})
+
+	It("should report missing and impossible settings", func() {
+		err := Configure(Options{
+			Environment: "prod",
+			MSSQLDBs:    map[string]*MSSQLConfig{"default": {Server: "db1", DBName: "app", Port: 70000, FailoverPort: 1434}},
+			PostgresqlDBs: map[string]*PostgresqlDBConfig{"default": {Server: "db2", MaxIdleConnections: 20, MaxOpenConnections: 10,
+				DialTimeout: -time.Second, TLS: TLSConfig{Mode: "sometimes"}}},
+			RethinkDBs:   map[string]*RethinkDBConfig{"default": {Addresses: []string{""}, DBName: "app"}},
+			CouchbaseDBs: map[string]*CouchbaseConfig{"default": {ClusterAddress: "db3"}},
+			DynamoDBs: map[string]*DynamoDBConfig{"default": {Region: "moon", AccessKey: "key", Profile: "dev"},
+				"local": {Region: "useast", Endpoint: "localhost:8000"}},
+		})
+		Ω(problems(err)).Should(Equal([]string{
+			"prod_couchbase_default: bucket_name is required",
+			"prod_dynamodb_default: accesskey and profile are alternatives, so set one or the other",
+			"prod_dynamodb_default: accesskey and secretkey go together",
+			`prod_dynamodb_default: region must be an aws region, EX: us-west-2, not "moon"`,
+			`prod_dynamodb_local: endpoint must be a url, EX: http://localhost:8000, not "localhost:8000"`,
+			"prod_mssql_default: failoverport needs a failoverpartner",
+			"prod_mssql_default: port must be between 1 and 65535, not 70000",
+			"prod_postgresql_default: dbname is required",
+			"prod_postgresql_default: dialtimeout cannot be negative",
+			`prod_postgresql_default: invalid tls mode "sometimes", expected disable, require, verify-ca or verify-full`,
+			"prod_postgresql_default: maxidleconnections (20) cannot exceed maxopenconnections (10)",
+			"prod_rethinkdb_default: addresses cannot be blank, EX: db1:28015,db2:28015",
+		}))
+		Ω(Config).Should(Equal(saved))
+	})
+})
@@ -0,0 +1,93 @@
+package goar
+
+import "reflect"
+
+// ConnectionResolver picks the connection used for a single db operation.
+// Returning a blank name or environment falls back to the model's
+// DBConnectionName() or DBConnectionEnvironment() respectively.
+// EX: route each tenant's vehicles to its own rethinkdb database
+//
+//	goar.SetConnectionResolver(func(ari goar.ActiveRecordInterfacer) (string, string) {
+//		if v, ok := ari.(*Vehicle); ok {
+//			return "tenant_" + v.TenantID, ""
+//		}
+//		return "", ""
+//	})
+type ConnectionResolver func(ari ActiveRecordInterfacer) (connName string, env string)
+
+var connectionResolver ConnectionResolver
+
+// SetConnectionResolver registers the package wide resolver.  Pass nil to
+// restore the static DBConnectionName()/DBConnectionEnvironment() behavior.
+func SetConnectionResolver(resolver ConnectionResolver) {
+	connectionResolver = resolver
+}
+
+// UseConnection routes every subsequent operation on this instance to the
+// given connection, overriding both the resolver and the model's defaults.
+// Blank values are ignored so that, for example, only the name can be set.
+func (ar *ActiveRecord) UseConnection(connName string, env string) *ActiveRecord {
+	ar.connName = connName
+	ar.connEnv = env
+	return ar
+}
+
+// ResolveConnection returns the connection name and environment an adapter
+// should use for the given model.  Precedence:
+//  1. instance override set via UseConnection
+//  2. the shard selected by the model's ShardMap (see Sharder)
+//  3. package wide ConnectionResolver
+//  4. the model's DBConnectionName() and DBConnectionEnvironment()
+func ResolveConnection(ari ActiveRecordInterfacer) (connName string, env string) {
+	if ar, ok := activeRecordOf(ari); ok {
+		connName, env = ar.connName, ar.connEnv
+	}
+
+	if s, ok := ari.(Sharder); ok && connName == "" {
+		connName = s.ShardMap().Shard(s.ShardKey())
+	}
+
+	if connectionResolver != nil && (connName == "" || env == "") {
+		name, e := connectionResolver(ari)
+		if connName == "" {
+			connName = name
+		}
+		if env == "" {
+			env = e
+		}
+	}
+
+	if connName == "" {
+		connName = ari.DBConnectionName()
+	}
+	if env == "" {
+		env = ari.DBConnectionEnvironment()
+	}
+
+	return connName, env
+}
+
+type activeRecorder interface {
+	activeRecord() *ActiveRecord
+}
+
+func (ar *ActiveRecord) activeRecord() *ActiveRecord {
+	return ar
+}
+
+func activeRecordOf(ari ActiveRecordInterfacer) (*ActiveRecord, bool) {
+	if v, ok := ari.(activeRecorder); ok {
+		return v.activeRecord(), true
+	}
+
+	return nil, false
+}
+
+// newInstanceOf returns a blank instance of the model's type that uses the model's connection.
+// NOTE: the connection is resolved from the model, b/c a resolver or shard map would route the blank instance elsewhere
+func newInstanceOf(ari ActiveRecordInterfacer) ActiveRecordInterfacer {
+	instance := ToAR(reflect.New(reflect.TypeOf(ari).Elem()).Interface().(ActiveRecordInterfacer))
+	instance.(activeRecorder).activeRecord().UseConnection(ResolveConnection(ari))
+
+	return instance
+}
@@ -0,0 +1,50 @@
+package goar
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Connection", func() {
+
+	var automobile *ActiveRecordAutomobile
+
+	BeforeEach(func() {
+		automobile = validAutomobileFactory()
+	})
+
+	AfterEach(func() {
+		SetConnectionResolver(nil)
+	})
+
+	It("should default to the model's static connection", func() {
+		connName, env := ResolveConnection(automobile)
+		Ω(connName).Should(Equal("aws"))
+		Ω(env).Should(Equal("test"))
+	})
+
+	It("should resolve the connection via the resolver", func() {
+		SetConnectionResolver(func(ari ActiveRecordInterfacer) (string, string) {
+			return "tenant_" + ari.(*ActiveRecordAutomobile).Make, ""
+		})
+
+		connName, env := ResolveConnection(automobile)
+		Ω(connName).Should(Equal("tenant_porsche"))
+		Ω(env).Should(Equal("test"))
+	})
+
+	It("should prefer the instance connection over the resolver", func() {
+		SetConnectionResolver(func(ari ActiveRecordInterfacer) (string, string) {
+			return "resolved", "resolved"
+		})
+		automobile.UseConnection("tenant_a", "")
+
+		connName, env := ResolveConnection(automobile)
+		Ω(connName).Should(Equal("tenant_a"))
+		Ω(env).Should(Equal("resolved"))
+
+		other := validAutomobileFactory()
+		connName, _ = ResolveConnection(other)
+		Ω(connName).Should(Equal("resolved")) // ensure the override isn't shared among multiple instances
+	})
+})
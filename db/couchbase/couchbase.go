@@ -1,8 +1,13 @@
 package couchbase
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"log"
+	"net/http"
+	"net/url"
+	"sync"
 
 	gocb "github.com/couchbase/gocb"
 	goar "github.com/obieq/goar"
@@ -20,14 +25,12 @@ type ArCouchbase struct {
 var _ goar.Persister = (*ArCouchbase)(nil)
 
 var (
-	clients = map[string]*gocb.Bucket{}
+	clients      = map[string]*gocb.Bucket{}
+	clientsMutex sync.Mutex
 )
 
 func connect(connName string, env string) *gocb.Bucket {
-	cfg := goar.Config
-	if cfg == nil {
-		log.Panicln("goar couchbase config cannot be nil")
-	}
+	cfg := goar.Configuration()
 
 	connKey := env + "_couchbase_" + connName
 	m, found := cfg.CouchbaseDBs[connKey]
@@ -41,11 +44,17 @@ func connect(connName string, env string) *gocb.Bucket {
 		log.Println("---- WARNING --- bucket password is blank")
 	}
 
-	cluster, _ := gocb.Connect("couchbase://" + m.ClusterAddress + "/")
+	cluster, _ := gocb.Connect(connSpec(m))
+	if m.DialTimeout > 0 {
+		cluster.SetConnectTimeout(m.DialTimeout)
+	}
 	bucket, err := cluster.OpenBucket(m.BucketName, m.BucketPassword)
 
 	if err != nil {
-		log.Fatalf("Error getting bucket:  %v", err)
+		log.Panicf("Error getting bucket:  %v", err)
+	}
+	if m.ReadTimeout > 0 {
+		bucket.SetOperationTimeout(m.ReadTimeout)
 	}
 
 	// bucket.SetTranscoder(TestTranscoder{})
@@ -53,22 +62,102 @@ func connect(connName string, env string) *gocb.Bucket {
 	return bucket
 }
 
+// connSpec returns the cluster's connection string, which is couchbases:// for tls.
+// It panics on tls settings gocb doesn't support b/c that's a config error.
+// NOTE: this gocb encrypts w/o verifying the server's certificate, so only the require tls mode works,
+// and couchbase has no application name.
+func connSpec(m *goar.CouchbaseConfig) string {
+	if err := m.TLS.Validate(); err != nil {
+		log.Panicln("invalid couchbase tls config:", err)
+	} else if m.TLS.Verified() || m.TLS.CAFile != "" || m.TLS.CertFile != "" || m.TLS.ServerName != "" {
+		log.Panicln("couchbase only supports the", goar.TLSRequire, "tls mode, w/o certificates or a server name")
+	}
+
+	if m.TLS.Enabled() {
+		return "couchbases://" + m.ClusterAddress + "/"
+	}
+
+	return "couchbase://" + m.ClusterAddress + "/"
+}
+
 func (ar *ArCouchbase) Client() *gocb.Bucket {
 	self := ar.Self()
-	connectionKey := self.DBConnectionName() + "_" + self.DBConnectionEnvironment()
 	if self == nil {
 		log.Panic("couchbase ar.Self() cannot be blank!")
 	}
 
+	return bucket(goar.ResolveConnection(self))
+}
+
+// ShardClient returns the bucket of one of a sharded model's connections
+func (ar *ArCouchbase) ShardClient(connName string) *gocb.Bucket {
+	self := ar.Self()
+	if self == nil {
+		log.Panic("couchbase ar.Self() cannot be blank!")
+	}
+
+	_, env := goar.ResolveConnection(self)
+	return bucket(connName, env)
+}
+
+// bucket opens the connection's bucket once.  Opening it can take the cluster's whole
+// timeout, so it's done w/o holding clientsMutex.
+func bucket(connName string, env string) *gocb.Bucket {
+	connectionKey := connName + "_" + env
+
+	clientsMutex.Lock()
 	conn, found := clients[connectionKey]
-	if !found {
-		conn = connect(self.DBConnectionName(), self.DBConnectionEnvironment())
-		clients[connectionKey] = conn
+	clientsMutex.Unlock()
+	if found {
+		return conn
 	}
 
+	conn = connect(connName, env)
+
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
+	if existing, found := clients[connectionKey]; found {
+		conn.Close() // another caller connected first
+		return existing
+	}
+	clients[connectionKey] = conn
+
 	return conn
 }
 
+func init() {
+	goar.RegisterHealthCheck(goar.COUCHBASE, healthCheck)
+}
+
+// healthCheck asks a management node for the bucket's stats
+func healthCheck(ctx context.Context, connName string, env string) error {
+	b := bucket(connName, env)
+	m := goar.Configuration().CouchbaseDBs[env+"_couchbase_"+connName]
+
+	endpoints := b.IoRouter().MgmtEps()
+	if len(endpoints) == 0 {
+		return errors.New("couchbase has no management nodes")
+	}
+
+	req, err := http.NewRequest("GET", endpoints[0]+"/pools/default/buckets/"+url.PathEscape(m.BucketName)+"/stats", nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(m.BucketName, m.BucketPassword)
+
+	resp, err := b.IoRouter().HttpClient().Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("couchbase bucket %s stats: %s", m.BucketName, resp.Status)
+	}
+
+	return nil
+}
+
 func (ar *ArCouchbase) SetKey(key string) {
 	ar.ID = key
 }
@@ -85,7 +174,18 @@ func (ar *ArCouchbase) Truncate() (numRowsDeleted int, err error) {
 }
 
 func (ar *ArCouchbase) Find(id interface{}, out interface{}) error {
-	_, err := ar.Client().Get(id.(string), &out)
+	return goar.ReadThrough(ar.Self(), id, out, func() error {
+		return ar.find(id, out)
+	})
+}
+
+func (ar *ArCouchbase) find(id interface{}, out interface{}) error {
+	client := ar.Client()
+	if sharder, ok := ar.Self().(goar.Sharder); ok {
+		client = ar.ShardClient(sharder.ShardMap().Shard(fmt.Sprint(id)))
+	}
+
+	_, err := client.Get(id.(string), &out)
 	return err
 }
 
@@ -102,15 +202,31 @@ func (ar *ArCouchbase) DbSave() error {
 			err = errors.New("Insert Failed: key already exists")
 		}
 	} else {
+		var old interface{}
+		if _, outboxed := ar.Self().(goar.Outboxer); outboxed { // read the previous version for the event's changes
+			if _, err = ar.Client().Get(ar.ID, &old); err != nil {
+				return err
+			}
+		}
+
 		// err = client.Set(ar.ID, 0, ar.Self())
-		cas, err = ar.Client().Replace(ar.ID, ar.Self(), 0, 0)
+		if cas, err = ar.Client().Replace(ar.ID, ar.Self(), 0, 0); err == nil {
+			err = ar.writeOutboxEvent(goar.OutboxUpdate, old, ar.Self())
+		}
+		return err
+	}
+
+	if err == nil {
+		err = ar.writeOutboxEvent(goar.OutboxCreate, nil, ar.Self())
 	}
 
 	return err
 }
 
 func (ar *ArCouchbase) DbDelete() (err error) {
-	_, err = ar.Client().Remove(ar.ID, 0)
+	if _, err = ar.Client().Remove(ar.ID, 0); err == nil {
+		err = ar.writeOutboxEvent(goar.OutboxDelete, ar.Self(), nil)
+	}
 	return err
 }
 
@@ -119,6 +235,10 @@ func (ar *ArCouchbase) DbSearch(models interface{}) (err error) {
 }
 
 func (ar *ArCouchbase) N1qlQuery(query string, models *[]interface{}) (err error) {
+	if _, ok := ar.Self().(goar.Sharder); ok {
+		return errors.New("couchbase does not query across shards, so sharded models can only be found by id")
+	}
+
 	var rows gocb.ViewResults
 	n1qlQuery := gocb.NewN1qlQuery(query)
 	if rows, err = ar.Client().ExecuteN1qlQuery(n1qlQuery, nil); err != nil {
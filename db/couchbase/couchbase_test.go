@@ -1,6 +1,7 @@
 package couchbase_test
 
 import (
+	"context"
 	"log"
 
 	. "github.com/obieq/goar"
@@ -158,3 +159,36 @@ var _ = Describe("Couchbase", func() {
 		})
 	})
 })
+
+var _ = Describe("Couchbase Health Check", func() {
+	It("should report the connection ok", func() {
+		checked := false
+		for _, h := range HealthCheck(context.Background()).Connections {
+			if h.Adapter == COUCHBASE && h.Connection == "aws" {
+				Ω(h.Status).Should(Equal(HealthOK), h.Error)
+				checked = true
+			}
+		}
+		Ω(checked).Should(BeTrue())
+	})
+})
+
+type ShardedCouchbaseAutomobile struct {
+	CouchbaseAutomobile
+}
+
+func (m *ShardedCouchbaseAutomobile) ShardMap() ShardMap {
+	return RangeShardMap{{ConnName: "aws"}}
+}
+
+func (m *ShardedCouchbaseAutomobile) ShardKey() string {
+	return m.Make
+}
+
+var _ = Describe("Couchbase Sharding", func() {
+	It("should not query a sharded model's shards one at a time", func() {
+		var results []interface{}
+		auto := ToAR(&ShardedCouchbaseAutomobile{}).(*ShardedCouchbaseAutomobile)
+		Ω(auto.N1qlQuery("SELECT * FROM default", &results)).Should(MatchError(ContainSubstring("across shards")))
+	})
+})
@@ -0,0 +1,148 @@
+package couchbase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	gocb "github.com/couchbase/gocb"
+	goar "github.com/obieq/goar"
+	"github.com/obieq/goar/migration"
+)
+
+// interface assertions
+var _ migration.Driver = (*MigrationDriver)(nil)
+
+// MigrationDriver runs goar migrations against a connection's bucket.
+// Every model shares the bucket, so migrations mostly create N1QL indexes.
+// The ledger is a single document in the bucket, keyed by migration.LedgerName.
+type MigrationDriver struct {
+	Client     *gocb.Bucket
+	BucketName string
+}
+
+// NewMigrationDriver uses the connection's bucket, EX: NewMigrationDriver("aws", "prod")
+func NewMigrationDriver(connName string, env string) *MigrationDriver {
+	client := bucket(connName, env)
+	return &MigrationDriver{Client: client, BucketName: goar.Configuration().CouchbaseDBs[env+"_couchbase_"+connName].BucketName}
+}
+
+type ledger struct {
+	Versions map[string]time.Time `json:"versions"` // applied_at by version
+}
+
+// Exec runs any other N1QL statement, EX: CREATE PRIMARY INDEX ON `bucket`
+func (d *MigrationDriver) Exec(statement string) error {
+	rows, err := d.Client.ExecuteN1qlQuery(gocb.NewN1qlQuery(statement), nil)
+	if err != nil {
+		return err
+	}
+
+	return rows.Close()
+}
+
+func (d *MigrationDriver) CreateDb(dbName string) error {
+	return errors.New("couchbase buckets must be created via the cluster's admin console or REST api")
+}
+
+func (d *MigrationDriver) DropDb(dbName string) error {
+	return errors.New("couchbase buckets must be dropped via the cluster's admin console or REST api")
+}
+
+// CreateTable is a no-op b/c every model is stored in the connection's bucket
+func (d *MigrationDriver) CreateTable(tableName string) error {
+	return nil
+}
+
+// DropTable is a no-op b/c every model is stored in the connection's bucket
+func (d *MigrationDriver) DropTable(tableName string) error {
+	return nil
+}
+
+// AddIndex creates a N1QL index on the bucket.  It supports the opts:
+// name (defaults to idx_<table>_<fields>) and where, EX: {"where": "type = \"vehicle\""}
+func (d *MigrationDriver) AddIndex(tableName string, fields []string, opts map[string]interface{}) error {
+	name, _ := opts["name"].(string)
+	if name == "" {
+		name = "idx_" + tableName + "_" + strings.Join(fields, "_")
+	}
+
+	columns := []string{}
+	for _, f := range fields {
+		columns = append(columns, "`"+f+"`")
+	}
+
+	statement := fmt.Sprintf("CREATE INDEX `%s` ON `%s`(%s)", name, d.BucketName, strings.Join(columns, ", "))
+	if where, _ := opts["where"].(string); where != "" {
+		statement += " WHERE " + where
+	}
+
+	return d.Exec(statement + " USING GSI")
+}
+
+func (d *MigrationDriver) EnsureLedger() error {
+	_, err := d.Client.Insert(migration.LedgerName, ledger{Versions: map[string]time.Time{}}, 0)
+	if isKeyExists(err) {
+		return nil
+	}
+
+	return err
+}
+
+func (d *MigrationDriver) AppliedVersions() ([]int64, error) {
+	l := ledger{}
+	if _, err := d.Client.Get(migration.LedgerName, &l); err != nil {
+		return nil, err
+	}
+
+	versions := []int64{}
+	for v := range l.Versions {
+		var version int64
+		if _, err := fmt.Sscan(v, &version); err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+func (d *MigrationDriver) RecordVersion(version int64) error {
+	return d.updateLedger(func(l *ledger) {
+		l.Versions[fmt.Sprint(version)] = time.Now().UTC()
+	})
+}
+
+func (d *MigrationDriver) RemoveVersion(version int64) error {
+	return d.updateLedger(func(l *ledger) {
+		delete(l.Versions, fmt.Sprint(version))
+	})
+}
+
+// updateLedger replaces the ledger via CAS, so a concurrent migrate fails instead of losing a version
+// Ping reads a document that doesn't exist, b/c gocb can't ping a bucket
+func (d *MigrationDriver) Ping() error {
+	var v interface{}
+	if _, err := d.Client.Get("goar_ping", &v); err != nil && !isKeyNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (d *MigrationDriver) updateLedger(fn func(l *ledger)) error {
+	l := ledger{}
+	cas, err := d.Client.Get(migration.LedgerName, &l)
+	if err != nil {
+		return err
+	}
+
+	if l.Versions == nil {
+		l.Versions = map[string]time.Time{}
+	}
+	fn(&l)
+
+	_, err = d.Client.Replace(migration.LedgerName, l, cas, 0)
+	return err
+}
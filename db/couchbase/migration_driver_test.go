@@ -0,0 +1,38 @@
+package couchbase_test
+
+import (
+	. "github.com/obieq/goar/db/couchbase"
+	. "github.com/obieq/goar/db/couchbase/Godeps/_workspace/src/github.com/onsi/ginkgo"
+	. "github.com/obieq/goar/db/couchbase/Godeps/_workspace/src/github.com/onsi/gomega"
+	"github.com/obieq/goar/migration"
+)
+
+var _ = Describe("Couchbase Migration Driver", func() {
+
+	var driver *MigrationDriver
+
+	BeforeEach(func() {
+		driver = NewMigrationDriver("aws", "test")
+		driver.Client.Remove(migration.LedgerName, 0)
+	})
+
+	It("should record and remove versions", func() {
+		Ω(driver.EnsureLedger()).Should(Succeed())
+		Ω(driver.EnsureLedger()).Should(Succeed())
+		Ω(driver.RecordVersion(20151014093000)).Should(Succeed())
+		Ω(driver.RecordVersion(20151015093000)).Should(Succeed())
+		Ω(driver.AppliedVersions()).Should(ConsistOf(int64(20151014093000), int64(20151015093000)))
+
+		Ω(driver.RemoveVersion(20151015093000)).Should(Succeed())
+		Ω(driver.AppliedVersions()).Should(Equal([]int64{20151014093000}))
+	})
+
+	It("should not manage buckets", func() {
+		Ω(driver.CreateDb("vehicles")).ShouldNot(Succeed())
+		Ω(driver.CreateTable("vehicles")).Should(Succeed())
+	})
+
+	It("should ping the connection", func() {
+		Ω(driver.Ping()).Should(Succeed())
+	})
+})
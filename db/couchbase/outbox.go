@@ -0,0 +1,79 @@
+package couchbase
+
+import (
+	"errors"
+	"fmt"
+
+	gocb "github.com/couchbase/gocb"
+	goar "github.com/obieq/goar"
+)
+
+// interface assertions
+var _ goar.OutboxStore = (*ArCouchbase)(nil)
+
+// outbox events are stored in the model's bucket, keyed by this prefix and the event id
+const outboxKeyPrefix = goar.OutboxModelName + "::"
+
+// PendingOutboxEvents reads the oldest events from the model's bucket
+// NOTE: requires a N1QL index, EX: CREATE INDEX goar_outbox ON `bucket`(occurred_at) WHERE META().id LIKE "goar_outbox::%"
+func (ar *ArCouchbase) PendingOutboxEvents(limit int) ([]*goar.OutboxEvent, error) {
+	connName, env := goar.ResolveConnection(ar.Self())
+	m, found := goar.Configuration().CouchbaseDBs[env+"_couchbase_"+connName]
+	if !found {
+		return nil, errors.New("couchbase db connection not found: " + env + "_couchbase_" + connName)
+	}
+
+	statement := fmt.Sprintf("SELECT o.* FROM `%s` o WHERE META(o).id LIKE \"%s%%\" ORDER BY o.occurred_at LIMIT %d", m.BucketName, outboxKeyPrefix, limit)
+	rows, err := ar.Client().ExecuteN1qlQuery(gocb.NewN1qlQuery(statement).Consistency(gocb.RequestPlus), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []*goar.OutboxEvent{}
+	event := &goar.OutboxEvent{}
+	for rows.Next(event) {
+		events = append(events, event)
+		event = &goar.OutboxEvent{}
+	}
+
+	return events, rows.Close()
+}
+
+func (ar *ArCouchbase) MarkOutboxEventsPublished(ids []string) error {
+	for _, id := range ids {
+		_, err := ar.Client().Remove(outboxKeyPrefix+id, 0)
+		if err != nil && !isKeyNotFound(err) { // not found means another dispatcher already removed it
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeOutboxEvent records a change to the model.
+// couchbase can't write two documents atomically, so the event is written
+// right after the model.  If it fails, the model is saved but the error is returned.
+func (ar *ArCouchbase) writeOutboxEvent(operation string, before interface{}, after interface{}) error {
+	if _, outboxed := ar.Self().(goar.Outboxer); !outboxed {
+		return nil
+	}
+
+	changes, err := goar.ChangedFields(before, after)
+	if err != nil {
+		return err
+	}
+
+	event := goar.NewOutboxEvent(ar.Self(), operation, changes)
+	if _, err = ar.Client().Insert(outboxKeyPrefix+event.ID, event, 0); err != nil {
+		return errors.New("the model was written, but its outbox event was not: " + err.Error())
+	}
+
+	return nil
+}
+
+func isKeyNotFound(err error) bool {
+	nf, ok := err.(interface {
+		KeyNotFound() bool
+	})
+	return ok && nf.KeyNotFound()
+}
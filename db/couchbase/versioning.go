@@ -0,0 +1,65 @@
+package couchbase
+
+import (
+	"errors"
+
+	goar "github.com/obieq/goar"
+)
+
+// interface assertions
+var _ goar.VersionStore = (*ArCouchbase)(nil)
+
+// versionHistory is the document that holds a record's versions, EX: key automobiles_versions::<id>
+type versionHistory struct {
+	Versions []*goar.Version `json:"versions"`
+}
+
+// WriteVersion appends the version to the record's history document.
+// The document is replaced via CAS so that concurrent writers don't drop each other's versions.
+func (ar *ArCouchbase) WriteVersion(version *goar.Version) error {
+	key := ar.versionsKey(version.Key)
+
+	for attempt := 0; attempt < 5; attempt++ {
+		history := versionHistory{}
+		cas, err := ar.Client().Get(key, &history)
+		if isKeyNotFound(err) {
+			history.Versions = []*goar.Version{version}
+			if _, err = ar.Client().Insert(key, history, 0); err == nil {
+				return nil
+			}
+		} else if err != nil {
+			return err
+		} else {
+			history.Versions = append(history.Versions, version)
+			if _, err = ar.Client().Replace(key, history, cas, 0); err == nil {
+				return nil
+			}
+		}
+
+		if !isKeyExists(err) { // the history was written concurrently, so re-read it and try again
+			return err
+		}
+	}
+
+	return errors.New("couchbase version history was modified concurrently: " + key)
+}
+
+func (ar *ArCouchbase) ReadVersions(key string) ([]*goar.Version, error) {
+	history := versionHistory{Versions: []*goar.Version{}}
+	if _, err := ar.Client().Get(ar.versionsKey(key), &history); err != nil && !isKeyNotFound(err) {
+		return nil, err
+	}
+
+	return history.Versions, nil
+}
+
+func (ar *ArCouchbase) versionsKey(key string) string {
+	return ar.Self().ModelName() + "_versions::" + key
+}
+
+func isKeyExists(err error) bool {
+	ke, ok := err.(interface {
+		KeyExists() bool
+	})
+	return ok && ke.KeyExists()
+}
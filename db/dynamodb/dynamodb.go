@@ -1,12 +1,21 @@
 package dynamodb
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"log"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
 
 	aws "github.com/AdRoll/goamz/aws"
 	dynamo "github.com/AdRoll/goamz/dynamodb"
+	"github.com/AdRoll/goamz/dynamodb/dynamizer"
 	goar "github.com/obieq/goar"
+	"github.com/obieq/goar/schema"
 )
 
 const DB_PRIMARY_KEY_NAME string = "id"
@@ -23,73 +32,171 @@ type ArDynamodb struct {
 var _ goar.Persister = (*ArDynamodb)(nil)
 
 var (
-	clients = map[string]*dynamo.Server{}
+	clients      = map[string]*dynamo.Server{}
+	clientsMutex sync.Mutex
 )
 
 func connect(connName string, env string) (s *dynamo.Server) {
-	c := goar.Config
-	if c == nil {
-		log.Panicln("goar config cannot be nil")
+	m := connection(connName, env)
+	if m.Region == "" {
+		log.Panicln("dynamodb aws region cannot be blank")
+	}
+
+	auth, err := credentials(m)
+	if err != nil {
+		log.Panicln("dynamodb credentials not found for", m.ConnectionName+":", err)
 	}
 
+	return dynamo.New(auth, region(m.AWSRegion(), m.Endpoint))
+}
+
+func connection(connName string, env string) *goar.DynamoDBConfig {
 	connKey := env + "_dynamodb_" + connName
-	m, found := c.DynamoDBs[connKey]
+	m, found := goar.Configuration().DynamoDBs[connKey]
 	if !found {
 		log.Panicln("dynamodb connection not found:", connKey)
-	} else if m.Region == "" {
-		log.Panicln("dynamodb aws region cannot be blank")
-	} else if m.AccessKey == "" {
-		log.Panicln("dynamodb access key cannot be blank")
-	} else if m.SecretKey == "" {
-		log.Panicln("dynamodb secret key cannot be blank")
 	}
 
-	var region aws.Region
-	switch m.Region {
-	case "useast":
-		region = aws.USEast
-	case "uswest":
-		region = aws.USWest
-	case "uswest2":
-		region = aws.USWest2
-	default:
-		log.Panicln("invalid region:", m.Region)
+	return m
+}
+
+// region looks up the region, which requests are signed for.  Regions goamz doesn't know, EX: ap-south-1,
+// are served by their standard endpoint, unless the connection has its own, EX: http://localhost:8000
+func region(name string, endpoint string) aws.Region {
+	r, found := aws.Regions[name]
+	if !found {
+		r = aws.Region{Name: name, DynamoDBEndpoint: "https://dynamodb." + name + ".amazonaws.com"}
+		if strings.HasPrefix(name, "cn-") {
+			r.DynamoDBEndpoint += ".cn"
+		}
+	}
+
+	if endpoint != "" {
+		r.DynamoDBEndpoint = strings.TrimSuffix(endpoint, "/")
 	}
-	auth := aws.Auth{AccessKey: m.AccessKey, SecretKey: m.SecretKey}
 
-	return dynamo.New(auth, region)
+	return r
+}
+
+// credentials are the connection's keys, else its profile in the shared credentials file, else
+// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, the instance's role or the default profile, EX: AWS_PROFILE=dev.
+// NOTE: the role's and the file's credentials expire, so the connection is made again when they do.
+func credentials(m *goar.DynamoDBConfig) (aws.Auth, error) {
+	if m.AccessKey != "" {
+		return *aws.NewAuth(m.AccessKey, m.SecretKey, m.SessionToken, time.Time{}), nil
+	}
+
+	profile, file := m.Profile, m.CredentialsFile
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+	if file == "" {
+		file = os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	}
+	if profile != "" || file != "" {
+		return aws.CredentialFileAuth(file, profile, time.Hour)
+	}
+
+	return aws.GetAuth("", "", "", time.Time{})
+}
+
+// expired checks whether the credentials expire within a minute
+func expired(auth *aws.Auth) bool {
+	expiration := auth.Expiration()
+	return !expiration.IsZero() && time.Now().Add(time.Minute).After(expiration)
 }
 
 func (ar *ArDynamodb) Client() *dynamo.Server {
 	self := ar.Self()
-	connectionKey := self.DBConnectionName() + "_" + self.DBConnectionEnvironment()
 	if self == nil {
 		log.Panic("ar.Self() cannot be blank!")
 	}
 
+	return server(goar.ResolveConnection(self))
+}
+
+// server connects once per connection, and again when its credentials expire.
+// NOTE: the credentials can come from the instance's metadata, so they're fetched w/o holding clientsMutex.
+func server(connName string, env string) *dynamo.Server {
+	connectionKey := connName + "_" + env
+
+	clientsMutex.Lock()
 	conn, found := clients[connectionKey]
-	if !found {
-		conn = connect(self.DBConnectionName(), self.DBConnectionEnvironment())
-		clients[connectionKey] = conn
+	clientsMutex.Unlock()
+	if found && !expired(&conn.Auth) {
+		return conn
 	}
 
+	conn = connect(connName, env)
+
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
+	if existing, found := clients[connectionKey]; found && !expired(&existing.Auth) {
+		return existing
+	}
+	clients[connectionKey] = conn
+
 	return conn
 }
 
+// tableName prefixes the table's name w/ the connection's prefix, EX: test_DynamodbAutomobiles
+func tableName(connName string, env string, name string) string {
+	return connection(connName, env).TablePrefix + name
+}
+
+func init() {
+	goar.RegisterHealthCheck(goar.DYNAMODB, healthCheck)
+}
+
+// healthCheck lists the region's tables, which also checks the credentials.
+// NOTE: goamz has no context, so goar.HealthCheck stops waiting when ctx is done.
+func healthCheck(ctx context.Context, connName string, env string) error {
+	_, err := server(connName, env).ListTables()
+	return err
+}
+
 func (ar *ArDynamodb) SetKey(key string) {
 	ar.ID = key
 }
 
+// All scans the table, page by page, into models, EX: &[]Vehicle{}.
+// Like the other adapters, it returns 100 models unless opts["limit"] says otherwise, and at most 1000.
+// NOTE: models w/ a bool, or interface{}, field cost a GetItem per model on top of the scan, see collect.
 func (ar *ArDynamodb) All(models interface{}, opts map[string]interface{}) (err error) {
-	return errors.New("All method not supported by Dynamodb.  Create a View instead.")
+	var limit int = 100
+
+	// set limit
+	if opts["limit"] != nil {
+		limit = opts["limit"].(int)
+		if limit > 1000 { // max limit is 1000
+			return errors.New("limit must be less than 1001")
+		} else if limit < 1 {
+			return errors.New("limit must be greater than 0")
+		}
+	}
+
+	return ar.collect(&search{limit: limit}, models)
 }
 
+// Truncate deletes every item in batches, rather than recreating the table, which would lose
+// its indexes and ttl and be unavailable until it's active again
 func (ar *ArDynamodb) Truncate() (numRowsDeleted int, err error) {
-	return -1, errors.New("Truncate method not yet implemented")
+	t := ar.table()
+	return truncate(t.Server, t.Name)
 }
 
 func (ar *ArDynamodb) Find(id interface{}, out interface{}) error {
-	tbl, dynamoKey := ar.GetTableWithPrimaryKey(id)
+	return goar.ReadThrough(ar.Self(), id, out, func() error {
+		return ar.find(id, out)
+	})
+}
+
+func (ar *ArDynamodb) find(id interface{}, out interface{}) error {
+	connName, env := goar.ResolveConnection(ar.Self())
+	if sharder, ok := ar.Self().(goar.Sharder); ok {
+		connName = sharder.ShardMap().Shard(fmt.Sprint(id))
+	}
+	tbl, dynamoKey := ar.tableOn(connName, env), &dynamo.Key{HashKey: id.(string)}
 
 	// NOTE: the AdRoll sdk returns an error if the key doesn't exist
 	if err := tbl.GetDocument(dynamoKey, out); err != nil {
@@ -154,16 +261,95 @@ func (ar *ArDynamodb) DbSave() error {
 // }
 
 func (ar *ArDynamodb) DbDelete() (err error) {
-	primary := dynamo.NewStringAttribute(DB_PRIMARY_KEY_NAME, "")
-	pk := dynamo.PrimaryKey{KeyAttribute: primary}
-	t := dynamo.Table{Server: ar.Client(), Name: ar.ModelName(), Key: pk}
-
+	t := ar.table()
 	dynamoKey := &dynamo.Key{HashKey: ar.ID}
 	return t.DeleteDocument(dynamoKey)
 }
 
+// DbSearch queries the table, or one of its declared indexes, by its hash key when the query has
+// an EQ condition on it, and otherwise scans the table w/ a filter.  Results can only be ordered by
+// the queried range key, EX: ordering a make's years needs `schema:"index=idx_make_year"` on Make and Year.
+// NOTE: like All, each result is read again by id when the model has a bool field.
 func (ar *ArDynamodb) DbSearch(models interface{}) (err error) {
-	return errors.New("Search method not supported by Dynamodb.  Create a View instead.")
+	s, err := compileSearch(schema.Of(ar.Self(), schema.DynamoDB), ar.Query())
+	if err != nil {
+		return err
+	}
+
+	return ar.collect(s, models)
+}
+
+// collect decodes the search's items into models, which must be a pointer to a slice.
+// NOTE: goamz parses Query and Scan items w/o their bools, so models that could have one are re-read by id,
+// one GetItem each, which costs a read per result.  BatchGetItem doesn't help b/c goamz parses its items the
+// same way; only GetItem's document is decoded w/ bools.  Storing flags as numbers or strings avoids the reads.
+func (ar *ArDynamodb) collect(s *search, models interface{}) error {
+	if _, ok := ar.Self().(goar.Sharder); ok {
+		return errors.New("dynamodb does not query across shards, so sharded models can only be found by id")
+	}
+
+	slice := reflect.ValueOf(models)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("dynamodb results must be a pointer to a slice, not %T", models)
+	}
+
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+	modelType := elemType
+	if elemType.Kind() == reflect.Ptr {
+		modelType = elemType.Elem()
+	}
+	reread := !decodable(modelType)
+
+	t := ar.table()
+	results := reflect.MakeSlice(slice.Type(), 0, 0)
+	err := s.run(&t, func(item map[string]*dynamo.Attribute) error {
+		id := ""
+		if a := item[DB_PRIMARY_KEY_NAME]; a != nil {
+			id = a.Value
+		}
+
+		model := reflect.New(modelType)
+		var err error
+		if reread {
+			err = t.GetDocument(&dynamo.Key{HashKey: id}, model.Interface())
+		} else {
+			err = dynamizer.FromDynamo(document(item), model.Interface())
+		}
+		if err != nil {
+			return err
+		}
+
+		if m, ok := model.Interface().(goar.ActiveRecordInterfacer); ok {
+			m.SetKey(id)
+		}
+		if elemType.Kind() == reflect.Ptr {
+			results = reflect.Append(results, model)
+		} else {
+			results = reflect.Append(results, model.Elem())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	slice.Set(results)
+	return nil
+}
+
+// table is the model's table, keyed by its id
+func (ar *ArDynamodb) table() dynamo.Table {
+	return ar.tableOn(goar.ResolveConnection(ar.Self()))
+}
+
+// tableOn is the model's table on a connection, EX: the shard of an id, w/ that connection's prefix
+func (ar *ArDynamodb) tableOn(connName string, env string) dynamo.Table {
+	primary := dynamo.NewStringAttribute(DB_PRIMARY_KEY_NAME, "")
+	pk := dynamo.PrimaryKey{KeyAttribute: primary}
+
+	return dynamo.Table{Server: server(connName, env), Name: tableName(connName, env, ar.ModelName()), Key: pk}
 }
 
 func (ar *ArDynamodb) GetTableWithPrimaryKey(key interface{}) (dynamo.Table, *dynamo.Key) {
@@ -172,9 +358,7 @@ func (ar *ArDynamodb) GetTableWithPrimaryKey(key interface{}) (dynamo.Table, *dy
 	//         primary := NewStringAttribute("TestHashKey", "")
 	//         secondary := NewNumericAttribute("TestRangeKey", "")
 	//         key := PrimaryKey{primary, secondary}
-	primary := dynamo.NewStringAttribute(DB_PRIMARY_KEY_NAME, "")
-	pk := dynamo.PrimaryKey{KeyAttribute: primary}
-	t := dynamo.Table{Server: ar.Client(), Name: ar.ModelName(), Key: pk}
+	t := ar.table()
 	dynamoKey := &dynamo.Key{HashKey: key.(string)}
 
 	return t, dynamoKey
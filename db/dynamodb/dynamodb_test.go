@@ -1,6 +1,13 @@
 package dynamodb
 
 import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
 	. "github.com/obieq/goar"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -50,21 +57,16 @@ var _ = Describe("Dynamodb", func() {
 		})
 
 		Context("Error Handling", func() {
-			It("should return an error when the Truncate() method is called", func() {
-				auto := DynamodbAutomobile{}.ToActiveRecord()
-				_, err := auto.Truncate()
-				Ω(err).ShouldNot(BeNil())
-			})
-
-			It("should return an error when the All() method is called", func() {
+			It("should return an error when All() isn't given a slice", func() {
 				auto := DynamodbAutomobile{}.ToActiveRecord()
 				err := auto.All(auto, nil)
 				Ω(err).ShouldNot(BeNil())
 			})
 
-			It("should return an error when the Search() method is called", func() {
+			It("should return an error when ordering by anything but a range key", func() {
+				var results []DynamodbAutomobile
 				auto := DynamodbAutomobile{}.ToActiveRecord()
-				err := auto.DbSearch(auto)
+				err := auto.Where(QueryCondition{Key: "id", RelationalOperator: EQ, Value: "id1"}).Order(OrderBy{Key: "year", SortOrder: DESC}).Run(&results)
 				Ω(err).ShouldNot(BeNil())
 			})
 
@@ -194,5 +196,183 @@ var _ = Describe("Dynamodb", func() {
 				Ω(err).To(HaveOccurred())
 			})
 		})
+
+		Context("Querying", func() {
+			ids := func(results []DynamodbAutomobile) []string {
+				ids := []string{}
+				for _, auto := range results {
+					ids = append(ids, auto.ID)
+				}
+				return ids
+			}
+
+			BeforeEach(func() {
+				for _, auto := range []*DynamodbAutomobile{&ModelS, &Sprite, &Panamera, &Evoque} {
+					Ω(auto.Save()).Should(BeTrue())
+				}
+			})
+
+			It("should scan all models for a given type", func() {
+				var results []DynamodbAutomobile
+				err := ar.All(&results, nil)
+				Ω(err).NotTo(HaveOccurred())
+				Ω(ids(results)).Should(ContainElement(ModelS.ID))
+				Ω(ids(results)).Should(ContainElement(Evoque.ID))
+			})
+
+			It("should query by the hash key", func() {
+				var results []*DynamodbAutomobile
+				ar.Where(QueryCondition{Key: "id", RelationalOperator: EQ, Value: Panamera.ID})
+				err := ar.Where(QueryCondition{Key: "year", RelationalOperator: EQ, Value: 2010}).Run(&results)
+
+				Ω(err).NotTo(HaveOccurred())
+				Ω(len(results)).Should(Equal(1))
+				Ω(results[0].ID).Should(Equal(Panamera.ID))
+				Ω(results[0].Model).Should(Equal("panamera"))
+			})
+
+			It("should scan w/ OR and IN conditions", func() {
+				var results []DynamodbAutomobile
+				ar.Where(QueryCondition{Key: "model", RelationalOperator: EQ, Value: "sprite"})
+				err := ar.Where(QueryCondition{LogicalOperator: OR, Key: "id", RelationalOperator: IN, Value: []interface{}{ModelS.ID, Evoque.ID}}).Run(&results)
+
+				Ω(err).NotTo(HaveOccurred())
+				Ω(ids(results)).Should(ConsistOf(ModelS.ID, Sprite.ID, Evoque.ID))
+			})
+
+			It("should truncate the table", func() {
+				deleted, err := ar.Truncate()
+				Ω(err).NotTo(HaveOccurred())
+				Ω(deleted).Should(BeNumerically(">=", 4))
+
+				var results []DynamodbAutomobile
+				Ω(ar.All(&results, nil)).Should(Succeed())
+				Ω(results).Should(BeEmpty())
+			})
+
+			It("should limit the results", func() {
+				var results []DynamodbAutomobile
+				err := ar.Where(QueryCondition{Key: "safety_rating", RelationalOperator: GTE, Value: 1}).Limit(2).Run(&results)
+
+				Ω(err).NotTo(HaveOccurred())
+				Ω(len(results)).Should(Equal(2))
+			})
+
+			It("should limit the number of models scanned by All", func() {
+				var results []DynamodbAutomobile
+				Ω(ar.All(&results, map[string]interface{}{"limit": 3})).Should(Succeed())
+				Ω(len(results)).Should(Equal(3))
+			})
+		})
+	})
+
+	Context("Connections", func() {
+		It("should look up every region, and use the connection's endpoint", func() {
+			Ω(region("eu-central-1", "").DynamoDBEndpoint).Should(Equal("https://dynamodb.eu-central-1.amazonaws.com"))
+			Ω(region("ap-south-1", "").DynamoDBEndpoint).Should(Equal("https://dynamodb.ap-south-1.amazonaws.com"))
+			Ω(region("cn-north-1", "").DynamoDBEndpoint).Should(Equal("https://dynamodb.cn-north-1.amazonaws.com.cn"))
+
+			local := region("us-east-1", "http://localhost:8000/")
+			Ω(local.Name).Should(Equal("us-east-1"))
+			Ω(local.DynamoDBEndpoint).Should(Equal("http://localhost:8000"))
+		})
+
+		It("should prefer the connection's keys, then its profile", func() {
+			auth, err := credentials(&DynamoDBConfig{AccessKey: "key", SecretKey: "secret", SessionToken: "token"})
+			Ω(err).NotTo(HaveOccurred())
+			Ω(auth.AccessKey).Should(Equal("key"))
+			Ω(auth.Token()).Should(Equal("token"))
+			Ω(expired(&auth)).Should(BeFalse())
+
+			file, err := ioutil.TempFile("", "credentials")
+			Ω(err).NotTo(HaveOccurred())
+			defer os.Remove(file.Name())
+			file.WriteString("[dev]\naws_access_key_id = devkey\naws_secret_access_key = devsecret\n")
+			file.Close()
+
+			auth, err = credentials(&DynamoDBConfig{Profile: "dev", CredentialsFile: file.Name()})
+			Ω(err).NotTo(HaveOccurred())
+			Ω(auth.AccessKey).Should(Equal("devkey"))
+			Ω(auth.SecretKey).Should(Equal("devsecret"))
+
+			_, err = credentials(&DynamoDBConfig{Profile: "prod", CredentialsFile: file.Name()})
+			Ω(err).To(HaveOccurred())
+		})
+	})
+
+	Context("Health Check", func() {
+		It("should list the connection's tables", func() {
+			Ω(healthCheck(context.Background(), "aws", "test")).Should(Succeed())
+		})
+	})
+})
+
+type ShardedDynamodbAutomobile struct {
+	DynamodbAutomobile
+}
+
+func (m *ShardedDynamodbAutomobile) ShardMap() ShardMap {
+	return RangeShardMap{{ConnName: "aws"}}
+}
+
+func (m *ShardedDynamodbAutomobile) ShardKey() string {
+	return m.Make
+}
+
+// RangeShardedDynamodbAutomobile puts ids after "m" on the shard2 connection
+type RangeShardedDynamodbAutomobile struct {
+	DynamodbAutomobile
+}
+
+func (m *RangeShardedDynamodbAutomobile) ShardMap() ShardMap {
+	return RangeShardMap{{UpperBound: "m", ConnName: "aws"}, {ConnName: "shard2"}}
+}
+
+func (m *RangeShardedDynamodbAutomobile) ShardKey() string {
+	return m.ID
+}
+
+var _ = Describe("Dynamodb Sharding", func() {
+	It("should find a sharded model on its id's shard", func() {
+		tables := []string{}
+		api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			in := struct{ TableName string }{}
+			json.Unmarshal(body, &in)
+			tables = append(tables, in.TableName)
+			w.Write([]byte(`{"Item": {"id": {"S": "zz-id"}, "make": {"S": "tesla"}, "year": {"N": "2012"}}}`))
+		}))
+		defer api.Close()
+
+		Configuration().DynamoDBs["test_dynamodb_shard2"] = &DynamoDBConfig{ConnectionName: "test_dynamodb_shard2", Region: "us-east-1", Endpoint: api.URL, AccessKey: "key", SecretKey: "secret", TablePrefix: "shard2_"}
+		defer func() {
+			delete(Configuration().DynamoDBs, "test_dynamodb_shard2")
+			clientsMutex.Lock()
+			delete(clients, "shard2_test")
+			clientsMutex.Unlock()
+		}()
+
+		var out RangeShardedDynamodbAutomobile
+		finder := ToAR(&RangeShardedDynamodbAutomobile{}).(*RangeShardedDynamodbAutomobile) // its blank id is on the aws shard
+		Ω(finder.Find("zz-id", &out)).Should(Succeed())
+		Ω(tables).Should(Equal([]string{"shard2_DynamodbAutomobiles"}))
+		Ω(out.ID).Should(Equal("zz-id"))
+		Ω(out.Make).Should(Equal("tesla"))
+	})
+
+	It("should not query a sharded model's shards one at a time", func() {
+		var results []ShardedDynamodbAutomobile
+		auto := ToAR(&ShardedDynamodbAutomobile{}).(*ShardedDynamodbAutomobile)
+		Ω(auto.All(&results, nil)).Should(MatchError(ContainSubstring("across shards")))
+		Ω(auto.Where(QueryCondition{Key: "make", RelationalOperator: EQ, Value: "tesla"}).Run(&results)).Should(MatchError(ContainSubstring("across shards")))
+	})
+})
+
+var _ = Describe("Dynamodb Search Limits", func() {
+	It("should return an error if All's limit is > 1000 or < 1", func() {
+		var results []DynamodbAutomobile
+		auto := DynamodbAutomobile{}.ToActiveRecord()
+		Ω(auto.All(&results, map[string]interface{}{"limit": 1001})).Should(MatchError("limit must be less than 1001"))
+		Ω(auto.All(&results, map[string]interface{}{"limit": 0})).Should(MatchError("limit must be greater than 0"))
 	})
 })
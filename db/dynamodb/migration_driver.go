@@ -0,0 +1,296 @@
+package dynamodb
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	dynamo "github.com/AdRoll/goamz/dynamodb"
+	goar "github.com/obieq/goar"
+	"github.com/obieq/goar/migration"
+	"github.com/obieq/goar/schema"
+)
+
+// interface assertions
+var _ migration.Driver = (*MigrationDriver)(nil)
+var _ schema.Inspector = (*MigrationDriver)(nil)
+
+// MigrationDriver runs goar migrations against a connection's region.
+// Tables are created with the string hash key every ArDynamodb model uses,
+// and provisioned capacity unless BillingMode is OnDemandBilling.
+// The ledger is a table of applied versions, keyed by the version.
+// Every table's name, the ledger's too, is prefixed by TablePrefix, EX: test_DynamodbAutomobiles
+type MigrationDriver struct {
+	Client        *dynamo.Server
+	TablePrefix   string
+	BillingMode   string        // ProvisionedBilling, the default, or OnDemandBilling
+	ReadCapacity  int64         // of the provisioned tables it creates, defaults to 5
+	WriteCapacity int64         // defaults to 5
+	Timeout       time.Duration // how long to wait for a table to become active, or be deleted, defaults to 2 minutes
+}
+
+// NewMigrationDriver uses the connection's server and table prefix, EX: NewMigrationDriver("aws", "prod")
+func NewMigrationDriver(connName string, env string) *MigrationDriver {
+	return &MigrationDriver{Client: server(connName, env), TablePrefix: connection(connName, env).TablePrefix,
+		ReadCapacity: 5, WriteCapacity: 5, Timeout: 2 * time.Minute}
+}
+
+// CreateDb is a no-op b/c dynamodb doesn't have databases
+func (d *MigrationDriver) CreateDb(dbName string) error {
+	return nil
+}
+
+// DropDb is a no-op b/c dynamodb doesn't have databases
+func (d *MigrationDriver) DropDb(dbName string) error {
+	return nil
+}
+
+// CreateTable creates the table and waits until it's active
+func (d *MigrationDriver) CreateTable(tableName string) error {
+	return d.createTable(dynamo.TableDescriptionT{
+		TableName:            d.TablePrefix + tableName,
+		AttributeDefinitions: []dynamo.AttributeDefinitionT{{Name: DB_PRIMARY_KEY_NAME, Type: dynamo.TYPE_STRING}},
+		KeySchema:            []dynamo.KeySchemaT{{AttributeName: DB_PRIMARY_KEY_NAME, KeyType: "HASH"}},
+	})
+}
+
+// CreateTableFor creates the model's table with the key schema declared by its struct.
+// Each declared index becomes a global secondary index, keyed by its first (hash)
+// and second (range) attributes, EX: `schema:"index=idx_make_year"`.
+// Items expire by the ttl attribute, when there is one, EX: `schema:"ttl"`
+func (d *MigrationDriver) CreateTableFor(ari goar.ActiveRecordInterfacer) error {
+	t := schema.Of(ari, schema.DynamoDB)
+	description := dynamo.TableDescriptionT{TableName: d.TablePrefix + t.Name}
+
+	attributes := map[string]bool{}
+	define := func(name string) {
+		if c := t.Column(name); c != nil && !attributes[name] {
+			attributes[name] = true
+			description.AttributeDefinitions = append(description.AttributeDefinitions, dynamo.AttributeDefinitionT{Name: name, Type: c.Type})
+		}
+	}
+
+	for _, keyType := range []string{"HASH", "RANGE"} { // the hash key must be first
+		for _, c := range t.Columns {
+			if c.KeyType == keyType {
+				define(c.Name)
+				description.KeySchema = append(description.KeySchema, dynamo.KeySchemaT{AttributeName: c.Name, KeyType: keyType})
+			}
+		}
+	}
+
+	for _, index := range t.Indexes {
+		if len(index.Columns) > 2 {
+			return errors.New("dynamodb indexes have at most a hash and a range key: " + index.Name)
+		} else if index.Unique {
+			return errors.New("dynamodb does not enforce unique indexes: " + index.Name)
+		}
+
+		gsi := dynamo.GlobalSecondaryIndexT{IndexName: index.Name, Projection: dynamo.ProjectionT{ProjectionType: "ALL"}}
+		for i, name := range index.Columns {
+			define(name)
+			gsi.KeySchema = append(gsi.KeySchema, dynamo.KeySchemaT{AttributeName: name, KeyType: []string{"HASH", "RANGE"}[i]})
+		}
+		description.GlobalSecondaryIndexes = append(description.GlobalSecondaryIndexes, gsi)
+	}
+
+	if err := d.createTable(description); err != nil {
+		return err
+	}
+
+	for _, c := range t.Columns {
+		if c.TTL {
+			return d.EnableTTL(t.Name, c.Name)
+		}
+	}
+
+	return nil
+}
+
+// createTable sets the table's billing mode, and its and its indexes' throughput, and waits until the table is active
+func (d *MigrationDriver) createTable(description dynamo.TableDescriptionT) error {
+	in := newCreateTableInput(description, d.BillingMode, &throughput{
+		ReadCapacityUnits:  capacity(d.ReadCapacity),
+		WriteCapacityUnits: capacity(d.WriteCapacity),
+	})
+	if err := request(d.Client, "CreateTable", in, nil); err != nil {
+		return err
+	}
+
+	return d.waitUntilActive(description.TableName)
+}
+
+// DropTable deletes the table and waits until it's gone, so it can be created again
+func (d *MigrationDriver) DropTable(tableName string) error {
+	if _, err := d.Client.DeleteTable(dynamo.TableDescriptionT{TableName: d.TablePrefix + tableName}); err != nil {
+		return err
+	}
+
+	return d.wait(d.TablePrefix+tableName, func(description *dynamo.TableDescriptionT, err error) (bool, error) {
+		if isResourceNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+}
+
+// DescribeTable returns the table's keys, indexes, throughput, status and size, EX: ACTIVE and ItemCount
+func (d *MigrationDriver) DescribeTable(tableName string) (*dynamo.TableDescriptionT, error) {
+	return d.Client.DescribeTable(d.TablePrefix + tableName)
+}
+
+// WaitUntilActive waits until the table has been created, or updated
+func (d *MigrationDriver) WaitUntilActive(tableName string) error {
+	return d.waitUntilActive(d.TablePrefix + tableName)
+}
+
+// EnableTTL expires the table's items once the epoch seconds in the attribute have passed, EX: expires_at.
+// NOTE: dynamodb allows one ttl change per table an hour.
+func (d *MigrationDriver) EnableTTL(tableName string, attribute string) error {
+	return updateTimeToLive(d.Client, d.TablePrefix+tableName, attribute, true)
+}
+
+// DisableTTL stops expiring the table's items by the attribute
+func (d *MigrationDriver) DisableTTL(tableName string, attribute string) error {
+	return updateTimeToLive(d.Client, d.TablePrefix+tableName, attribute, false)
+}
+
+// TTL returns the attribute the table's items expire by, and whether it's enabled, or enabling
+func (d *MigrationDriver) TTL(tableName string) (string, bool, error) {
+	ttl, err := describeTimeToLive(d.Client, d.TablePrefix+tableName)
+	enabled := ttl.TimeToLiveStatus == "ENABLED" || ttl.TimeToLiveStatus == "ENABLING"
+
+	return ttl.AttributeName, enabled, err
+}
+
+// AddIndex isn't supported b/c the sdk can't update a table's secondary indexes
+func (d *MigrationDriver) AddIndex(tableName string, fields []string, opts map[string]interface{}) error {
+	return errors.New("dynamodb secondary indexes must be declared when the table is created")
+}
+
+func (d *MigrationDriver) EnsureLedger() error {
+	_, err := d.Client.DescribeTable(d.TablePrefix + migration.LedgerName)
+	if isResourceNotFound(err) {
+		return d.CreateTable(migration.LedgerName)
+	}
+
+	return err
+}
+
+func (d *MigrationDriver) AppliedVersions() ([]int64, error) {
+	versions := []int64{}
+	err := d.ledger().ScanCallbackIterator(nil, func(item map[string]*dynamo.Attribute) error {
+		version, err := strconv.ParseInt(item[DB_PRIMARY_KEY_NAME].Value, 10, 64)
+		versions = append(versions, version)
+		return err
+	})
+
+	return versions, err
+}
+
+func (d *MigrationDriver) RecordVersion(version int64) error {
+	appliedAt := dynamo.NewStringAttribute("applied_at", time.Now().UTC().Format(time.RFC3339))
+	_, err := d.ledger().PutItem(fmt.Sprint(version), "", []dynamo.Attribute{*appliedAt})
+	return err
+}
+
+func (d *MigrationDriver) RemoveVersion(version int64) error {
+	_, err := d.ledger().DeleteItem(&dynamo.Key{HashKey: fmt.Sprint(version)})
+	return err
+}
+
+// Ping lists the region's tables, which also checks the credentials
+func (d *MigrationDriver) Ping() error {
+	_, err := d.Client.ListTables()
+	return err
+}
+
+func (d *MigrationDriver) Dialect() schema.Dialect {
+	return schema.DynamoDB
+}
+
+// InspectTable reads the table's key attributes and secondary indexes via DescribeTable.
+// The table is named w/o the prefix, as the model names it.
+func (d *MigrationDriver) InspectTable(name string) (*schema.Table, error) {
+	description, err := d.Client.DescribeTable(d.TablePrefix + name)
+	if isResourceNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	t := describedTable(description)
+	t.Name = name
+
+	return t, nil
+}
+
+// describedTable maps a table description onto the schema the model's struct declares.
+// NOTE: only key attributes are defined, so the index keys are the only other columns.
+func describedTable(description *dynamo.TableDescriptionT) *schema.Table {
+	t := &schema.Table{Name: description.TableName, Dialect: schema.DynamoDB, Columns: []schema.Column{}, Indexes: []schema.Index{}}
+
+	keyTypes := map[string]string{}
+	for _, k := range description.KeySchema {
+		keyTypes[k.AttributeName] = k.KeyType
+	}
+	for _, a := range description.AttributeDefinitions {
+		t.Columns = append(t.Columns, schema.Column{Name: a.Name, Type: a.Type, KeyType: keyTypes[a.Name], PrimaryKey: keyTypes[a.Name] == "HASH"})
+	}
+
+	addIndex := func(name string, keys []dynamo.KeySchemaT) {
+		index := schema.Index{Name: name}
+		for _, k := range keys {
+			index.Columns = append(index.Columns, k.AttributeName)
+		}
+		t.Indexes = append(t.Indexes, index)
+	}
+	for _, gsi := range description.GlobalSecondaryIndexes {
+		addIndex(gsi.IndexName, gsi.KeySchema)
+	}
+	for _, lsi := range description.LocalSecondaryIndexes {
+		addIndex(lsi.IndexName, lsi.KeySchema)
+	}
+
+	return t
+}
+
+func (d *MigrationDriver) ledger() *dynamo.Table {
+	pk := dynamo.PrimaryKey{KeyAttribute: dynamo.NewStringAttribute(DB_PRIMARY_KEY_NAME, "")}
+	return d.Client.NewTable(d.TablePrefix+migration.LedgerName, pk)
+}
+
+func (d *MigrationDriver) waitUntilActive(tableName string) error {
+	return d.wait(tableName, func(description *dynamo.TableDescriptionT, err error) (bool, error) {
+		return err == nil && description.TableStatus == "ACTIVE", err
+	})
+}
+
+// wait describes the table every second until done, or the timeout
+func (d *MigrationDriver) wait(tableName string, done func(*dynamo.TableDescriptionT, error) (bool, error)) error {
+	timeout := d.Timeout
+	if timeout == 0 {
+		timeout = 2 * time.Minute
+	}
+
+	for deadline := time.Now().Add(timeout); time.Now().Before(deadline); time.Sleep(time.Second) {
+		if ok, err := done(d.Client.DescribeTable(tableName)); ok || err != nil {
+			return err
+		}
+	}
+
+	return errors.New("dynamodb table did not finish changing: " + tableName)
+}
+
+func capacity(units int64) int64 {
+	if units <= 0 {
+		return 5
+	}
+	return units
+}
+
+func isResourceNotFound(err error) bool {
+	e, ok := err.(*dynamo.Error)
+	return ok && e.Code == "ResourceNotFoundException"
+}
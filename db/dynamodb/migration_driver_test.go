@@ -0,0 +1,55 @@
+package dynamodb
+
+import (
+	dynamo "github.com/AdRoll/goamz/dynamodb"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dynamodb Migration Driver", func() {
+
+	var driver *MigrationDriver
+
+	BeforeEach(func() {
+		driver = NewMigrationDriver("aws", "test")
+	})
+
+	It("should record and remove versions", func() {
+		Ω(driver.EnsureLedger()).Should(Succeed())
+		Ω(driver.RecordVersion(20151014093000)).Should(Succeed())
+		Ω(driver.AppliedVersions()).Should(ContainElement(int64(20151014093000)))
+
+		Ω(driver.RemoveVersion(20151014093000)).Should(Succeed())
+		Ω(driver.AppliedVersions()).ShouldNot(ContainElement(int64(20151014093000)))
+	})
+
+	It("should not add indexes to an existing table", func() {
+		Ω(driver.AddIndex("DynamodbAutomobiles", []string{"make"}, nil)).ShouldNot(Succeed())
+	})
+
+	It("should recognize a missing table", func() {
+		Ω(isResourceNotFound(&dynamo.Error{Code: "ResourceNotFoundException"})).Should(BeTrue())
+		Ω(isResourceNotFound(&dynamo.Error{Code: "ValidationException"})).Should(BeFalse())
+	})
+
+	It("should inspect a table's keys and indexes", func() {
+		t := describedTable(&dynamo.TableDescriptionT{
+			TableName:            "vehicles",
+			AttributeDefinitions: []dynamo.AttributeDefinitionT{{Name: "dealer", Type: "S"}, {Name: "vin", Type: "S"}, {Name: "year", Type: "N"}},
+			KeySchema:            []dynamo.KeySchemaT{{AttributeName: "dealer", KeyType: "HASH"}, {AttributeName: "vin", KeyType: "RANGE"}},
+			GlobalSecondaryIndexes: []dynamo.GlobalSecondaryIndexT{
+				{IndexName: "idx_year", KeySchema: []dynamo.KeySchemaT{{AttributeName: "year", KeyType: "HASH"}}},
+			},
+		})
+
+		Ω(t.PrimaryKey()).Should(Equal([]string{"dealer"}))
+		Ω(t.Column("vin").KeyType).Should(Equal("RANGE"))
+		Ω(t.Column("year").KeyType).Should(BeEmpty())
+		Ω(t.Indexes).Should(HaveLen(1))
+		Ω(t.Indexes[0].Columns).Should(Equal([]string{"year"}))
+	})
+
+	It("should ping the connection", func() {
+		Ω(driver.Ping()).Should(Succeed())
+	})
+})
@@ -0,0 +1,401 @@
+package dynamodb
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	dynamo "github.com/AdRoll/goamz/dynamodb"
+	"github.com/AdRoll/goamz/dynamodb/dynamizer"
+	goar "github.com/obieq/goar"
+	"github.com/obieq/goar/schema"
+)
+
+// search is a DbSearch compiled into a Query against the table or one of its indexes,
+// which needs an EQ condition on the hash key, or else a Scan
+type search struct {
+	index         string                       // blank for the table
+	keyConditions []dynamo.AttributeComparison // the hash key, and maybe the range key, conditions of a Query
+	queryFilter   []dynamo.AttributeComparison // a Query's other conditions
+	filter        *dynamo.Expression           // a Scan's conditions
+	descending    bool                         // by the range key
+	limit         int
+}
+
+// keySchema is the hash and range keys of the table, or an index
+type keySchema struct {
+	index    string
+	hash     string
+	rangeKey string
+}
+
+// keySchemas returns the table's keys, then its declared indexes', EX: `schema:"index=idx_make_year"`
+func keySchemas(t *schema.Table) []keySchema {
+	table := keySchema{}
+	for _, c := range t.Columns {
+		switch c.KeyType {
+		case "HASH":
+			table.hash = c.Name
+		case "RANGE":
+			table.rangeKey = c.Name
+		}
+	}
+
+	keys := []keySchema{table}
+	for _, index := range t.Indexes {
+		k := keySchema{index: index.Name, hash: index.Columns[0]}
+		if len(index.Columns) > 1 {
+			k.rangeKey = index.Columns[1]
+		}
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+// compileSearch picks the key schema with an EQ condition on its hash key, preferring one with a range
+// key condition too.  Ordering needs a Query by the order's range key, b/c Scans are unordered.
+// NOTE: OR conditions can't be Queried, so they're Scanned.
+func compileSearch(t *schema.Table, q *goar.Query) (*search, error) {
+	if len(q.Plucks) > 0 || len(q.Aggregations) > 0 || q.Distinct {
+		return nil, errors.New("dynamodb doesn't support plucks, aggregations or distinct")
+	} else if len(q.OrderBys) > 1 {
+		return nil, errors.New("dynamodb can only order by one range key")
+	}
+
+	limit, err := goar.QueryLimit(q)
+	if err != nil {
+		return nil, err
+	}
+	s := &search{limit: limit}
+
+	anded := true
+	for i, where := range q.WhereConditions {
+		anded = anded && (i == 0 || where.LogicalOperator != goar.OR)
+	}
+
+	best, bestHash, bestRange := keySchema{}, -1, -1
+	for _, k := range keySchemas(t) {
+		if !anded || len(q.OrderBys) == 1 && (k.rangeKey == "" || q.OrderBys[0].Key != k.rangeKey) {
+			continue
+		}
+
+		hash, rangeKey := -1, -1
+		for i, where := range q.WhereConditions {
+			if where.Key == k.hash && where.RelationalOperator == goar.EQ && hash < 0 {
+				hash = i
+			} else if where.Key == k.rangeKey && k.rangeKey != "" && isKeyOperator(where.RelationalOperator) && rangeKey < 0 {
+				rangeKey = i
+			}
+		}
+
+		if hash >= 0 && (bestHash < 0 || rangeKey >= 0 && bestRange < 0) {
+			best, bestHash, bestRange = k, hash, rangeKey
+		}
+	}
+
+	if bestHash < 0 {
+		if len(q.OrderBys) == 1 {
+			return nil, fmt.Errorf("dynamodb can only order by the range key of the table or an index queried by its hash key, not %s", q.OrderBys[0].Key)
+		}
+
+		s.filter, err = filterExpression(q.WhereConditions)
+		return s, err
+	}
+
+	s.index = best.index
+	s.descending = len(q.OrderBys) == 1 && q.OrderBys[0].SortOrder == goar.DESC
+	for i, where := range q.WhereConditions {
+		c, err := comparison(where)
+		if err != nil {
+			return nil, err
+		}
+
+		if i == bestHash || i == bestRange {
+			s.keyConditions = append(s.keyConditions, c)
+		} else {
+			s.queryFilter = append(s.queryFilter, c)
+		}
+	}
+
+	return s, nil
+}
+
+// isKeyOperator checks whether a range key condition can be a key condition
+func isKeyOperator(op goar.EnumRelationalOperators) bool {
+	switch op {
+	case goar.EQ, goar.LT, goar.LTE, goar.GT, goar.GTE:
+		return true
+	}
+
+	return false
+}
+
+// query builds the search's Query or Scan request.  The limit is only sent when nothing is filtered,
+// b/c dynamodb limits the items it reads rather than the ones that match.
+func (s *search) query(t *dynamo.Table) *dynamo.UntypedQuery {
+	q := dynamo.NewQuery(t)
+	if s.limit > 0 && s.queryFilter == nil && s.filter == nil {
+		q.AddLimit(int64(s.limit))
+	}
+
+	if s.keyConditions == nil {
+		if s.filter != nil {
+			q.AddFilterExpression(s.filter)
+		}
+		return q
+	}
+
+	q.AddKeyConditions(s.keyConditions)
+	if s.queryFilter != nil {
+		q.AddQueryFilter(s.queryFilter)
+	}
+	if s.index != "" {
+		q.AddIndex(s.index)
+	}
+	if s.descending {
+		q.AddScanIndexForward(false)
+	}
+
+	return q
+}
+
+var errLimitReached = errors.New("limit reached")
+
+// run pages through the search's items until they run out, or the limit is reached
+func (s *search) run(t *dynamo.Table, cb func(item map[string]*dynamo.Attribute) error) error {
+	count := 0
+	limited := func(item map[string]*dynamo.Attribute) error {
+		if err := cb(item); err != nil {
+			return err
+		}
+
+		if count++; s.limit > 0 && count >= s.limit {
+			return errLimitReached
+		}
+		return nil
+	}
+
+	var err error
+	if s.keyConditions != nil {
+		err = t.QueryTableCallbackIterator(s.query(t), limited)
+	} else {
+		err = t.FetchResultCallbackIterator(s.query(t), limited)
+	}
+
+	if err == errLimitReached {
+		return nil
+	}
+	return err
+}
+
+var comparisonOperators = map[goar.EnumRelationalOperators]string{
+	goar.EQ:  dynamo.COMPARISON_EQUAL,
+	goar.NE:  dynamo.COMPARISON_NOT_EQUAL,
+	goar.LT:  dynamo.COMPARISON_LESS_THAN,
+	goar.LTE: dynamo.COMPARISON_LESS_THAN_OR_EQUAL,
+	goar.GT:  dynamo.COMPARISON_GREATER_THAN,
+	goar.GTE: dynamo.COMPARISON_GREATER_THAN_OR_EQUAL,
+	goar.IN:  dynamo.COMPARISON_IN,
+}
+
+// comparison converts a condition into a key condition, or a Query filter
+func comparison(where goar.QueryCondition) (dynamo.AttributeComparison, error) {
+	c := dynamo.AttributeComparison{AttributeName: where.Key, ComparisonOperator: comparisonOperators[where.RelationalOperator]}
+	if c.ComparisonOperator == "" {
+		return c, fmt.Errorf("invalid comparison operator: %v", where.RelationalOperator)
+	}
+
+	values, err := conditionValues(where)
+	for _, v := range values {
+		a, err := attribute("", v)
+		if err != nil {
+			return c, err
+		}
+		c.AttributeValueList = append(c.AttributeValueList, a)
+	}
+
+	return c, err
+}
+
+var expressionOperators = map[goar.EnumRelationalOperators]string{
+	goar.EQ:  "=",
+	goar.NE:  "<>",
+	goar.LT:  "<",
+	goar.LTE: "<=",
+	goar.GT:  ">",
+	goar.GTE: ">=",
+}
+
+// filterExpression converts the conditions into a Scan filter, EX: (#n0 = :v0) OR #n1 IN (:v1, :v2).
+// Like rethinkdb's, they're combined left to right.  Names are placeholders b/c many, EX: year, are reserved words.
+func filterExpression(conditions []goar.QueryCondition) (*dynamo.Expression, error) {
+	if len(conditions) == 0 {
+		return nil, nil
+	}
+
+	e := &dynamo.Expression{AttributeNames: map[string]string{}}
+	names := map[string]string{}
+	for i, where := range conditions {
+		name, ok := names[where.Key]
+		if !ok {
+			name = "#n" + strconv.Itoa(len(names))
+			names[where.Key], e.AttributeNames[name] = name, where.Key
+		}
+
+		values, err := conditionValues(where)
+		if err != nil {
+			return nil, err
+		}
+
+		placeholders := []string{}
+		for _, v := range values {
+			a, err := attribute(":v"+strconv.Itoa(len(e.AttributeValues)), v)
+			if err != nil {
+				return nil, err
+			}
+			e.AttributeValues = append(e.AttributeValues, a)
+			placeholders = append(placeholders, a.Name)
+		}
+
+		var condition string
+		if where.RelationalOperator == goar.IN {
+			condition = name + " IN (" + strings.Join(placeholders, ", ") + ")"
+		} else if op, ok := expressionOperators[where.RelationalOperator]; ok {
+			condition = name + " " + op + " " + placeholders[0]
+		} else {
+			return nil, fmt.Errorf("invalid comparison operator: %v", where.RelationalOperator)
+		}
+
+		switch {
+		case i == 0:
+			e.Text = condition
+		case where.LogicalOperator == goar.OR:
+			e.Text = "(" + e.Text + ") OR " + condition
+		default:
+			e.Text = "(" + e.Text + ") AND " + condition
+		}
+	}
+
+	return e, nil
+}
+
+// conditionValues returns IN's values, or the condition's value
+func conditionValues(where goar.QueryCondition) ([]interface{}, error) {
+	if where.RelationalOperator != goar.IN {
+		return []interface{}{where.Value}, nil
+	}
+
+	v := reflect.ValueOf(where.Value)
+	if v.Kind() != reflect.Slice || v.Len() == 0 {
+		return nil, fmt.Errorf("dynamodb IN conditions need a slice of values: %s", where.Key)
+	}
+
+	values := make([]interface{}, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+	}
+
+	return values, nil
+}
+
+// attribute converts a condition value the way PutDocument stores it, so strings and times
+// are strings and every other number is a number.
+// NOTE: goamz can't send bools in a condition, so they can't be compared.
+func attribute(name string, value interface{}) (dynamo.Attribute, error) {
+	switch v := value.(type) {
+	case string:
+		return *dynamo.NewStringAttribute(name, v), nil
+	case time.Time:
+		return *dynamo.NewStringAttribute(name, v.Format(time.RFC3339Nano)), nil
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return *dynamo.NewNumericAttribute(name, strconv.FormatInt(v.Int(), 10)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return *dynamo.NewNumericAttribute(name, strconv.FormatUint(v.Uint(), 10)), nil
+	case reflect.Float32, reflect.Float64:
+		return *dynamo.NewNumericAttribute(name, strconv.FormatFloat(v.Float(), 'f', -1, 64)), nil
+	}
+
+	return dynamo.Attribute{}, fmt.Errorf("dynamodb can't compare %T values", value)
+}
+
+// document converts a Query or Scan item into the form GetDocument decodes
+func document(item map[string]*dynamo.Attribute) dynamizer.DynamoItem {
+	d := dynamizer.DynamoItem{}
+	for name, a := range item {
+		d[name] = dynamoAttribute(a)
+	}
+
+	return d
+}
+
+// dynamoAttribute converts an attribute, or nil which goamz returns for the ones it can't parse
+func dynamoAttribute(a *dynamo.Attribute) *dynamizer.DynamoAttribute {
+	d := &dynamizer.DynamoAttribute{}
+	if a == nil {
+		d.NULL = true
+		return d
+	}
+
+	switch a.Type {
+	case dynamo.TYPE_STRING:
+		s := a.Value
+		d.S = &s
+	case dynamo.TYPE_NUMBER:
+		d.N = a.Value
+	case dynamo.TYPE_MAP:
+		d.M = map[string]*dynamizer.DynamoAttribute{}
+		for name, v := range a.MapValues {
+			d.M[name] = dynamoAttribute(v)
+		}
+	case dynamo.TYPE_LIST:
+		d.L = []*dynamizer.DynamoAttribute{}
+		for _, v := range a.ListValues {
+			d.L = append(d.L, dynamoAttribute(v))
+		}
+	default: // binary and sets, which PutDocument never writes
+		d.NULL = true
+	}
+
+	return d
+}
+
+// decodable checks whether a model's items can be decoded from Query and Scan results, which goamz
+// parses w/o their bools.  Otherwise each item is read again via GetDocument.
+// NOTE: an interface might hold a bool, so it isn't decodable.
+func decodable(t reflect.Type) bool {
+	return isDecodable(t, map[reflect.Type]bool{})
+}
+
+func isDecodable(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return true
+	}
+	seen[t] = true
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.Interface:
+		return false
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return isDecodable(t.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" && !f.Anonymous || f.Tag.Get("json") == "-" || f.Type == reflect.TypeOf(goar.ActiveRecord{}) {
+				continue
+			}
+			if !isDecodable(f.Type, seen) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
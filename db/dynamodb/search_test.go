@@ -0,0 +1,181 @@
+package dynamodb
+
+import (
+	"reflect"
+	"time"
+
+	dynamo "github.com/AdRoll/goamz/dynamodb"
+	"github.com/AdRoll/goamz/dynamodb/dynamizer"
+	. "github.com/obieq/goar"
+	"github.com/obieq/goar/schema"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dynamodb Search", func() {
+	var (
+		vehicles *schema.Table
+		table    *dynamo.Table
+	)
+
+	BeforeEach(func() {
+		vehicles = &schema.Table{
+			Name:    "vehicles",
+			Dialect: schema.DynamoDB,
+			Columns: []schema.Column{{Name: "id", KeyType: "HASH"}, {Name: "make"}, {Name: "year"}},
+			Indexes: []schema.Index{{Name: "idx_make", Columns: []string{"make"}}, {Name: "idx_make_year", Columns: []string{"make", "year"}}},
+		}
+		table = &dynamo.Table{Name: "vehicles"}
+	})
+
+	where := func(conditions ...QueryCondition) *Query {
+		return &Query{WhereConditions: conditions}
+	}
+
+	Context("Queries", func() {
+		It("should query the table by its hash key", func() {
+			s, err := compileSearch(vehicles, where(QueryCondition{Key: "id", RelationalOperator: EQ, Value: "id1"}))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(s.index).Should(BeEmpty())
+			Ω(s.keyConditions).Should(HaveLen(1))
+			Ω(s.queryFilter).Should(BeNil())
+			Ω(s.query(table).String()).Should(ContainSubstring(`"KeyConditions":{"id":{"AttributeValueList":[{"S":"id1"}],"ComparisonOperator":"EQ"}}`))
+		})
+
+		It("should prefer an index w/ a range key condition, and filter the other conditions", func() {
+			s, err := compileSearch(vehicles, where(
+				QueryCondition{Key: "model", RelationalOperator: NE, Value: "sprite"},
+				QueryCondition{Key: "year", RelationalOperator: GTE, Value: 1960},
+				QueryCondition{Key: "make", RelationalOperator: EQ, Value: "austin healey"},
+			))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(s.index).Should(Equal("idx_make_year"))
+			Ω(s.keyConditions).Should(HaveLen(2))
+			Ω(s.queryFilter).Should(HaveLen(1))
+
+			q := s.query(table).String()
+			Ω(q).Should(ContainSubstring(`"IndexName":"idx_make_year"`))
+			Ω(q).Should(ContainSubstring(`"year":{"AttributeValueList":[{"N":"1960"}],"ComparisonOperator":"GE"}`))
+			Ω(q).Should(ContainSubstring(`"QueryFilter":{"model":{"AttributeValueList":[{"S":"sprite"}],"ComparisonOperator":"NE"}}`))
+		})
+
+		It("should order by the range key", func() {
+			q := where(QueryCondition{Key: "make", RelationalOperator: EQ, Value: "porsche"})
+			q.OrderBys = []OrderBy{{Key: "year", SortOrder: DESC}}
+
+			s, err := compileSearch(vehicles, q)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(s.index).Should(Equal("idx_make_year"))
+			Ω(s.descending).Should(BeTrue())
+			Ω(s.query(table).String()).Should(ContainSubstring(`"ScanIndexForward":"false"`))
+		})
+
+		It("should only send the limit when nothing is filtered", func() {
+			q := where(QueryCondition{Key: "make", RelationalOperator: EQ, Value: "porsche"})
+			q.Limit = "2"
+			s, err := compileSearch(vehicles, q)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(s.limit).Should(Equal(2))
+			Ω(s.query(table).String()).Should(ContainSubstring(`"Limit":2`))
+
+			q.WhereConditions = append(q.WhereConditions, QueryCondition{Key: "model", RelationalOperator: EQ, Value: "panamera"})
+			s, err = compileSearch(vehicles, q)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(s.query(table).String()).ShouldNot(ContainSubstring(`"Limit"`))
+		})
+	})
+
+	Context("Scans", func() {
+		It("should scan w/ a filter expression when no hash key is compared", func() {
+			s, err := compileSearch(vehicles, where(
+				QueryCondition{Key: "year", RelationalOperator: LT, Value: 2000},
+				QueryCondition{LogicalOperator: OR, Key: "make", RelationalOperator: IN, Value: []interface{}{"tesla", "bugatti"}},
+				QueryCondition{LogicalOperator: AND, Key: "year", RelationalOperator: NE, Value: 2009},
+			))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(s.keyConditions).Should(BeNil())
+			Ω(s.filter.Text).Should(Equal("((#n0 < :v0) OR #n1 IN (:v1, :v2)) AND #n0 <> :v3"))
+			Ω(s.filter.AttributeNames).Should(Equal(map[string]string{"#n0": "year", "#n1": "make"}))
+			Ω(s.filter.AttributeValues).Should(HaveLen(4))
+			Ω(s.query(table).String()).Should(ContainSubstring(`"FilterExpression":"((#n0 \u003c :v0) OR #n1 IN (:v1, :v2)) AND #n0 \u003c\u003e :v3"`))
+		})
+
+		It("should scan OR conditions, even on the hash key", func() {
+			s, err := compileSearch(vehicles, where(
+				QueryCondition{Key: "id", RelationalOperator: EQ, Value: "id1"},
+				QueryCondition{LogicalOperator: OR, Key: "id", RelationalOperator: EQ, Value: "id2"},
+			))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(s.keyConditions).Should(BeNil())
+			Ω(s.filter.Text).Should(Equal("(#n0 = :v0) OR #n0 = :v1"))
+		})
+
+		It("should scan everything w/o conditions", func() {
+			s, err := compileSearch(vehicles, where())
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(s.filter).Should(BeNil())
+			Ω(s.query(table).String()).Should(Equal(`{"TableName":"vehicles"}`))
+		})
+	})
+
+	Context("Errors", func() {
+		It("should not order a scan, or by anything but the range key", func() {
+			q := where(QueryCondition{Key: "year", RelationalOperator: GT, Value: 2000})
+			q.OrderBys = []OrderBy{{Key: "year", SortOrder: ASC}}
+			_, err := compileSearch(vehicles, q)
+			Ω(err).Should(HaveOccurred())
+
+			q = where(QueryCondition{Key: "make", RelationalOperator: EQ, Value: "porsche"})
+			q.OrderBys = []OrderBy{{Key: "model", SortOrder: ASC}}
+			_, err = compileSearch(vehicles, q)
+			Ω(err).Should(HaveOccurred())
+		})
+
+		It("should not pluck, aggregate or distinct", func() {
+			_, err := compileSearch(vehicles, &Query{Plucks: []interface{}{"make"}})
+			Ω(err).Should(HaveOccurred())
+			_, err = compileSearch(vehicles, &Query{Distinct: true})
+			Ω(err).Should(HaveOccurred())
+		})
+
+		It("should not compare empty INs or bools", func() {
+			_, err := compileSearch(vehicles, where(QueryCondition{Key: "make", RelationalOperator: IN, Value: []interface{}{}}))
+			Ω(err).Should(HaveOccurred())
+			_, err = compileSearch(vehicles, where(QueryCondition{Key: "sold", RelationalOperator: EQ, Value: true}))
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("Decoding", func() {
+		It("should convert values the way they're stored", func() {
+			a, _ := attribute(":v0", uint8(3))
+			Ω(a).Should(Equal(*dynamo.NewNumericAttribute(":v0", "3")))
+			a, _ = attribute(":v0", 52.62)
+			Ω(a).Should(Equal(*dynamo.NewNumericAttribute(":v0", "52.62")))
+
+			at := time.Date(2015, 10, 14, 9, 30, 0, 0, time.UTC)
+			a, _ = attribute(":v0", at)
+			Ω(a).Should(Equal(*dynamo.NewStringAttribute(":v0", "2015-10-14T09:30:00Z")))
+		})
+
+		It("should decode an item", func() {
+			item := map[string]*dynamo.Attribute{
+				"id":   dynamo.NewStringAttribute("id", "id1"),
+				"make": dynamo.NewStringAttribute("make", "tesla"),
+				"year": dynamo.NewNumericAttribute("year", "2009"),
+			}
+
+			var v Vehicle2
+			Ω(dynamoAttribute(nil).NULL).Should(BeTrue())
+			Ω(document(item)).Should(HaveLen(3))
+			Ω(dynamizer.FromDynamo(document(item), &v)).Should(Succeed())
+			Ω(v).Should(Equal(Vehicle2{Make: "tesla", Year: 2009}))
+		})
+
+		It("should re-read models that could have a bool", func() {
+			Ω(decodable(reflect.TypeOf(Automobile2{}))).Should(BeTrue())
+			Ω(decodable(reflect.TypeOf(DynamodbAutomobile{}))).Should(BeFalse()) // Junk
+			Ω(decodable(reflect.TypeOf(struct{ Options map[string][]bool }{}))).Should(BeFalse())
+		})
+	})
+})
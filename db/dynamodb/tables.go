@@ -0,0 +1,240 @@
+package dynamodb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	aws "github.com/AdRoll/goamz/aws"
+	dynamo "github.com/AdRoll/goamz/dynamodb"
+)
+
+// the billing modes of the tables MigrationDriver creates
+const (
+	ProvisionedBilling = "PROVISIONED"
+	OnDemandBilling    = "PAY_PER_REQUEST"
+)
+
+// request calls the dynamodb api for what goamz can't do, EX: UpdateTimeToLive, signed and retried
+// the way goamz does its own.  Errors are a *dynamo.Error, like goamz's.
+func request(s *dynamo.Server, action string, in interface{}, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	target := "DynamoDB_20120810." + action
+	for retries := 0; ; retries++ {
+		req, err := http.NewRequest("POST", s.Region.DynamoDBEndpoint+"/", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set("Content-Type", "application/x-amz-json-1.0")
+		req.Header.Set("X-Amz-Date", time.Now().UTC().Format(aws.ISO8601BasicFormat))
+		req.Header.Set("X-Amz-Target", target)
+		if token := s.Auth.Token(); token != "" {
+			req.Header.Set("X-Amz-Security-Token", token)
+		}
+		aws.NewV4Signer(s.Auth, "dynamodb", s.Region).Sign(req)
+
+		var data []byte
+		resp, err := http.DefaultClient.Do(req)
+		if err == nil {
+			data, err = ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+		if err == nil && resp.StatusCode != http.StatusOK {
+			err = responseError(resp, data)
+		}
+
+		if err == nil {
+			if out == nil {
+				return nil
+			}
+			return json.Unmarshal(data, out)
+		} else if !s.RetryPolicy.ShouldRetry(target, resp, err, retries) {
+			return err
+		}
+		time.Sleep(s.RetryPolicy.Delay(target, resp, err, retries))
+	}
+}
+
+// responseError reads the error's code, EX: com.amazonaws.dynamodb.v20120810#ResourceNotFoundException
+func responseError(resp *http.Response, data []byte) error {
+	body := struct {
+		Type    string `json:"__type"`
+		Message string // NOTE: also matches message, which some errors use
+	}{}
+	json.Unmarshal(data, &body)
+
+	code := body.Type
+	if i := strings.Index(code, "#"); i >= 0 {
+		code = code[i+1:]
+	}
+
+	return &dynamo.Error{StatusCode: resp.StatusCode, Status: resp.Status, Code: code, Message: body.Message}
+}
+
+type throughput struct {
+	ReadCapacityUnits  int64
+	WriteCapacityUnits int64
+}
+
+type globalSecondaryIndex struct {
+	IndexName             string
+	KeySchema             []dynamo.KeySchemaT
+	Projection            dynamo.ProjectionT
+	ProvisionedThroughput *throughput `json:",omitempty"`
+}
+
+// createTableInput is goamz's CreateTable request, plus the billing mode.
+// On-demand tables, and their indexes, have no throughput.
+type createTableInput struct {
+	TableName              string
+	AttributeDefinitions   []dynamo.AttributeDefinitionT
+	KeySchema              []dynamo.KeySchemaT
+	GlobalSecondaryIndexes []globalSecondaryIndex `json:",omitempty"`
+	BillingMode            string                 `json:",omitempty"`
+	ProvisionedThroughput  *throughput            `json:",omitempty"`
+}
+
+func newCreateTableInput(description dynamo.TableDescriptionT, billingMode string, t *throughput) createTableInput {
+	in := createTableInput{
+		TableName:            description.TableName,
+		AttributeDefinitions: description.AttributeDefinitions,
+		KeySchema:            description.KeySchema,
+	}
+	if billingMode == OnDemandBilling {
+		in.BillingMode = billingMode
+	} else {
+		in.ProvisionedThroughput = t
+	}
+
+	for _, gsi := range description.GlobalSecondaryIndexes {
+		in.GlobalSecondaryIndexes = append(in.GlobalSecondaryIndexes, globalSecondaryIndex{
+			IndexName:             gsi.IndexName,
+			KeySchema:             gsi.KeySchema,
+			Projection:            gsi.Projection,
+			ProvisionedThroughput: in.ProvisionedThroughput,
+		})
+	}
+
+	return in
+}
+
+// timeToLive is the TTL of a table, EX: expires_at, which items expire by
+type timeToLive struct {
+	AttributeName    string
+	Enabled          bool   `json:",omitempty"`
+	TimeToLiveStatus string `json:",omitempty"` // ENABLING, ENABLED, DISABLING or DISABLED
+}
+
+func updateTimeToLive(s *dynamo.Server, tableName string, attribute string, enabled bool) error {
+	return request(s, "UpdateTimeToLive", map[string]interface{}{
+		"TableName":               tableName,
+		"TimeToLiveSpecification": timeToLive{AttributeName: attribute, Enabled: enabled},
+	}, nil)
+}
+
+func describeTimeToLive(s *dynamo.Server, tableName string) (timeToLive, error) {
+	out := struct{ TimeToLiveDescription timeToLive }{}
+	err := request(s, "DescribeTimeToLive", map[string]string{"TableName": tableName}, &out)
+
+	return out.TimeToLiveDescription, err
+}
+
+// itemKey is an item's key attributes, as dynamodb returns them, EX: {"id": {"S": "id1"}}
+type itemKey map[string]json.RawMessage
+
+type writeRequest struct {
+	DeleteRequest struct{ Key itemKey }
+}
+
+// batchSize is the most writes BatchWriteItem takes
+const batchSize = 25
+
+// truncate scans the table's keys and deletes them in batches, returning how many were deleted.
+// NOTE: the key schema is described, b/c tables created by a migration can have a range key, or another hash key than id.
+func truncate(s *dynamo.Server, tableName string) (int, error) {
+	description, err := s.DescribeTable(tableName)
+	if err != nil {
+		return 0, err
+	}
+
+	projection, names := []string{}, map[string]string{}
+	for i, k := range description.KeySchema {
+		placeholder := "#k" + strconv.Itoa(i)
+		projection = append(projection, placeholder)
+		names[placeholder] = k.AttributeName
+	}
+	in := map[string]interface{}{
+		"TableName":                tableName,
+		"ProjectionExpression":     strings.Join(projection, ", "),
+		"ExpressionAttributeNames": names,
+	}
+
+	deleted := 0
+	for {
+		out := struct {
+			Items            []itemKey
+			LastEvaluatedKey itemKey
+		}{}
+		if err := request(s, "Scan", in, &out); err != nil {
+			return deleted, err
+		}
+
+		for start := 0; start < len(out.Items); start += batchSize {
+			end := start + batchSize
+			if end > len(out.Items) {
+				end = len(out.Items)
+			}
+
+			n, err := batchDelete(s, tableName, out.Items[start:end])
+			deleted += n
+			if err != nil {
+				return deleted, err
+			}
+		}
+
+		if len(out.LastEvaluatedKey) == 0 {
+			return deleted, nil
+		}
+		in["ExclusiveStartKey"] = out.LastEvaluatedKey
+	}
+}
+
+// batchDelete deletes the keys, retrying the ones dynamodb leaves unprocessed when it's throttled
+func batchDelete(s *dynamo.Server, tableName string, keys []itemKey) (int, error) {
+	writes := []writeRequest{}
+	for _, k := range keys {
+		w := writeRequest{}
+		w.DeleteRequest.Key = k
+		writes = append(writes, w)
+	}
+
+	deleted := 0
+	pending := map[string][]writeRequest{tableName: writes}
+	for retries := 0; len(pending[tableName]) > 0; retries++ {
+		if retries == 10 {
+			return deleted, fmt.Errorf("dynamodb did not delete %d items from %s", len(pending[tableName]), tableName)
+		} else if retries > 0 {
+			time.Sleep(time.Duration(50<<uint(retries)) * time.Millisecond)
+		}
+
+		out := struct{ UnprocessedItems map[string][]writeRequest }{}
+		if err := request(s, "BatchWriteItem", map[string]interface{}{"RequestItems": pending}, &out); err != nil {
+			return deleted, err
+		}
+
+		deleted += len(pending[tableName]) - len(out.UnprocessedItems[tableName])
+		pending = out.UnprocessedItems
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,153 @@
+package dynamodb
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+
+	aws "github.com/AdRoll/goamz/aws"
+	dynamo "github.com/AdRoll/goamz/dynamodb"
+	goar "github.com/obieq/goar"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type UniqueDynamodbVehicle struct {
+	ArDynamodb
+	VIN string `json:"vin" schema:"unique=idx_vin"`
+}
+
+func (m *UniqueDynamodbVehicle) Validate() {}
+
+var _ = Describe("Dynamodb Tables", func() {
+	var (
+		api      *httptest.Server
+		s        *dynamo.Server
+		requests map[string][]string // bodies, by action
+		respond  func(action string, body string) (int, string)
+	)
+
+	BeforeEach(func() {
+		requests = map[string][]string{}
+		respond = func(action string, body string) (int, string) { return http.StatusOK, "{}" }
+
+		api = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Ω(r.Header.Get("Authorization")).Should(ContainSubstring("Credential=key/"))
+			action := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "DynamoDB_20120810.")
+			body, _ := ioutil.ReadAll(r.Body)
+			requests[action] = append(requests[action], string(body))
+
+			status, out := respond(action, string(body))
+			w.WriteHeader(status)
+			w.Write([]byte(out))
+		}))
+		s = dynamo.New(aws.Auth{AccessKey: "key", SecretKey: "secret"}, region("us-east-1", api.URL))
+		s.RetryPolicy = aws.NeverRetryPolicy{}
+	})
+
+	AfterEach(func() {
+		api.Close()
+	})
+
+	It("should return dynamodb's errors", func() {
+		respond = func(action string, body string) (int, string) {
+			return http.StatusBadRequest, `{"__type": "com.amazonaws.dynamodb.v20120810#ResourceNotFoundException", "message": "no vehicles"}`
+		}
+
+		_, err := describeTimeToLive(s, "vehicles")
+		Ω(isResourceNotFound(err)).Should(BeTrue())
+		Ω(err.Error()).Should(Equal("ResourceNotFoundException: no vehicles"))
+	})
+
+	It("should create provisioned or on-demand tables", func() {
+		description := dynamo.TableDescriptionT{
+			TableName:              "vehicles",
+			KeySchema:              []dynamo.KeySchemaT{{AttributeName: "id", KeyType: "HASH"}},
+			GlobalSecondaryIndexes: []dynamo.GlobalSecondaryIndexT{{IndexName: "idx_make", KeySchema: []dynamo.KeySchemaT{{AttributeName: "make", KeyType: "HASH"}}}},
+		}
+
+		provisioned, _ := json.Marshal(newCreateTableInput(description, "", &throughput{ReadCapacityUnits: 5, WriteCapacityUnits: 10}))
+		Ω(string(provisioned)).Should(ContainSubstring(`"ProvisionedThroughput":{"ReadCapacityUnits":5,"WriteCapacityUnits":10}}],`))
+		Ω(string(provisioned)).ShouldNot(ContainSubstring("BillingMode"))
+
+		onDemand, _ := json.Marshal(newCreateTableInput(description, OnDemandBilling, &throughput{ReadCapacityUnits: 5, WriteCapacityUnits: 10}))
+		Ω(string(onDemand)).Should(ContainSubstring(`"BillingMode":"PAY_PER_REQUEST"`))
+		Ω(string(onDemand)).ShouldNot(ContainSubstring("ProvisionedThroughput"))
+	})
+
+	It("should enable and describe the ttl", func() {
+		respond = func(action string, body string) (int, string) {
+			return http.StatusOK, `{"TimeToLiveDescription": {"AttributeName": "expires_at", "TimeToLiveStatus": "ENABLING"}}`
+		}
+
+		d := &MigrationDriver{Client: s, TablePrefix: "test_"}
+		Ω(d.EnableTTL("vehicles", "expires_at")).Should(Succeed())
+		Ω(requests["UpdateTimeToLive"]).Should(Equal([]string{`{"TableName":"test_vehicles","TimeToLiveSpecification":{"AttributeName":"expires_at","Enabled":true}}`}))
+
+		attribute, enabled, err := d.TTL("vehicles")
+		Ω(err).NotTo(HaveOccurred())
+		Ω(attribute).Should(Equal("expires_at"))
+		Ω(enabled).Should(BeTrue())
+	})
+
+	It("should truncate page by page, retrying unprocessed deletes", func() {
+		unprocessed := true
+		respond = func(action string, body string) (int, string) {
+			switch {
+			case action == "DescribeTable":
+				return http.StatusOK, `{"Table": {"TableName": "vehicles", "KeySchema": [{"AttributeName": "id", "KeyType": "HASH"}]}}`
+			case action == "Scan" && !strings.Contains(body, "ExclusiveStartKey"):
+				items := []string{}
+				for i := 0; i < 30; i++ {
+					items = append(items, `{"id": {"S": "id`+strconv.Itoa(i)+`"}}`)
+				}
+				return http.StatusOK, `{"Items": [` + strings.Join(items, ",") + `], "LastEvaluatedKey": {"id": {"S": "id29"}}}`
+			case action == "Scan":
+				return http.StatusOK, `{"Items": [{"id": {"S": "id30"}}]}`
+			case unprocessed:
+				unprocessed = false
+				return http.StatusOK, `{"UnprocessedItems": {"vehicles": [{"DeleteRequest": {"Key": {"id": {"S": "id0"}}}}]}}`
+			}
+			return http.StatusOK, `{"UnprocessedItems": {}}`
+		}
+
+		deleted, err := truncate(s, "vehicles")
+		Ω(err).NotTo(HaveOccurred())
+		Ω(deleted).Should(Equal(31))
+		Ω(requests["Scan"]).Should(HaveLen(2))
+		Ω(requests["Scan"][1]).Should(ContainSubstring(`"ExclusiveStartKey":{"id":{"S":"id29"}}`))
+
+		Ω(requests["BatchWriteItem"]).Should(HaveLen(4)) // 25 and a retry of 1, 5, then 1
+		Ω(strings.Count(requests["BatchWriteItem"][0], "DeleteRequest")).Should(Equal(25))
+		Ω(requests["BatchWriteItem"][1]).Should(Equal(`{"RequestItems":{"vehicles":[{"DeleteRequest":{"Key":{"id":{"S":"id0"}}}}]}}`))
+		Ω(strings.Count(requests["BatchWriteItem"][2], "DeleteRequest")).Should(Equal(5))
+	})
+
+	It("should truncate by the table's described keys", func() {
+		respond = func(action string, body string) (int, string) {
+			switch action {
+			case "DescribeTable":
+				return http.StatusOK, `{"Table": {"TableName": "vehicles", "KeySchema": [{"AttributeName": "make", "KeyType": "HASH"}, {"AttributeName": "year", "KeyType": "RANGE"}]}}`
+			case "Scan":
+				return http.StatusOK, `{"Items": [{"make": {"S": "tesla"}, "year": {"N": "2012"}}]}`
+			}
+			return http.StatusOK, `{"UnprocessedItems": {}}`
+		}
+
+		deleted, err := truncate(s, "vehicles")
+		Ω(err).NotTo(HaveOccurred())
+		Ω(deleted).Should(Equal(1))
+		Ω(requests["Scan"][0]).Should(ContainSubstring(`"ProjectionExpression":"#k0, #k1"`))
+		Ω(requests["Scan"][0]).Should(ContainSubstring(`"ExpressionAttributeNames":{"#k0":"make","#k1":"year"}`))
+		Ω(requests["BatchWriteItem"][0]).Should(Equal(`{"RequestItems":{"vehicles":[{"DeleteRequest":{"Key":{"make":{"S":"tesla"},"year":{"N":"2012"}}}}]}}`))
+	})
+
+	It("should not create unique indexes, which dynamodb can't enforce", func() {
+		d := &MigrationDriver{Client: s}
+		Ω(d.CreateTableFor(goar.ToAR(&UniqueDynamodbVehicle{}))).Should(MatchError("dynamodb does not enforce unique indexes: idx_vin"))
+		Ω(requests).ShouldNot(HaveKey("CreateTable"))
+	})
+})
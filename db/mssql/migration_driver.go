@@ -0,0 +1,220 @@
+package mssql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-xorm/xorm"
+	. "github.com/obieq/goar"
+	"github.com/obieq/goar/migration"
+	"github.com/obieq/goar/schema"
+)
+
+// interface assertions
+var _ migration.Driver = (*MigrationDriver)(nil)
+var _ schema.Inspector = (*MigrationDriver)(nil)
+
+// MigrationDriver runs goar migrations against a connection's database.
+// Migrations can run any other DDL via Exec, EX:
+//
+//	d.(*mssql.MigrationDriver).Exec("ALTER TABLE vehicles ADD vin varchar(17)")
+type MigrationDriver struct {
+	Client *xorm.Engine
+}
+
+// NewMigrationDriver uses the connection's engine, EX: NewMigrationDriver("default", "prod")
+func NewMigrationDriver(connName string, env string) *MigrationDriver {
+	return &MigrationDriver{Client: engine(connName, env, nil)}
+}
+
+// NewMaintenanceDriver connects to the server's master database, EX: for CreateDb before the
+// connection's database exists, or DropDb, which sql server refuses while it's in use.
+// NOTE: the engine isn't cached, so Close it when done.
+func NewMaintenanceDriver(connName string, env string) *MigrationDriver {
+	m := *connection(connName, env)
+	m.DBName = MaintenanceDBName
+
+	client := open(&m)
+	client.TZLocation = time.UTC
+
+	return &MigrationDriver{Client: client}
+}
+
+// MaintenanceDBName is the database NewMaintenanceDriver connects to
+var MaintenanceDBName = "master"
+
+// Close closes the driver's engine
+func (d *MigrationDriver) Close() error {
+	return d.Client.Close()
+}
+
+func (d *MigrationDriver) Exec(sql string, values ...interface{}) error {
+	_, err := d.Client.Exec(sql, values...)
+	return err
+}
+
+func (d *MigrationDriver) CreateDb(dbName string) error {
+	return d.Exec("CREATE DATABASE " + quote(dbName))
+}
+
+func (d *MigrationDriver) DropDb(dbName string) error {
+	return d.Exec("DROP DATABASE " + quote(dbName))
+}
+
+// CreateTable creates a table with the columns every ArMsSql model has
+func (d *MigrationDriver) CreateTable(tableName string) error {
+	return d.Exec("CREATE TABLE " + quote(tableName) + " (id int IDENTITY(1,1) PRIMARY KEY, created_at datetime, updated_at datetime)")
+}
+
+// CreateTableFor creates the model's table and indexes, as declared by its struct, EX:
+// d.CreateTableFor(&Vehicle{})
+func (d *MigrationDriver) CreateTableFor(ari ActiveRecordInterfacer) error {
+	for _, statement := range schema.Of(ari, schema.MSSQL).SQL() {
+		if err := d.Exec(statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *MigrationDriver) DropTable(tableName string) error {
+	return d.Exec("DROP TABLE " + quote(tableName))
+}
+
+// AddIndex supports the opts: name (defaults to idx_<table>_<fields>) and unique, EX: {"unique": true}
+func (d *MigrationDriver) AddIndex(tableName string, fields []string, opts map[string]interface{}) error {
+	name, _ := opts["name"].(string)
+	if name == "" {
+		name = "idx_" + tableName + "_" + strings.Join(fields, "_")
+	}
+
+	unique := ""
+	if u, _ := opts["unique"].(bool); u {
+		unique = "UNIQUE "
+	}
+
+	columns := []string{}
+	for _, f := range fields {
+		columns = append(columns, quote(f))
+	}
+
+	return d.Exec(fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)", unique, quote(name), quote(tableName), strings.Join(columns, ", ")))
+}
+
+func (d *MigrationDriver) EnsureLedger() error {
+	return d.Exec("IF OBJECT_ID('" + migration.LedgerName + "', 'U') IS NULL CREATE TABLE " + migration.LedgerName + " (version bigint PRIMARY KEY, applied_at datetime NOT NULL)")
+}
+
+func (d *MigrationDriver) AppliedVersions() ([]int64, error) {
+	rows, err := d.Client.DB().Query("SELECT version FROM " + migration.LedgerName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []int64{}
+	for rows.Next() {
+		var version int64
+		if err = rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
+
+func (d *MigrationDriver) RecordVersion(version int64) error {
+	return d.Exec("INSERT INTO "+migration.LedgerName+" (version, applied_at) VALUES (?, ?)", version, time.Now().UTC())
+}
+
+func (d *MigrationDriver) RemoveVersion(version int64) error {
+	return d.Exec("DELETE FROM "+migration.LedgerName+" WHERE version = ?", version)
+}
+
+func (d *MigrationDriver) Ping() error {
+	return d.Client.Ping()
+}
+
+func (d *MigrationDriver) Dialect() schema.Dialect {
+	return schema.MSSQL
+}
+
+// InspectTable reads the table's columns from sys.columns, and its indexes,
+// other than the primary key, from sys.indexes
+func (d *MigrationDriver) InspectTable(name string) (*schema.Table, error) {
+	t := &schema.Table{Name: name, Dialect: schema.MSSQL, Columns: []schema.Column{}, Indexes: []schema.Index{}}
+
+	rows, err := d.Client.DB().Query(`SELECT c.name, ty.name, c.max_length, c.is_nullable
+		FROM sys.columns c JOIN sys.types ty ON ty.user_type_id = c.user_type_id
+		WHERE c.object_id = OBJECT_ID(?) ORDER BY c.column_id`, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var column, dataType string
+		var length int
+		var nullable bool
+		if err = rows.Scan(&column, &dataType, &length, &nullable); err != nil {
+			return nil, err
+		}
+		t.Columns = append(t.Columns, schema.Column{Name: column, Type: columnType(dataType, length), Nullable: nullable})
+	}
+	if err = rows.Err(); err != nil || len(t.Columns) == 0 {
+		return nil, err
+	}
+
+	indexes, err := d.Client.DB().Query(`SELECT i.name, c.name, i.is_unique
+		FROM sys.indexes i
+		JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+		JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+		WHERE i.object_id = OBJECT_ID(?) AND i.is_primary_key = 0
+		ORDER BY i.name, ic.key_ordinal`, name)
+	if err != nil {
+		return nil, err
+	}
+	defer indexes.Close()
+
+	for indexes.Next() {
+		var index, column string
+		var unique bool
+		if err = indexes.Scan(&index, &column, &unique); err != nil {
+			return nil, err
+		}
+
+		if n := len(t.Indexes); n == 0 || t.Indexes[n-1].Name != index {
+			t.Indexes = append(t.Indexes, schema.Index{Name: index, Unique: unique})
+		}
+		last := &t.Indexes[len(t.Indexes)-1]
+		last.Columns = append(last.Columns, column)
+	}
+
+	return t, indexes.Err()
+}
+
+// columnType formats a sys.types name the way schema declares it, EX: nvarchar, 34 => nvarchar(17)
+// NOTE: max_length is in bytes, and -1 for max
+func columnType(dataType string, length int) string {
+	switch dataType {
+	case "nvarchar", "nchar":
+		if length > 0 {
+			length /= 2
+		}
+	case "varchar", "char", "varbinary", "binary":
+	default:
+		return dataType
+	}
+
+	if length < 0 {
+		return dataType + "(max)"
+	}
+	return fmt.Sprintf("%s(%d)", dataType, length)
+}
+
+func quote(identifier string) string {
+	return "[" + strings.Replace(identifier, "]", "]]", -1) + "]"
+}
@@ -0,0 +1,51 @@
+package mssql_test
+
+import (
+	. "github.com/obieq/goar/db/mssql"
+	"github.com/obieq/goar/migration"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MsSql Migration Driver", func() {
+
+	var driver *MigrationDriver
+
+	BeforeEach(func() {
+		driver = NewMigrationDriver("aws", "test")
+		driver.Exec("IF OBJECT_ID('" + migration.LedgerName + "', 'U') IS NOT NULL DROP TABLE " + migration.LedgerName)
+	})
+
+	It("should record and remove versions", func() {
+		Ω(driver.EnsureLedger()).Should(Succeed())
+		Ω(driver.EnsureLedger()).Should(Succeed())
+		Ω(driver.RecordVersion(20151014093000)).Should(Succeed())
+		Ω(driver.RecordVersion(20151015093000)).Should(Succeed())
+		Ω(driver.AppliedVersions()).Should(ConsistOf(int64(20151014093000), int64(20151015093000)))
+
+		Ω(driver.RemoveVersion(20151015093000)).Should(Succeed())
+		Ω(driver.AppliedVersions()).Should(Equal([]int64{20151014093000}))
+	})
+
+	It("should create tables and indexes", func() {
+		driver.Exec("IF OBJECT_ID('migration_driver_vehicles', 'U') IS NOT NULL DROP TABLE migration_driver_vehicles")
+		Ω(driver.CreateTable("migration_driver_vehicles")).Should(Succeed())
+		Ω(driver.Exec("ALTER TABLE migration_driver_vehicles ADD vin varchar(17)")).Should(Succeed())
+		Ω(driver.AddIndex("migration_driver_vehicles", []string{"vin"}, map[string]interface{}{"unique": true})).Should(Succeed())
+		Ω(driver.AddIndex("migration_driver_vehicles", []string{"vin"}, map[string]interface{}{"unique": true})).ShouldNot(Succeed())
+
+		t, err := driver.InspectTable("migration_driver_vehicles")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(t.Column("vin").Type).Should(Equal("varchar(17)"))
+		Ω(t.Column("vin").Nullable).Should(BeTrue())
+		Ω(t.Indexes).Should(HaveLen(1))
+		Ω(t.Indexes[0].Columns).Should(Equal([]string{"vin"}))
+
+		Ω(driver.DropTable("migration_driver_vehicles")).Should(Succeed())
+		Ω(driver.InspectTable("migration_driver_vehicles")).Should(BeNil())
+	})
+
+	It("should ping the connection", func() {
+		Ω(driver.Ping()).Should(Succeed())
+	})
+})
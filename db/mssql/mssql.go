@@ -1,17 +1,21 @@
 package mssql
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	_ "github.com/denisenkom/go-mssqldb"
 	"github.com/go-xorm/xorm"
 	. "github.com/obieq/goar"
+	"github.com/obieq/goar/naming"
 )
 
 type ArMsSql struct {
@@ -28,28 +32,26 @@ var _ Persister = (*ArMsSql)(nil)
 var _ RDBMSer = (*ArMsSql)(nil)
 
 var (
-	clients = map[string]*xorm.Engine{}
+	clients      = map[string]*xorm.Engine{}
+	clientsMutex sync.Mutex
 )
 
 func connect(connName string, env string) (client *xorm.Engine) {
-	var connString string
-
-	c := Config
-	if c == nil {
-		log.Panic("goar config cannot be nil")
-	}
+	return open(connection(connName, env))
+}
 
+func connection(connName string, env string) *MSSQLConfig {
 	connKey := env + "_mssql_" + connName
-	m, found := c.MSSQLDBs[connKey]
+	m, found := Configuration().MSSQLDBs[connKey]
 	if !found {
 		log.Panic("mssql connection not found:", connKey)
 	}
 
-	if m.FailoverPartner != "" {
-		connString = fmt.Sprintf("server=%s;port=%d;database=%s;user id=%s;password=%s;failoverpartner=%s;failoverport=%d", m.Server, m.Port, m.DBName, m.Username, m.Password, m.FailoverPartner, m.FailoverPort)
-	} else {
-		connString = fmt.Sprintf("server=%s;port=%d;database=%s;user id=%s;password=%s", m.Server, m.Port, m.DBName, m.Username, m.Password)
-	}
+	return m
+}
+
+func open(m *MSSQLConfig) (client *xorm.Engine) {
+	connString := ConnectionString(m)
 
 	if m.Debug {
 		log.Println("connString:", connString)
@@ -76,6 +78,72 @@ func connect(connName string, env string) (client *xorm.Engine) {
 	return db
 }
 
+// ConnectionString returns go-mssqldb's connection string for the config.  It panics on invalid
+// tls settings b/c that's a config error.  NOTE: go-mssqldb doesn't support client certificates,
+// always verifies the host, even for verify-ca, and its timeouts are in whole seconds.
+func ConnectionString(m *MSSQLConfig) string {
+	if err := m.TLS.Validate(); err != nil {
+		log.Panic("invalid mssql tls config:", err)
+	} else if m.TLS.CertFile != "" {
+		log.Panic("mssql doesn't support tls client certificates")
+	}
+
+	connString := fmt.Sprintf("server=%s;port=%d;database=%s;user id=%s;password=%s", m.Server, m.Port, m.DBName, m.Username, m.Password)
+	if m.FailoverPartner != "" {
+		connString += fmt.Sprintf(";failoverpartner=%s;failoverport=%d", m.FailoverPartner, m.FailoverPort)
+	}
+
+	if m.TLS.Enabled() {
+		connString += fmt.Sprintf(";encrypt=true;trustservercertificate=%t", !m.TLS.Verified())
+		if m.TLS.CAFile != "" {
+			connString += ";certificate=" + m.TLS.CAFile
+		}
+		if m.TLS.ServerName != "" {
+			connString += ";hostnameincertificate=" + m.TLS.ServerName
+		}
+	}
+	if m.DialTimeout > 0 {
+		connString += fmt.Sprintf(";dial timeout=%d", seconds(m.DialTimeout))
+	}
+	if m.ReadTimeout > 0 {
+		connString += fmt.Sprintf(";connection timeout=%d", seconds(m.ReadTimeout))
+	}
+	if m.ApplicationName != "" {
+		connString += ";app name=" + m.ApplicationName
+	}
+
+	return connString
+}
+
+// seconds rounds up, so a timeout under a second isn't 0, which go-mssqldb treats as none
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+func init() {
+	RegisterHealthCheck(MSSQL, healthCheck)
+}
+
+// healthCheck pings the connection.  When no model has connected yet, it connects w/o keeping the engine,
+// so the engine still gets the time zone of the model that first connects.
+func healthCheck(ctx context.Context, connName string, env string) error {
+	clientsMutex.Lock()
+	conn, found := clients[connName+"_"+env]
+	clientsMutex.Unlock()
+
+	if !found {
+		conn = connect(connName, env)
+		defer conn.Close()
+	}
+
+	return conn.DB().PingContext(ctx)
+}
+
+// ColumnName is the field's xorm column, EX: safety_rating, which goar's validations query by
+func (ar *ArMsSql) ColumnName(field reflect.StructField) string {
+	return naming.Column(field.Name, field.Tag, naming.MSSQL)
+}
+
 func (ar *ArMsSql) SetKey(key string) {
 	// TODO: set guid key here once that's implemented
 	//ar.ID = key
@@ -83,26 +151,59 @@ func (ar *ArMsSql) SetKey(key string) {
 
 func (ar *ArMsSql) Client() *xorm.Engine {
 	self := ar.Self()
-	connectionKey := self.DBConnectionName() + "_" + self.DBConnectionEnvironment()
 	if self == nil {
 		log.Panic("ar.Self() cannot be blank!")
 	}
 
+	connName, env := ResolveConnection(self)
+	return engine(connName, env, ar.TZLocation)
+}
+
+// ShardClient returns the engine for one of a sharded model's connections
+func (ar *ArMsSql) ShardClient(connName string) *xorm.Engine {
+	self := ar.Self()
+	if self == nil {
+		log.Panic("ar.Self() cannot be blank!")
+	}
+
+	_, env := ResolveConnection(self)
+	return engine(connName, env, ar.TZLocation)
+}
+
+// engine returns the connection's engine, which uses the time zone of the model that first connected
+// engine connects once per connection, like healthCheck, w/o holding clientsMutex while it dials
+func engine(connName string, env string, tz *time.Location) *xorm.Engine {
+	connectionKey := connName + "_" + env
+
+	clientsMutex.Lock()
 	conn, found := clients[connectionKey]
-	if !found {
-		conn = connect(self.DBConnectionName(), self.DBConnectionEnvironment())
-		conn.TZLocation = ar.TZLocation
-		if ar.TZLocation == nil {
-			conn.TZLocation = time.UTC
-		}
+	clientsMutex.Unlock()
+	if found {
+		return conn
+	}
 
-		clients[connectionKey] = conn
+	conn = connect(connName, env)
+	conn.TZLocation = tz
+	if tz == nil {
+		conn.TZLocation = time.UTC
 	}
 
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
+	if existing, found := clients[connectionKey]; found {
+		conn.Close() // another caller connected first
+		return existing
+	}
+	clients[connectionKey] = conn
+
 	return conn
 }
 
 func (ar *ArMsSql) All(models interface{}, opts map[string]interface{}) (err error) {
+	if _, ok := ar.Self().(Sharder); ok {
+		return errors.New("mssql does not query across shards, so sharded models can only be found by id")
+	}
+
 	var limit int = 100
 
 	// set limit
@@ -128,8 +229,17 @@ func (ar *ArMsSql) Truncate() (numRowsDeleted int, err error) {
 	return -1, err
 }
 
-func (ar *ArMsSql) Find(id interface{}, out interface{}) (err error) {
+func (ar *ArMsSql) Find(id interface{}, out interface{}) error {
+	return ReadThrough(ar.Self(), id, out, func() error {
+		return ar.find(id, out)
+	})
+}
+
+func (ar *ArMsSql) find(id interface{}, out interface{}) (err error) {
 	client := ar.Client()
+	if sharder, ok := ar.Self().(Sharder); ok {
+		client = ar.ShardClient(sharder.ShardMap().Shard(fmt.Sprint(id)))
+	}
 
 	_, errConv := strconv.Atoi(id.(string))
 
@@ -150,6 +260,19 @@ func (ar *ArMsSql) DbSave() (err error) {
 	//_, err = client.PutIfAbsent(ar.ModelName(), ar.ID, ar.Self())
 	client := ar.Client()
 
+	if _, outboxed := ar.Self().(Outboxer); outboxed {
+		return ar.inOutboxTransaction(func(session *xorm.Session) error {
+			if ar.ID > 0 {
+				return ar.updateWithOutboxEvent(session)
+			}
+
+			if _, err := session.Insert(ar.Self()); err != nil {
+				return err
+			}
+			return writeOutboxEvent(session, ar.Self(), OutboxCreate, nil, ar.Self())
+		})
+	}
+
 	if ar.ID > 0 {
 		_, err = client.Id(ar.ID).Update(ar.Self())
 	} else {
@@ -162,48 +285,53 @@ func (ar *ArMsSql) DbSave() (err error) {
 }
 
 func (ar *ArMsSql) DbDelete() (err error) {
+	if _, outboxed := ar.Self().(Outboxer); outboxed {
+		return ar.inOutboxTransaction(func(session *xorm.Session) error {
+			if _, err := session.Delete(ar.Self()); err != nil {
+				return err
+			}
+			return writeOutboxEvent(session, ar.Self(), OutboxDelete, ar.Self(), nil)
+		})
+	}
+
 	client := ar.Client()
 	_, err = client.Delete(ar.Self())
 	return
 }
 
+// DbSearch runs the query's where conditions, order bys and limit, EX: make = ? AND year IN (?, ?).
+// Keys are column names, EX: safety_rating, which goar-query's fields use.
 func (ar *ArMsSql) DbSearch(models interface{}) (err error) {
-	var query, sort string
-	//var response *c.SearchResults
-	//query := r.Db(DbName()).Table(ar.Self().ModelName())
-
-	// plucks
-	//query = processPlucks(query, ar)
+	if _, ok := ar.Self().(Sharder); ok {
+		return errors.New("mssql does not query across shards, so sharded models can only be found by id")
+	}
 
-	// where conditions
-	if query, err = processWhereConditions(ar); err != nil {
+	where, args, err := SQLWhere(ar.Query())
+	if err != nil {
+		return err
+	}
+	order, err := SQLOrder(ar.Query())
+	if err != nil {
+		return err
+	}
+	limit, err := QueryLimit(ar.Query())
+	if err != nil {
 		return err
 	}
 
-	// aggregations
-	//if query, err = processAggregations(query, ar); err != nil {
-	//return err
-	//}
-
-	// order bys
-	sort = processSorts(ar)
-
-	// TODO: delete!
-	log.Printf("DbSearch query: %s", query)
-
-	// run search
-	if sort == "" {
-		//if response, err = client.Search(ar.ModelName(), query, 100, 0); err != nil {
-		//return err
-		//}
-	} else {
-		//if response, err = client.SearchSorted(ar.ModelName(), query, sort, 100, 0); err != nil {
-		//return err
-		//}
+	session := ar.Client().NewSession()
+	defer session.Close()
+	if where != "" {
+		session = session.Where(where, args...)
+	}
+	if order != "" {
+		session = session.OrderBy(order)
+	}
+	if limit > 0 {
+		session = session.Limit(limit)
 	}
 
-	//return mapResults(response.Results, models)
-	return nil
+	return session.Find(models)
 }
 
 func (ar *ArMsSql) SpExecResultSet(spName string, params map[string]interface{}, models interface{}) (err error) {
@@ -286,65 +414,6 @@ func mapResults(orchestrateResults interface{}, models interface{}) (err error)
 	return nil
 }
 
-func processWhereConditions(ar *ArMsSql) (query string, err error) {
-	var whereStmt, whereCondition string
-
-	if len(ar.Query().WhereConditions) > 0 {
-		for index, where := range ar.Query().WhereConditions {
-			switch where.RelationalOperator {
-			case EQ: // equal
-				whereCondition = where.Key + ":" + fmt.Sprintf("%v", where.Value)
-				//whereCondition = where.Key + ":" + where.Value.(string)
-				//whereCondition = r.Row.Field(where.Key).Eq(where.Value)
-			//case NE: // not equal
-			//whereCondition = r.Row.Field(where.Key).Ne(where.Value)
-			//case LT: // less than
-			//whereCondition = r.Row.Field(where.Key).Lt(where.Value)
-			//case LTE: // less than or equal
-			//whereCondition = r.Row.Field(where.Key).Le(where.Value)
-			//case GT: // greater than
-			//// TODO: create function to set range based on type???
-			//whereCondition = where.Key + ":[" + fmt.Sprintf("%v", where.Value) + " TO *]"
-			//whereCondition = r.Row.Field(where.Key).Gt(where.Value)
-			case GTE: // greater than or equal
-				whereCondition = where.Key + ":[" + fmt.Sprintf("%v", where.Value) + " TO *]"
-			//whereCondition = r.Row.Field(where.Key).Ge(where.Value)
-			// case IN: // TODO: implement!!!!
-			default:
-				return query, errors.New(fmt.Sprintf("invalid comparison operator: %v", where.RelationalOperator))
-			}
-
-			if index == 0 {
-				whereStmt = whereCondition
-				//if where.LogicalOperator == NOT {
-				//whereStmt = whereStmt.Not()
-				//}
-			} else {
-				switch where.LogicalOperator {
-				case AND:
-					whereStmt = whereStmt + " AND " + whereCondition
-					//whereStmt = whereStmt.And(whereCondition)
-				case OR:
-					whereStmt = whereStmt + " OR " + whereCondition
-				//whereStmt = whereStmt.Or(whereCondition)
-				////case NOT:
-				////whereStmt = whereStmt.And(whereCondition).Not()
-				default:
-					whereStmt = whereStmt + " AND " + whereCondition
-					//whereStmt = whereStmt.And(whereCondition)
-				}
-			}
-		}
-
-		// TODO: delete!!
-		log.Printf("DbSearch whereStmt: %s", whereStmt)
-		//query = query.Filter(whereStmt)
-		//query = query.Filter(whereStmt)
-	}
-
-	return whereStmt, nil
-}
-
 //func processAggregations(query r.Term, ar *ArRethinkDb) (r.Term, error) {
 //// sum
 //if sum := ar.Query().Aggregations[SUM]; sum != nil {
@@ -362,26 +431,3 @@ func processWhereConditions(ar *ArMsSql) (query string, err error) {
 
 //return query, nil
 //}
-
-func processSorts(ar *ArMsSql) (sort string) {
-	if len(ar.Query().OrderBys) > 0 {
-		sort = ""
-
-		for i, orderBy := range ar.Query().OrderBys {
-			if i > 0 {
-				sort += ","
-			}
-
-			sort += "value." + orderBy.Key + ":"
-
-			switch orderBy.SortOrder {
-			case DESC: // descending
-				sort += "desc"
-			default: // ascending
-				sort += "asc"
-			}
-		}
-	}
-
-	return sort
-}
@@ -1,7 +1,11 @@
 package mssql_test
 
 import (
+	"context"
+	"time"
+
 	. "github.com/obieq/goar"
+	"github.com/obieq/goar/db/mssql"
 	. "github.com/obieq/goar/tests/models"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -277,6 +281,18 @@ var _ = Describe("MsSql", func() {
 				})
 			}) // Context: All
 
+			Context("Search", func() {
+				It("should query with IN and GTE conditions, ordered and limited", func() {
+					var results []MsSqlAutomobile
+					ar.Where(Field{Key: "make"}.In("porsche", "bugatti")).Where(QueryCondition{LogicalOperator: AND, Key: "safety_rating", RelationalOperator: GTE, Value: 4})
+					err := ar.Order(OrderBy{Key: "year", SortOrder: DESC}).Limit(1).Run(&results)
+
+					Ω(err).NotTo(HaveOccurred())
+					Ω(len(results)).Should(Equal(1))
+					Ω(results[0].ID).Should(Equal(Bugatti.ID))
+				})
+			})
+
 			//Context("Relational Operators", func() {
 			//Context("Equal", func() {
 			//It("should query with two EQ operators", func() {
@@ -411,3 +427,71 @@ var _ = Describe("MsSql", func() {
 		})
 	})
 })
+
+var _ = Describe("MsSql Connection String", func() {
+	m := func() *MSSQLConfig {
+		return &MSSQLConfig{Server: "db1", Port: 1433, DBName: "app", Username: "sa", Password: "secret"}
+	}
+
+	It("should build the connection string", func() {
+		Ω(mssql.ConnectionString(m())).Should(Equal("server=db1;port=1433;database=app;user id=sa;password=secret"))
+
+		c := m()
+		c.FailoverPartner, c.FailoverPort = "db2", 1434
+		Ω(mssql.ConnectionString(c)).Should(HaveSuffix(";failoverpartner=db2;failoverport=1434"))
+	})
+
+	It("should add the tls, timeout and application name settings", func() {
+		c := m()
+		c.TLS = TLSConfig{Mode: TLSVerifyFull, CAFile: "/etc/ssl/ca.pem", ServerName: "db.internal"}
+		c.DialTimeout = 500 * time.Millisecond
+		c.ReadTimeout = 30 * time.Second
+		c.ApplicationName = "vehicles"
+
+		Ω(mssql.ConnectionString(c)).Should(HaveSuffix(";encrypt=true;trustservercertificate=false;certificate=/etc/ssl/ca.pem" +
+			";hostnameincertificate=db.internal;dial timeout=1;connection timeout=30;app name=vehicles"))
+
+		c.TLS = TLSConfig{Mode: TLSRequire}
+		Ω(mssql.ConnectionString(c)).Should(ContainSubstring(";encrypt=true;trustservercertificate=true"))
+	})
+
+	It("should panic on client certificates", func() {
+		c := m()
+		c.TLS = TLSConfig{Mode: TLSVerifyFull, CertFile: "client.pem", KeyFile: "client.key"}
+		Ω(func() { mssql.ConnectionString(c) }).Should(Panic())
+	})
+})
+
+var _ = Describe("MsSql Health Check", func() {
+	It("should report the connection ok", func() {
+		checked := false
+		for _, h := range HealthCheck(context.Background()).Connections {
+			if h.Adapter == MSSQL && h.Connection == "aws" {
+				Ω(h.Status).Should(Equal(HealthOK), h.Error)
+				checked = true
+			}
+		}
+		Ω(checked).Should(BeTrue())
+	})
+})
+
+type ShardedMsSqlAutomobile struct {
+	MsSqlAutomobile
+}
+
+func (m *ShardedMsSqlAutomobile) ShardMap() ShardMap {
+	return RangeShardMap{{ConnName: "aws"}}
+}
+
+func (m *ShardedMsSqlAutomobile) ShardKey() string {
+	return m.Make
+}
+
+var _ = Describe("MsSql Sharding", func() {
+	It("should not query a sharded model's shards one at a time", func() {
+		var results []ShardedMsSqlAutomobile
+		auto := ToAR(&ShardedMsSqlAutomobile{}).(*ShardedMsSqlAutomobile)
+		Ω(auto.All(&results, nil)).Should(MatchError(ContainSubstring("across shards")))
+		Ω(auto.Where(QueryCondition{Key: "make", RelationalOperator: EQ, Value: "tesla"}).Run(&results)).Should(MatchError(ContainSubstring("across shards")))
+	})
+})
@@ -0,0 +1,117 @@
+package mssql
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-xorm/xorm"
+	. "github.com/obieq/goar"
+)
+
+// interface assertions
+var _ OutboxStore = (*ArMsSql)(nil)
+
+// outboxRow maps an OutboxEvent onto the goar_outbox table, which must exist in the model's database:
+// EX: CREATE TABLE goar_outbox (id varchar(36) PRIMARY KEY, topic varchar(255), model_name varchar(255),
+// [key] varchar(255), operation varchar(10), changes varchar(max), occurred_at datetime2)
+type outboxRow struct {
+	ID         string    `xorm:"pk 'id'"`
+	Topic      string    `xorm:"'topic'"`
+	ModelName  string    `xorm:"'model_name'"`
+	Key        string    `xorm:"'key'"`
+	Operation  string    `xorm:"'operation'"`
+	Changes    string    `xorm:"'changes'"` // comma separated field names
+	OccurredAt time.Time `xorm:"'occurred_at'"`
+}
+
+func (row *outboxRow) TableName() string {
+	return OutboxModelName
+}
+
+func (ar *ArMsSql) PendingOutboxEvents(limit int) ([]*OutboxEvent, error) {
+	rows := []outboxRow{}
+	if err := ar.Client().Asc("occurred_at").Limit(limit).Find(&rows); err != nil {
+		return nil, err
+	}
+
+	events := []*OutboxEvent{}
+	for _, row := range rows {
+		event := &OutboxEvent{
+			ID:         row.ID,
+			Topic:      row.Topic,
+			ModelName:  row.ModelName,
+			Key:        row.Key,
+			Operation:  row.Operation,
+			Changes:    []string{},
+			OccurredAt: row.OccurredAt,
+		}
+		if row.Changes != "" {
+			event.Changes = strings.Split(row.Changes, ",")
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+func (ar *ArMsSql) MarkOutboxEventsPublished(ids []string) error {
+	keys := make([]interface{}, len(ids))
+	for i, id := range ids {
+		keys[i] = id
+	}
+
+	_, err := ar.Client().In("id", keys...).Delete(&outboxRow{})
+	return err
+}
+
+// inOutboxTransaction runs fn in a transaction so the model and its outbox event are written atomically
+func (ar *ArMsSql) inOutboxTransaction(fn func(session *xorm.Session) error) error {
+	session := ar.Client().NewSession()
+	defer session.Close()
+
+	if err := session.Begin(); err != nil {
+		return err
+	}
+
+	if err := fn(session); err != nil {
+		session.Rollback()
+		return err
+	}
+
+	return session.Commit()
+}
+
+// updateWithOutboxEvent reads the current row so the event can list the fields the update changes
+func (ar *ArMsSql) updateWithOutboxEvent(session *xorm.Session) error {
+	before := reflect.New(reflect.TypeOf(ar.Self()).Elem()).Interface()
+	if _, err := session.Id(ar.ID).Get(before); err != nil {
+		return err
+	}
+
+	if _, err := session.Id(ar.ID).Update(ar.Self()); err != nil {
+		return err
+	}
+
+	return writeOutboxEvent(session, ar.Self(), OutboxUpdate, before, ar.Self())
+}
+
+func writeOutboxEvent(session *xorm.Session, ari ActiveRecordInterfacer, operation string, before interface{}, after interface{}) error {
+	changes, err := ChangedFields(before, after)
+	if err != nil {
+		return err
+	}
+
+	event := NewOutboxEvent(ari, operation, changes)
+	_, err = session.Insert(&outboxRow{
+		ID:         event.ID,
+		Topic:      event.Topic,
+		ModelName:  event.ModelName,
+		Key:        event.Key,
+		Operation:  event.Operation,
+		Changes:    strings.Join(event.Changes, ","),
+		OccurredAt: event.OccurredAt,
+	})
+
+	return err
+}
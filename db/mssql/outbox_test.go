@@ -0,0 +1,63 @@
+package mssql_test
+
+import (
+	"strconv"
+
+	. "github.com/obieq/goar"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type OutboxedMsSqlAutomobile struct {
+	MsSqlAutomobile
+}
+
+func (m *OutboxedMsSqlAutomobile) OutboxTopic() string {
+	return "inventory.automobiles"
+}
+
+var _ = Describe("MsSql Outbox", func() {
+
+	var auto *OutboxedMsSqlAutomobile
+
+	BeforeEach(func() {
+		client := MsSqlAutomobile{}.ToActiveRecord().Client()
+		client.Exec("IF OBJECT_ID('" + OutboxModelName + "') IS NULL CREATE TABLE " + OutboxModelName + " (id varchar(36) PRIMARY KEY, topic varchar(255), " +
+			"model_name varchar(255), [key] varchar(255), operation varchar(10), changes varchar(max), occurred_at datetime2)")
+		client.Exec("DELETE FROM " + OutboxModelName)
+
+		auto = ToAR(&OutboxedMsSqlAutomobile{}).(*OutboxedMsSqlAutomobile)
+		auto.SafetyRating, auto.Make, auto.Year, auto.Model = 5, "tesla", 2014, "model s"
+	})
+
+	It("should update an existing record when it's saved again", func() {
+		Ω(auto.Save()).Should(BeTrue())
+		id := auto.ID
+
+		auto.Model = "model x"
+		Ω(auto.Save()).Should(BeTrue())
+		Ω(auto.ID).Should(Equal(id))
+
+		found := OutboxedMsSqlAutomobile{}
+		Ω(ToAR(&found).(*OutboxedMsSqlAutomobile).Find(strconv.Itoa(id), &found)).Should(Succeed())
+		Ω(found.Model).Should(Equal("model x"))
+
+		events, err := auto.PendingOutboxEvents(10)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(events).Should(HaveLen(2))
+		Ω(events[0].Operation).Should(Equal(OutboxCreate))
+		Ω(events[1].Operation).Should(Equal(OutboxUpdate))
+		Ω(events[1].Changes).Should(ContainElement("Model"))
+		Ω(events[1].Changes).ShouldNot(ContainElement("Make"))
+	})
+
+	It("should record an event when a record is deleted", func() {
+		Ω(auto.Save()).Should(BeTrue())
+		Ω(auto.Delete()).Should(Succeed())
+
+		events, err := auto.PendingOutboxEvents(10)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(events).Should(HaveLen(2))
+		Ω(events[1].Operation).Should(Equal(OutboxDelete))
+	})
+})
@@ -1,10 +1,12 @@
 package orchestrate
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
 	"reflect"
+	"sync"
 
 	goar "github.com/obieq/goar"
 	c "github.com/orchestrate-io/gorc"
@@ -22,14 +24,12 @@ type ArOrchestrate struct {
 var _ goar.Persister = (*ArOrchestrate)(nil)
 
 var (
-	clients = map[string]*c.Client{}
+	clients      = map[string]*c.Client{}
+	clientsMutex sync.Mutex
 )
 
 func connect(connName string, env string) (client *c.Client) {
-	cfg := goar.Config
-	if cfg == nil {
-		log.Panicln("goar orchestrate config cannot be nil")
-	}
+	cfg := goar.Configuration()
 
 	connKey := env + "_orchestrate_" + connName
 	m, found := cfg.OrchestrateDBs[connKey]
@@ -44,25 +44,66 @@ func connect(connName string, env string) (client *c.Client) {
 
 func (ar *ArOrchestrate) Client() *c.Client {
 	self := ar.Self()
-	connectionKey := self.DBConnectionName() + "_" + self.DBConnectionEnvironment()
 	if self == nil {
 		log.Panic("orchestrate ar.Self() cannot be blank!")
 	}
 
+	return client(goar.ResolveConnection(self))
+}
+
+// ShardClient returns the client of one of a sharded model's connections
+func (ar *ArOrchestrate) ShardClient(connName string) *c.Client {
+	self := ar.Self()
+	if self == nil {
+		log.Panic("orchestrate ar.Self() cannot be blank!")
+	}
+
+	_, env := goar.ResolveConnection(self)
+	return client(connName, env)
+}
+
+// client is created once per connection, outside clientsMutex like the other adapters
+func client(connName string, env string) *c.Client {
+	connectionKey := connName + "_" + env
+
+	clientsMutex.Lock()
 	conn, found := clients[connectionKey]
-	if !found {
-		conn = connect(self.DBConnectionName(), self.DBConnectionEnvironment())
-		clients[connectionKey] = conn
+	clientsMutex.Unlock()
+	if found {
+		return conn
 	}
 
+	conn = connect(connName, env)
+
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
+	if existing, found := clients[connectionKey]; found {
+		return existing
+	}
+	clients[connectionKey] = conn
+
 	return conn
 }
 
+func init() {
+	goar.RegisterHealthCheck(goar.ORCHESTRATE, healthCheck)
+}
+
+// healthCheck pings orchestrate, which also checks the api key.
+// NOTE: gorc has no context, so goar.HealthCheck stops waiting when ctx is done.
+func healthCheck(ctx context.Context, connName string, env string) error {
+	return client(connName, env).Ping()
+}
+
 func (ar *ArOrchestrate) SetKey(key string) {
 	ar.ID = key
 }
 
 func (ar *ArOrchestrate) All(models interface{}, opts map[string]interface{}) (err error) {
+	if _, ok := ar.Self().(goar.Sharder); ok {
+		return errors.New("orchestrate does not query across shards, so sharded models can only be found by id")
+	}
+
 	var limit int = 10 // per Orchestrate's documentation: 10 default, 100 max
 	var response *c.KVResults
 
@@ -97,7 +138,18 @@ func (ar *ArOrchestrate) Truncate() (numRowsDeleted int, err error) {
 }
 
 func (ar *ArOrchestrate) Find(id interface{}, out interface{}) error {
-	result, err := ar.Client().Get(ar.ModelName(), id.(string))
+	return goar.ReadThrough(ar.Self(), id, out, func() error {
+		return ar.find(id, out)
+	})
+}
+
+func (ar *ArOrchestrate) find(id interface{}, out interface{}) error {
+	conn := ar.Client()
+	if sharder, ok := ar.Self().(goar.Sharder); ok {
+		conn = ar.ShardClient(sharder.ShardMap().Shard(fmt.Sprint(id)))
+	}
+
+	result, err := conn.Get(ar.ModelName(), id.(string))
 
 	if result != nil {
 		err = result.Value(&out)
@@ -128,6 +180,10 @@ func (ar *ArOrchestrate) DbDelete() (err error) {
 }
 
 func (ar *ArOrchestrate) DbSearch(models interface{}) (err error) {
+	if _, ok := ar.Self().(goar.Sharder); ok {
+		return errors.New("orchestrate does not query across shards, so sharded models can only be found by id")
+	}
+
 	var query, sort string
 	var response *c.SearchResults
 	//query := r.Db(DbName()).Table(ar.Self().ModelName())
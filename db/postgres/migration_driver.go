@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	. "github.com/obieq/goar"
+	"github.com/obieq/goar/migration"
+	"github.com/obieq/goar/schema"
+)
+
+// interface assertions
+var _ migration.Driver = (*MigrationDriver)(nil)
+var _ schema.Inspector = (*MigrationDriver)(nil)
+
+// MigrationDriver runs goar migrations against a connection's database.
+// Migrations can run any other DDL via Exec, EX:
+//
+//	d.(*postgres.MigrationDriver).Exec("ALTER TABLE vehicles ADD COLUMN vin varchar(17)")
+type MigrationDriver struct {
+	Client gorm.DB
+}
+
+// NewMigrationDriver uses the connection's client, EX: NewMigrationDriver("default", "prod")
+func NewMigrationDriver(connName string, env string) *MigrationDriver {
+	return &MigrationDriver{Client: session(connName, env)}
+}
+
+// NewMaintenanceDriver connects to the server's postgres database, rather than the connection's,
+// which CreateDb needs when it doesn't exist yet and DropDb needs b/c postgres can't drop the open database.
+// NOTE: the connection isn't shared, so Close it when done.
+func NewMaintenanceDriver(connName string, env string) *MigrationDriver {
+	m := *connection(connName, env)
+	m.DBName = MaintenanceDBName
+
+	return &MigrationDriver{Client: open(&m)}
+}
+
+// MaintenanceDBName is the database NewMaintenanceDriver connects to
+var MaintenanceDBName = "postgres"
+
+// Close closes the driver's connection, EX: NewMaintenanceDriver's
+func (d *MigrationDriver) Close() error {
+	return d.Client.Close()
+}
+
+func (d *MigrationDriver) Exec(sql string, values ...interface{}) error {
+	return d.Client.Exec(sql, values...).Error
+}
+
+func (d *MigrationDriver) CreateDb(dbName string) error {
+	return d.Exec("CREATE DATABASE " + quote(dbName))
+}
+
+func (d *MigrationDriver) DropDb(dbName string) error {
+	return d.Exec("DROP DATABASE " + quote(dbName))
+}
+
+// CreateTable creates a table with the columns every ArPostgres model has
+func (d *MigrationDriver) CreateTable(tableName string) error {
+	return d.Exec("CREATE TABLE " + quote(tableName) + " (id serial PRIMARY KEY, created_at timestamp with time zone, updated_at timestamp with time zone)")
+}
+
+// CreateTableFor creates the model's table and indexes, as declared by its struct, EX:
+// d.CreateTableFor(&Vehicle{})
+func (d *MigrationDriver) CreateTableFor(ari ActiveRecordInterfacer) error {
+	for _, statement := range schema.Of(ari, schema.Postgres).SQL() {
+		if err := d.Exec(statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *MigrationDriver) DropTable(tableName string) error {
+	return d.Exec("DROP TABLE " + quote(tableName))
+}
+
+// AddIndex supports the opts: name (defaults to idx_<table>_<fields>) and unique, EX: {"unique": true}
+func (d *MigrationDriver) AddIndex(tableName string, fields []string, opts map[string]interface{}) error {
+	name, _ := opts["name"].(string)
+	if name == "" {
+		name = "idx_" + tableName + "_" + strings.Join(fields, "_")
+	}
+
+	unique := ""
+	if u, _ := opts["unique"].(bool); u {
+		unique = "UNIQUE "
+	}
+
+	columns := []string{}
+	for _, f := range fields {
+		columns = append(columns, quote(f))
+	}
+
+	return d.Exec(fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)", unique, quote(name), quote(tableName), strings.Join(columns, ", ")))
+}
+
+func (d *MigrationDriver) EnsureLedger() error {
+	return d.Exec("CREATE TABLE IF NOT EXISTS " + migration.LedgerName + " (version bigint PRIMARY KEY, applied_at timestamp with time zone NOT NULL)")
+}
+
+func (d *MigrationDriver) AppliedVersions() ([]int64, error) {
+	rows, err := d.Client.DB().Query("SELECT version FROM " + migration.LedgerName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []int64{}
+	for rows.Next() {
+		var version int64
+		if err = rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
+
+func (d *MigrationDriver) RecordVersion(version int64) error {
+	return d.Exec("INSERT INTO "+migration.LedgerName+" (version, applied_at) VALUES (?, ?)", version, time.Now().UTC())
+}
+
+func (d *MigrationDriver) RemoveVersion(version int64) error {
+	return d.Exec("DELETE FROM "+migration.LedgerName+" WHERE version = ?", version)
+}
+
+func (d *MigrationDriver) Ping() error {
+	return d.Client.DB().Ping()
+}
+
+func (d *MigrationDriver) Dialect() schema.Dialect {
+	return schema.Postgres
+}
+
+// InspectTable reads the table's columns from information_schema, and its indexes,
+// other than the primary key, from pg_index
+func (d *MigrationDriver) InspectTable(name string) (*schema.Table, error) {
+	t := &schema.Table{Name: name, Dialect: schema.Postgres, Columns: []schema.Column{}, Indexes: []schema.Index{}}
+
+	rows, err := d.Client.DB().Query(`SELECT column_name, data_type, COALESCE(character_maximum_length, 0), is_nullable
+		FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = $1
+		ORDER BY ordinal_position`, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var column, dataType, nullable string
+		var length int
+		if err = rows.Scan(&column, &dataType, &length, &nullable); err != nil {
+			return nil, err
+		}
+		if length > 0 {
+			dataType = fmt.Sprintf("%s(%d)", dataType, length)
+		}
+		t.Columns = append(t.Columns, schema.Column{Name: column, Type: dataType, Nullable: nullable == "YES"})
+	}
+	if err = rows.Err(); err != nil || len(t.Columns) == 0 {
+		return nil, err
+	}
+
+	indexes, err := d.Client.DB().Query(`SELECT i.relname, a.attname, ix.indisunique
+		FROM pg_index ix
+		JOIN pg_class tc ON tc.oid = ix.indrelid
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_attribute a ON a.attrelid = tc.oid AND a.attnum = ANY(ix.indkey)
+		WHERE tc.relname = $1 AND tc.relnamespace = current_schema()::regnamespace AND NOT ix.indisprimary
+		ORDER BY i.relname, array_position(ix.indkey::int2[], a.attnum)`, name)
+	if err != nil {
+		return nil, err
+	}
+	defer indexes.Close()
+
+	for indexes.Next() {
+		var index, column string
+		var unique bool
+		if err = indexes.Scan(&index, &column, &unique); err != nil {
+			return nil, err
+		}
+
+		if n := len(t.Indexes); n == 0 || t.Indexes[n-1].Name != index {
+			t.Indexes = append(t.Indexes, schema.Index{Name: index, Unique: unique})
+		}
+		last := &t.Indexes[len(t.Indexes)-1]
+		last.Columns = append(last.Columns, column)
+	}
+
+	return t, indexes.Err()
+}
+
+func quote(identifier string) string {
+	return `"` + strings.Replace(identifier, `"`, `""`, -1) + `"`
+}
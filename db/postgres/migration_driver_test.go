@@ -0,0 +1,42 @@
+package postgres_test
+
+import (
+	. "github.com/obieq/goar/db/postgres"
+	"github.com/obieq/goar/migration"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Postgres Migration Driver", func() {
+
+	var driver *MigrationDriver
+
+	BeforeEach(func() {
+		driver = NewMigrationDriver("aws", "test")
+		driver.Exec("DROP TABLE IF EXISTS " + migration.LedgerName)
+	})
+
+	It("should record and remove versions", func() {
+		Ω(driver.EnsureLedger()).Should(Succeed())
+		Ω(driver.EnsureLedger()).Should(Succeed())
+		Ω(driver.RecordVersion(20151014093000)).Should(Succeed())
+		Ω(driver.RecordVersion(20151015093000)).Should(Succeed())
+		Ω(driver.AppliedVersions()).Should(ConsistOf(int64(20151014093000), int64(20151015093000)))
+
+		Ω(driver.RemoveVersion(20151015093000)).Should(Succeed())
+		Ω(driver.AppliedVersions()).Should(Equal([]int64{20151014093000}))
+	})
+
+	It("should create tables and indexes", func() {
+		driver.Exec("DROP TABLE IF EXISTS migration_driver_vehicles")
+		Ω(driver.CreateTable("migration_driver_vehicles")).Should(Succeed())
+		Ω(driver.Exec("ALTER TABLE migration_driver_vehicles ADD COLUMN vin varchar(17)")).Should(Succeed())
+		Ω(driver.AddIndex("migration_driver_vehicles", []string{"vin"}, map[string]interface{}{"unique": true})).Should(Succeed())
+		Ω(driver.AddIndex("migration_driver_vehicles", []string{"vin"}, map[string]interface{}{"unique": true})).ShouldNot(Succeed())
+		Ω(driver.DropTable("migration_driver_vehicles")).Should(Succeed())
+	})
+
+	It("should ping the connection", func() {
+		Ω(driver.Ping()).Should(Succeed())
+	})
+})
@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	. "github.com/obieq/goar"
+)
+
+// interface assertions
+var _ OutboxStore = (*ArPostgres)(nil)
+
+// outboxRow maps an OutboxEvent onto the goar_outbox table, which must exist in the model's database:
+// EX: CREATE TABLE goar_outbox (id varchar(36) PRIMARY KEY, topic varchar(255), model_name varchar(255),
+// key varchar(255), operation varchar(10), changes text, occurred_at timestamp with time zone)
+type outboxRow struct {
+	ID         string `gorm:"primary_key"`
+	Topic      string
+	ModelName  string
+	Key        string
+	Operation  string
+	Changes    string // comma separated field names
+	OccurredAt time.Time
+}
+
+func (row outboxRow) TableName() string {
+	return OutboxModelName
+}
+
+func (ar *ArPostgres) PendingOutboxEvents(limit int) ([]*OutboxEvent, error) {
+	rows := []outboxRow{}
+	client := ar.Client()
+	if err := client.Order("occurred_at").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	events := []*OutboxEvent{}
+	for _, row := range rows {
+		event := &OutboxEvent{
+			ID:         row.ID,
+			Topic:      row.Topic,
+			ModelName:  row.ModelName,
+			Key:        row.Key,
+			Operation:  row.Operation,
+			Changes:    []string{},
+			OccurredAt: row.OccurredAt,
+		}
+		if row.Changes != "" {
+			event.Changes = strings.Split(row.Changes, ",")
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+func (ar *ArPostgres) MarkOutboxEventsPublished(ids []string) error {
+	client := ar.Client()
+	return client.Where("id in (?)", ids).Delete(outboxRow{}).Error
+}
+
+// inOutboxTransaction runs fn in a transaction so the model and its outbox event are written atomically
+func inOutboxTransaction(client *gorm.DB, fn func(tx *gorm.DB) error) error {
+	tx := client.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// updateWithOutboxEvent reads the current row so the event can list the fields the update changes
+func (ar *ArPostgres) updateWithOutboxEvent(tx *gorm.DB) error {
+	before := reflect.New(reflect.TypeOf(ar.Self()).Elem()).Interface()
+	if err := tx.First(before, ar.ID).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Save(ar.Self()).Error; err != nil {
+		return err
+	}
+
+	return writeOutboxEvent(tx, ar.Self(), OutboxUpdate, before, ar.Self())
+}
+
+func writeOutboxEvent(tx *gorm.DB, ari ActiveRecordInterfacer, operation string, before interface{}, after interface{}) error {
+	changes, err := ChangedFields(before, after)
+	if err != nil {
+		return err
+	}
+
+	event := NewOutboxEvent(ari, operation, changes)
+	return tx.Create(&outboxRow{
+		ID:         event.ID,
+		Topic:      event.Topic,
+		ModelName:  event.ModelName,
+		Key:        event.Key,
+		Operation:  event.Operation,
+		Changes:    strings.Join(event.Changes, ","),
+		OccurredAt: event.OccurredAt,
+	}).Error
+}
@@ -1,14 +1,21 @@
 package postgres
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
 
 	_ "github.com/lib/pq"
 
 	"github.com/jinzhu/gorm"
 	. "github.com/obieq/goar"
+	"github.com/obieq/goar/naming"
 )
 
 type ArPostgres struct {
@@ -24,22 +31,26 @@ var _ Persister = (*ArPostgres)(nil)
 var _ RDBMSer = (*ArPostgres)(nil)
 
 var (
-	clients = map[string]gorm.DB{}
+	clients      = map[string]gorm.DB{}
+	clientsMutex sync.Mutex
 )
 
 func connect(connName string, env string) (client gorm.DB) {
-	c := Config
-	if c == nil {
-		log.Panic("goar config cannot be nil")
-	}
+	return open(connection(connName, env))
+}
 
+func connection(connName string, env string) *PostgresqlDBConfig {
 	connKey := env + "_postgresql_" + connName
-	m, found := c.PostgresqlDBs[connKey]
+	m, found := Configuration().PostgresqlDBs[connKey]
 	if !found {
 		log.Panic("postgresql connection not found:", connKey)
 	}
 
-	connString := fmt.Sprintf("host=%s port=%d dbname=%s user=%s password=%s sslmode=disable", m.Server, m.Port, m.DBName, m.Username, m.Password)
+	return m
+}
+
+func open(m *PostgresqlDBConfig) (client gorm.DB) {
+	connString := ConnectionString(m)
 
 	if m.Debug {
 		log.Println("connString:", connString)
@@ -66,28 +77,116 @@ func connect(connName string, env string) (client gorm.DB) {
 	return db
 }
 
+// ConnectionString returns lib/pq's connection string for the config.  It panics on invalid tls
+// settings b/c that's a config error.  NOTE: lib/pq verifies the server's certificate against the
+// host, so TLS.ServerName isn't supported, and ReadTimeout is sent as the statement_timeout.
+func ConnectionString(m *PostgresqlDBConfig) string {
+	if err := m.TLS.Validate(); err != nil {
+		log.Panic("invalid postgresql tls config:", err)
+	} else if m.TLS.ServerName != "" {
+		log.Panic("postgresql can't verify tls certificates against a server name, so connect via that host")
+	}
+
+	sslmode := m.TLS.Mode
+	if sslmode == "" {
+		sslmode = TLSDisable
+	}
+
+	connString := fmt.Sprintf("host=%s port=%d dbname=%s user=%s password=%s sslmode=%s", m.Server, m.Port, m.DBName, m.Username, m.Password, sslmode)
+	if m.TLS.CAFile != "" {
+		connString += " sslrootcert=" + quoteValue(m.TLS.CAFile)
+	}
+	if m.TLS.CertFile != "" {
+		connString += " sslcert=" + quoteValue(m.TLS.CertFile) + " sslkey=" + quoteValue(m.TLS.KeyFile)
+	}
+	if m.DialTimeout > 0 {
+		connString += fmt.Sprintf(" connect_timeout=%d", int(math.Ceil(m.DialTimeout.Seconds())))
+	}
+	if m.ReadTimeout > 0 {
+		connString += fmt.Sprintf(" statement_timeout=%d", m.ReadTimeout/time.Millisecond)
+	}
+	if m.ApplicationName != "" {
+		connString += " application_name=" + quoteValue(m.ApplicationName)
+	}
+
+	return connString
+}
+
+// quoteValue quotes a connection string value, EX: my app => 'my app'
+func quoteValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
 func (ar *ArPostgres) Client() gorm.DB {
 	self := ar.Self()
-	connectionKey := self.DBConnectionName() + "_" + self.DBConnectionEnvironment()
 	if self == nil {
 		log.Panic("ar.Self() cannot be blank!")
 	}
 
+	return session(ResolveConnection(self))
+}
+
+// ShardClient returns the session for one of a sharded model's connections
+func (ar *ArPostgres) ShardClient(connName string) gorm.DB {
+	self := ar.Self()
+	if self == nil {
+		log.Panic("ar.Self() cannot be blank!")
+	}
+
+	_, env := ResolveConnection(self)
+	return session(connName, env)
+}
+
+// session opens the connection once.  clientsMutex isn't held while it dials, so a
+// database that's down doesn't stall the other connections or their health checks.
+func session(connName string, env string) gorm.DB {
+	connectionKey := connName + "_" + env
+
+	clientsMutex.Lock()
 	conn, found := clients[connectionKey]
-	if !found {
-		conn = connect(self.DBConnectionName(), self.DBConnectionEnvironment())
-		clients[connectionKey] = conn
+	clientsMutex.Unlock()
+	if found {
+		return conn
+	}
+
+	conn = connect(connName, env)
+
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
+	if existing, found := clients[connectionKey]; found {
+		conn.Close() // another caller connected first
+		return existing
 	}
+	clients[connectionKey] = conn
 
 	return conn
 }
 
+func init() {
+	RegisterHealthCheck(POSTGRESQL, healthCheck)
+}
+
+// healthCheck pings the connection
+func healthCheck(ctx context.Context, connName string, env string) error {
+	db := session(connName, env)
+	return db.DB().PingContext(ctx)
+}
+
+// ColumnName is gorm's column for the field, EX: vin_number for a column:vin_number tag
+func (ar *ArPostgres) ColumnName(field reflect.StructField) string {
+	return naming.Column(field.Name, field.Tag, naming.Postgres)
+}
+
 func (ar *ArPostgres) SetKey(key string) {
 	// TODO: set guid key here once that's implemented
 	//ar.ID = key
 }
 
 func (ar *ArPostgres) All(models interface{}, opts map[string]interface{}) (err error) {
+	if _, ok := ar.Self().(Sharder); ok {
+		return errors.New("postgres does not query across shards, so sharded models can only be found by id")
+	}
+
 	var limit int = 100
 
 	// set limit
@@ -111,6 +210,12 @@ func (ar *ArPostgres) Truncate() (numRowsDeleted int, err error) {
 }
 
 func (ar *ArPostgres) Find(id interface{}, out interface{}) error {
+	return ReadThrough(ar.Self(), id, out, func() error {
+		return ar.find(id, out)
+	})
+}
+
+func (ar *ArPostgres) find(id interface{}, out interface{}) error {
 	//result, err := client.Get(ar.ModelName(), id.(string))
 
 	//if result != nil {
@@ -120,6 +225,10 @@ func (ar *ArPostgres) Find(id interface{}, out interface{}) error {
 	//}
 
 	client := ar.Client()
+	if sharder, ok := ar.Self().(Sharder); ok {
+		client = ar.ShardClient(sharder.ShardMap().Shard(fmt.Sprint(id)))
+	}
+
 	return client.First(out, id).Error
 	//return nil
 }
@@ -133,7 +242,24 @@ func (ar *ArPostgres) DbSave() error {
 	//} else {
 	//_, err = client.PutIfAbsent(ar.ModelName(), ar.ID, ar.Self())
 	client := ar.Client()
-	err = client.Create(ar.Self()).Error
+	if _, outboxed := ar.Self().(Outboxer); outboxed {
+		return inOutboxTransaction(&client, func(tx *gorm.DB) error {
+			if ar.ID > 0 {
+				return ar.updateWithOutboxEvent(tx)
+			}
+
+			if err := tx.Create(ar.Self()).Error; err != nil {
+				return err
+			}
+			return writeOutboxEvent(tx, ar.Self(), OutboxCreate, nil, ar.Self())
+		})
+	}
+
+	if ar.ID > 0 { // the record was previously saved
+		err = client.Save(ar.Self()).Error
+	} else {
+		err = client.Create(ar.Self()).Error
+	}
 	//}
 
 	return err
@@ -141,46 +267,52 @@ func (ar *ArPostgres) DbSave() error {
 
 func (ar *ArPostgres) DbDelete() (err error) {
 	//return client.Purge(ar.ModelName(), ar.ID)
-	return nil
+	client := ar.Client()
+	if _, outboxed := ar.Self().(Outboxer); outboxed {
+		return inOutboxTransaction(&client, func(tx *gorm.DB) error {
+			if err := tx.Delete(ar.Self()).Error; err != nil {
+				return err
+			}
+			return writeOutboxEvent(tx, ar.Self(), OutboxDelete, ar.Self(), nil)
+		})
+	}
+
+	return client.Delete(ar.Self()).Error
 }
 
+// DbSearch runs the query's where conditions, order bys and limit, EX: make = ? AND year IN (?, ?).
+// Keys are column names, EX: safety_rating, which goar-query's fields use.
 func (ar *ArPostgres) DbSearch(models interface{}) (err error) {
-	var query, sort string
-	//var response *c.SearchResults
-	//query := r.Db(DbName()).Table(ar.Self().ModelName())
-
-	// plucks
-	//query = processPlucks(query, ar)
+	if _, ok := ar.Self().(Sharder); ok {
+		return errors.New("postgres does not query across shards, so sharded models can only be found by id")
+	}
 
-	// where conditions
-	if query, err = processWhereConditions(ar); err != nil {
+	where, args, err := SQLWhere(ar.Query())
+	if err != nil {
+		return err
+	}
+	order, err := SQLOrder(ar.Query())
+	if err != nil {
+		return err
+	}
+	limit, err := QueryLimit(ar.Query())
+	if err != nil {
 		return err
 	}
 
-	// aggregations
-	//if query, err = processAggregations(query, ar); err != nil {
-	//return err
-	//}
-
-	// order bys
-	sort = processSorts(ar)
-
-	// TODO: delete!
-	log.Printf("DbSearch query: %s", query)
-
-	// run search
-	if sort == "" {
-		//if response, err = client.Search(ar.ModelName(), query, 100, 0); err != nil {
-		//return err
-		//}
-	} else {
-		//if response, err = client.SearchSorted(ar.ModelName(), query, sort, 100, 0); err != nil {
-		//return err
-		//}
+	client := ar.Client()
+	db := &client
+	if where != "" {
+		db = db.Where(where, args...)
+	}
+	if order != "" {
+		db = db.Order(order)
+	}
+	if limit > 0 {
+		db = db.Limit(limit)
 	}
 
-	//return mapResults(response.Results, models)
-	return nil
+	return db.Find(models).Error
 }
 
 func (ar *ArPostgres) SpExecResultSet(spName string, params map[string]interface{}, models interface{}) (err error) {
@@ -196,85 +328,3 @@ func buildSpParams(params map[string]interface{}) string {
 func mapResults(results interface{}, models interface{}) (err error) {
 	return nil
 }
-
-func processWhereConditions(ar *ArPostgres) (query string, err error) {
-	var whereStmt, whereCondition string
-
-	if len(ar.Query().WhereConditions) > 0 {
-		for index, where := range ar.Query().WhereConditions {
-			switch where.RelationalOperator {
-			case EQ: // equal
-				whereCondition = where.Key + ":" + fmt.Sprintf("%v", where.Value)
-				//whereCondition = where.Key + ":" + where.Value.(string)
-				//whereCondition = r.Row.Field(where.Key).Eq(where.Value)
-			//case NE: // not equal
-			//whereCondition = r.Row.Field(where.Key).Ne(where.Value)
-			//case LT: // less than
-			//whereCondition = r.Row.Field(where.Key).Lt(where.Value)
-			//case LTE: // less than or equal
-			//whereCondition = r.Row.Field(where.Key).Le(where.Value)
-			//case GT: // greater than
-			//// TODO: create function to set range based on type???
-			//whereCondition = where.Key + ":[" + fmt.Sprintf("%v", where.Value) + " TO *]"
-			//whereCondition = r.Row.Field(where.Key).Gt(where.Value)
-			case GTE: // greater than or equal
-				whereCondition = where.Key + ":[" + fmt.Sprintf("%v", where.Value) + " TO *]"
-			//whereCondition = r.Row.Field(where.Key).Ge(where.Value)
-			// case IN: // TODO: implement!!!!
-			default:
-				return query, errors.New(fmt.Sprintf("invalid comparison operator: %v", where.RelationalOperator))
-			}
-
-			if index == 0 {
-				whereStmt = whereCondition
-				//if where.LogicalOperator == NOT {
-				//whereStmt = whereStmt.Not()
-				//}
-			} else {
-				switch where.LogicalOperator {
-				case AND:
-					whereStmt = whereStmt + " AND " + whereCondition
-					//whereStmt = whereStmt.And(whereCondition)
-				case OR:
-					whereStmt = whereStmt + " OR " + whereCondition
-				//whereStmt = whereStmt.Or(whereCondition)
-				////case NOT:
-				////whereStmt = whereStmt.And(whereCondition).Not()
-				default:
-					whereStmt = whereStmt + " AND " + whereCondition
-					//whereStmt = whereStmt.And(whereCondition)
-				}
-			}
-		}
-
-		// TODO: delete!!
-		log.Printf("DbSearch whereStmt: %s", whereStmt)
-		//query = query.Filter(whereStmt)
-		//query = query.Filter(whereStmt)
-	}
-
-	return whereStmt, nil
-}
-
-func processSorts(ar *ArPostgres) (sort string) {
-	if len(ar.Query().OrderBys) > 0 {
-		sort = ""
-
-		for i, orderBy := range ar.Query().OrderBys {
-			if i > 0 {
-				sort += ","
-			}
-
-			sort += "value." + orderBy.Key + ":"
-
-			switch orderBy.SortOrder {
-			case DESC: // descending
-				sort += "desc"
-			default: // ascending
-				sort += "asc"
-			}
-		}
-	}
-
-	return sort
-}
@@ -1,7 +1,11 @@
 package postgres_test
 
 import (
+	"context"
+	"time"
+
 	. "github.com/obieq/goar"
+	"github.com/obieq/goar/db/postgres"
 	. "github.com/obieq/goar/tests/models"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -174,6 +178,18 @@ var _ = Describe("Postgres", func() {
 				})
 			}) // Context: All
 
+			Context("Search", func() {
+				It("should query with IN and GTE conditions, ordered and limited", func() {
+					var results []PostgresAutomobile
+					ar.Where(Field{Key: "make"}.In("porsche", "bugatti")).Where(QueryCondition{LogicalOperator: AND, Key: "safety_rating", RelationalOperator: GTE, Value: 4})
+					err := ar.Order(OrderBy{Key: "year", SortOrder: DESC}).Limit(1).Run(&results)
+
+					Ω(err).NotTo(HaveOccurred())
+					Ω(len(results)).Should(Equal(1))
+					Ω(results[0].ID).Should(Equal(Bugatti.ID))
+				})
+			})
+
 			//Context("Relational Operators", func() {
 			//Context("Equal", func() {
 			//It("should query with two EQ operators", func() {
@@ -308,3 +324,68 @@ var _ = Describe("Postgres", func() {
 		})
 	})
 })
+
+var _ = Describe("Postgres Connection String", func() {
+	m := func() *PostgresqlDBConfig {
+		return &PostgresqlDBConfig{Server: "db1", Port: 5432, DBName: "app", Username: "app", Password: "secret"}
+	}
+
+	It("should disable ssl by default", func() {
+		Ω(postgres.ConnectionString(m())).Should(Equal("host=db1 port=5432 dbname=app user=app password=secret sslmode=disable"))
+	})
+
+	It("should add the tls, timeout and application name settings", func() {
+		c := m()
+		c.TLS = TLSConfig{Mode: TLSVerifyFull, CAFile: "/etc/ssl/ca.pem", CertFile: "client.pem", KeyFile: "client.key"}
+		c.DialTimeout = 1500 * time.Millisecond
+		c.ReadTimeout = 30 * time.Second
+		c.ApplicationName = "vehicle's api"
+
+		Ω(postgres.ConnectionString(c)).Should(Equal("host=db1 port=5432 dbname=app user=app password=secret sslmode=verify-full" +
+			" sslrootcert='/etc/ssl/ca.pem' sslcert='client.pem' sslkey='client.key'" +
+			` connect_timeout=2 statement_timeout=30000 application_name='vehicle\'s api'`))
+	})
+
+	It("should panic on tls settings lib/pq doesn't support", func() {
+		c := m()
+		c.TLS = TLSConfig{Mode: TLSVerifyFull, ServerName: "db.internal"}
+		Ω(func() { postgres.ConnectionString(c) }).Should(Panic())
+
+		c.TLS = TLSConfig{Mode: "sometimes"}
+		Ω(func() { postgres.ConnectionString(c) }).Should(Panic())
+	})
+})
+
+var _ = Describe("Postgres Health Check", func() {
+	It("should report the connection ok", func() {
+		checked := false
+		for _, h := range HealthCheck(context.Background()).Connections {
+			if h.Adapter == POSTGRESQL && h.Connection == "aws" {
+				Ω(h.Status).Should(Equal(HealthOK), h.Error)
+				checked = true
+			}
+		}
+		Ω(checked).Should(BeTrue())
+	})
+})
+
+type ShardedPostgresAutomobile struct {
+	PostgresAutomobile
+}
+
+func (m *ShardedPostgresAutomobile) ShardMap() ShardMap {
+	return RangeShardMap{{ConnName: "aws"}}
+}
+
+func (m *ShardedPostgresAutomobile) ShardKey() string {
+	return m.Make
+}
+
+var _ = Describe("Postgres Sharding", func() {
+	It("should not query a sharded model's shards one at a time", func() {
+		var results []ShardedPostgresAutomobile
+		auto := ToAR(&ShardedPostgresAutomobile{}).(*ShardedPostgresAutomobile)
+		Ω(auto.All(&results, nil)).Should(MatchError(ContainSubstring("across shards")))
+		Ω(auto.Where(QueryCondition{Key: "make", RelationalOperator: EQ, Value: "tesla"}).Run(&results)).Should(MatchError(ContainSubstring("across shards")))
+	})
+})
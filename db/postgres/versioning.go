@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/obieq/goar"
+)
+
+// interface assertions
+var _ VersionStore = (*ArPostgres)(nil)
+
+// versionRow maps a Version onto the model's companion table, which must exist in the model's database:
+// EX: CREATE TABLE automobiles_versions (id varchar(36) PRIMARY KEY, model_name varchar(255), key varchar(255),
+// number integer, operation varchar(10), actor_id varchar(255), mode varchar(10), data jsonb, created_at timestamp with time zone)
+type versionRow struct {
+	ID        string `gorm:"primary_key"`
+	ModelName string
+	Key       string
+	Number    int
+	Operation string
+	ActorID   string
+	Mode      string
+	Data      string // json
+	CreatedAt time.Time
+}
+
+func (ar *ArPostgres) WriteVersion(version *Version) error {
+	data, err := json.Marshal(version.Data)
+	if err != nil {
+		return err
+	}
+
+	client := ar.Client()
+	return client.Table(ar.Self().ModelName() + "_versions").Create(&versionRow{
+		ID:        version.ID,
+		ModelName: version.ModelName,
+		Key:       version.Key,
+		Number:    version.Number,
+		Operation: version.Operation,
+		ActorID:   version.ActorID,
+		Mode:      string(version.Mode),
+		Data:      string(data),
+		CreatedAt: version.CreatedAt,
+	}).Error
+}
+
+func (ar *ArPostgres) ReadVersions(key string) ([]*Version, error) {
+	rows := []versionRow{}
+	client := ar.Client()
+	if err := client.Table(ar.Self().ModelName()+"_versions").Where("key = ?", key).Order("number").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	versions := []*Version{}
+	for _, row := range rows {
+		version := &Version{
+			ID:        row.ID,
+			ModelName: row.ModelName,
+			Key:       row.Key,
+			Number:    row.Number,
+			Operation: row.Operation,
+			ActorID:   row.ActorID,
+			Mode:      VersionMode(row.Mode),
+			CreatedAt: row.CreatedAt,
+		}
+		if err := json.Unmarshal([]byte(row.Data), &version.Data); err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
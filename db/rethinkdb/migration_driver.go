@@ -0,0 +1,146 @@
+package rethinkdb
+
+import (
+	"log"
+	"time"
+
+	r "github.com/dancannon/gorethink"
+	goar "github.com/obieq/goar"
+	"github.com/obieq/goar/migration"
+	"github.com/obieq/goar/schema"
+)
+
+// interface assertions
+var _ migration.Driver = (*MigrationDriver)(nil)
+var _ schema.Inspector = (*MigrationDriver)(nil)
+
+// MigrationDriver runs goar migrations against a connection's database.
+// The ledger is a table of applied versions in the same database.
+type MigrationDriver struct {
+	Client *r.Session
+	DBName string
+}
+
+// NewMigrationDriver uses the connection's session and database, EX: NewMigrationDriver("aws", "prod")
+func NewMigrationDriver(connName string, env string) *MigrationDriver {
+	client := session(connName, env)
+	return &MigrationDriver{Client: client, DBName: goar.Configuration().RethinkDBs[env+"_rethinkdb_"+connName].DBName}
+}
+
+func (d *MigrationDriver) CreateDb(dbName string) error {
+	return (&RethinkDbMigration{}).CreateDb(d.Client, dbName)
+}
+
+func (d *MigrationDriver) DropDb(dbName string) error {
+	return (&RethinkDbMigration{}).DropDb(d.Client, dbName)
+}
+
+func (d *MigrationDriver) CreateTable(tableName string) error {
+	return (&RethinkDbMigration{}).CreateTable(d.Client, d.DBName, tableName)
+}
+
+// CreateTableFor creates the model's table and the indexes declared by its struct.
+// NOTE: rethinkdb doesn't enforce unique indexes, so they're created as regular indexes.
+func (d *MigrationDriver) CreateTableFor(ari goar.ActiveRecordInterfacer) error {
+	t := schema.Of(ari, schema.RethinkDB)
+
+	opts := r.TableCreateOpts{}
+	if keys := t.PrimaryKey(); len(keys) == 1 && keys[0] != "id" {
+		opts.PrimaryKey = keys[0]
+	}
+
+	if _, err := r.DB(d.DBName).TableCreate(t.Name, opts).RunWrite(d.Client); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	for _, index := range t.Indexes {
+		if err := d.AddIndex(t.Name, index.Columns, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *MigrationDriver) DropTable(tableName string) error {
+	return (&RethinkDbMigration{}).DropTable(d.Client, d.DBName, tableName)
+}
+
+func (d *MigrationDriver) AddIndex(tableName string, fields []string, opts map[string]interface{}) error {
+	return (&RethinkDbMigration{}).AddIndex(d.Client, d.DBName, tableName, fields, opts)
+}
+
+func (d *MigrationDriver) EnsureLedger() error {
+	var exists bool
+	rows, err := r.DB(d.DBName).TableList().Contains(migration.LedgerName).Run(d.Client)
+	if err == nil {
+		err = rows.One(&exists)
+	}
+
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	} else if exists {
+		return nil
+	}
+
+	return d.CreateTable(migration.LedgerName)
+}
+
+func (d *MigrationDriver) AppliedVersions() ([]int64, error) {
+	versions := []int64{}
+	rows, err := r.DB(d.DBName).Table(migration.LedgerName).Field("id").Run(d.Client)
+	if err == nil {
+		err = rows.All(&versions)
+	}
+
+	return versions, err
+}
+
+func (d *MigrationDriver) RecordVersion(version int64) error {
+	_, err := r.DB(d.DBName).Table(migration.LedgerName).Insert(map[string]interface{}{"id": version, "applied_at": time.Now().UTC()}).RunWrite(d.Client)
+	return err
+}
+
+func (d *MigrationDriver) RemoveVersion(version int64) error {
+	_, err := r.DB(d.DBName).Table(migration.LedgerName).Get(version).Delete().RunWrite(d.Client)
+	return err
+}
+
+// Ping runs a trivial query against the connection
+func (d *MigrationDriver) Ping() error {
+	_, err := r.Expr(1).Run(d.Client)
+	return err
+}
+
+func (d *MigrationDriver) Dialect() schema.Dialect {
+	return schema.RethinkDB
+}
+
+// InspectTable returns the table's indexes, by name only b/c IndexList doesn't report their fields
+func (d *MigrationDriver) InspectTable(name string) (*schema.Table, error) {
+	var exists bool
+	rows, err := r.DB(d.DBName).TableList().Contains(name).Run(d.Client)
+	if err == nil {
+		err = rows.One(&exists)
+	}
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	names := []string{}
+	if rows, err = r.DB(d.DBName).Table(name).IndexList().Run(d.Client); err == nil {
+		err = rows.All(&names)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t := &schema.Table{Name: name, Dialect: schema.RethinkDB, Columns: []schema.Column{}, Indexes: []schema.Index{}}
+	for _, index := range names {
+		t.Indexes = append(t.Indexes, schema.Index{Name: index})
+	}
+
+	return t, nil
+}
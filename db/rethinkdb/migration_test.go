@@ -1,6 +1,7 @@
 package rethinkdb
 
 import (
+	"github.com/obieq/goar/schema"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -89,3 +90,53 @@ var _ = Describe("RethinkDb Migration", func() {
 		})
 	})
 })
+
+type SchemaRethinkDbVehicle struct {
+	ArRethinkDb
+	VIN  string `gorethink:"vin" schema:"unique"`
+	Make string `gorethink:"make" schema:"index"`
+}
+
+func (m *SchemaRethinkDbVehicle) Validate() {}
+
+var _ = Describe("RethinkDb Migration Driver", func() {
+
+	var driver *MigrationDriver
+
+	BeforeEach(func() {
+		driver = &MigrationDriver{Client: migrationTestClient, DBName: rethinkTestDBName}
+	})
+
+	It("should ping the connection", func() {
+		Ω(driver.Ping()).Should(Succeed())
+	})
+
+	It("should create the ledger once", func() {
+		Ω(driver.EnsureLedger()).Should(Succeed())
+		Ω(driver.EnsureLedger()).Should(Succeed())
+	})
+
+	It("should record and remove versions", func() {
+		Ω(driver.EnsureLedger()).Should(Succeed())
+		Ω(driver.RecordVersion(20151014093000)).Should(Succeed())
+		Ω(driver.RecordVersion(20151015093000)).Should(Succeed())
+		Ω(driver.AppliedVersions()).Should(ConsistOf(int64(20151014093000), int64(20151015093000)))
+
+		Ω(driver.RemoveVersion(20151015093000)).Should(Succeed())
+		Ω(driver.AppliedVersions()).Should(Equal([]int64{20151014093000}))
+		Ω(driver.RemoveVersion(20151014093000)).Should(Succeed())
+	})
+
+	It("should create a model's table and indexes", func() {
+		Ω(driver.CreateTableFor(&SchemaRethinkDbVehicle{})).Should(Succeed())
+		Ω(schema.DiffModels(driver, &SchemaRethinkDbVehicle{})).Should(BeEmpty())
+		Ω(driver.DropTable("schema_rethink_db_vehicles")).Should(Succeed())
+		Ω(driver.InspectTable("schema_rethink_db_vehicles")).Should(BeNil())
+	})
+
+	It("should create and drop tables in the connection's database", func() {
+		Ω(driver.CreateTable("migration_driver_table")).Should(Succeed())
+		Ω(driver.AddIndex("migration_driver_table", []string{"vin"}, nil)).Should(Succeed())
+		Ω(driver.DropTable("migration_driver_table")).Should(Succeed())
+	})
+})
@@ -0,0 +1,57 @@
+package rethinkdb
+
+import (
+	"errors"
+
+	r "github.com/dancannon/gorethink"
+	goar "github.com/obieq/goar"
+)
+
+// interface assertions
+var _ goar.OutboxStore = (*ArRethinkDb)(nil)
+
+// PendingOutboxEvents reads the oldest events from the goar_outbox table of the model's connection
+func (ar *ArRethinkDb) PendingOutboxEvents(limit int) ([]*goar.OutboxEvent, error) {
+	rows, err := r.Table(goar.OutboxModelName).OrderBy("occurred_at").Limit(limit).Run(ar.Client())
+	if err != nil {
+		return nil, err
+	}
+
+	events := []*goar.OutboxEvent{}
+	return events, rows.All(&events)
+}
+
+func (ar *ArRethinkDb) MarkOutboxEventsPublished(ids []string) error {
+	keys := make([]interface{}, len(ids))
+	for i, id := range ids {
+		keys[i] = id
+	}
+
+	_, err := r.Table(goar.OutboxModelName).GetAll(keys...).Delete().RunWrite(ar.Client())
+	return err
+}
+
+// writeOutboxEvent records the change returned by an insert or delete.
+// rethinkdb can't write two documents atomically, so the event is written
+// right after the model.  If it fails, the model is saved but the error is returned.
+func (ar *ArRethinkDb) writeOutboxEvent(operation string, rslt r.WriteResponse) error {
+	var oldVal, newVal interface{}
+	if len(rslt.Changes) > 0 { // unchanged documents aren't returned
+		oldVal, newVal = rslt.Changes[0].OldValue, rslt.Changes[0].NewValue
+		if operation == goar.OutboxUpdate && oldVal == nil {
+			operation = goar.OutboxCreate
+		}
+	}
+
+	changes, err := goar.ChangedFields(oldVal, newVal)
+	if err != nil {
+		return err
+	}
+
+	event := goar.NewOutboxEvent(ar.Self(), operation, changes)
+	if _, err = r.Table(goar.OutboxModelName).Insert(event).RunWrite(ar.Client()); err != nil {
+		return errors.New("the model was written, but its outbox event was not: " + err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package rethinkdb
+
+import (
+	r "github.com/dancannon/gorethink"
+	. "github.com/obieq/goar"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type OutboxedRethinkDbAutomobile struct {
+	RethinkDbAutomobile
+}
+
+func (m *OutboxedRethinkDbAutomobile) CustomModelName() string {
+	return "rethink_db_automobiles"
+}
+
+func (m *OutboxedRethinkDbAutomobile) OutboxTopic() string {
+	return "inventory.automobiles"
+}
+
+var _ = Describe("Outbox", func() {
+
+	var auto *OutboxedRethinkDbAutomobile
+
+	BeforeEach(func() {
+		RethinkDbAutomobile{}.ToActiveRecord().Truncate()
+		r.Table(OutboxModelName).Delete().RunWrite(RethinkDbAutomobile{}.ToActiveRecord().Client())
+
+		auto = ToAR(&OutboxedRethinkDbAutomobile{}).(*OutboxedRethinkDbAutomobile)
+		auto.SafetyRating, auto.Make, auto.Year, auto.Model = 5, "tesla", 2014, "model s"
+	})
+
+	It("should record an event for every save and delete", func() {
+		Ω(auto.Save()).Should(BeTrue())
+		auto.Model = "model x"
+		Ω(auto.Save()).Should(BeTrue())
+		Ω(auto.Delete()).Should(Succeed())
+
+		events, err := auto.PendingOutboxEvents(10)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(events).Should(HaveLen(3))
+
+		byOperation := map[string]*OutboxEvent{}
+		for _, event := range events {
+			Ω(event.Topic).Should(Equal("inventory.automobiles"))
+			Ω(event.Key).Should(Equal(auto.ID))
+			byOperation[event.Operation] = event
+		}
+
+		Ω(byOperation[OutboxCreate].Changes).Should(ContainElement("Model"))
+		Ω(byOperation[OutboxUpdate].Changes).Should(ContainElement("Model"))
+		Ω(byOperation[OutboxUpdate].Changes).ShouldNot(ContainElement("Make"))
+		Ω(byOperation).Should(HaveKey(OutboxDelete))
+	})
+
+	It("should remove published events", func() {
+		Ω(auto.Save()).Should(BeTrue())
+		events, err := auto.PendingOutboxEvents(10)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(events).Should(HaveLen(1))
+
+		Ω(auto.MarkOutboxEventsPublished([]string{events[0].ID})).Should(Succeed())
+		events, err = auto.PendingOutboxEvents(10)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(events).Should(BeEmpty())
+	})
+})
@@ -1,14 +1,19 @@
 package rethinkdb
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
 	"reflect"
+	"sync"
 	"time"
 
 	r "github.com/dancannon/gorethink"
+	"github.com/dancannon/gorethink/encoding"
 	goar "github.com/obieq/goar"
+	"github.com/obieq/goar/naming"
+	"github.com/satori/go.uuid"
 )
 
 type ArRethinkDb struct {
@@ -23,11 +28,24 @@ type ArRethinkDb struct {
 var _ goar.Persister = (*ArRethinkDb)(nil)
 
 var (
-	clients = map[string]*r.Session{}
+	clients      = map[string]*r.Session{}
+	clientsMutex sync.Mutex
 )
 
-// this facilitates integration/unit testing
+// this facilitates integration/unit testing.  It panics on invalid tls settings b/c that's a config error.
+// NOTE: gorethink has one timeout for dialing, reads and writes, so it's the longer of the two,
+// and rethinkdb has no application name.
 var connOpts = func(opts *goar.RethinkDBConfig) r.ConnectOpts {
+	tlsConfig, err := opts.TLS.Config()
+	if err != nil {
+		log.Panicln("invalid rethinkdb tls config:", err)
+	}
+
+	timeout := opts.DialTimeout
+	if opts.ReadTimeout > timeout {
+		timeout = opts.ReadTimeout
+	}
+
 	return r.ConnectOpts{
 		Addresses:     opts.Addresses,
 		Database:      opts.DBName,
@@ -35,15 +53,14 @@ var connOpts = func(opts *goar.RethinkDBConfig) r.ConnectOpts {
 		MaxOpen:       opts.MaxOpenConnections,
 		AuthKey:       opts.AuthKey,
 		DiscoverHosts: opts.DiscoverHosts,
+		Timeout:       timeout,
+		TLSConfig:     tlsConfig,
 	}
 }
 
 func connect(connName string, env string) (s *r.Session) {
 	var err error
-	c := goar.Config
-	if c == nil {
-		log.Panicln("goar config cannot be nil")
-	}
+	c := goar.Configuration()
 
 	connKey := env + "_rethinkdb_" + connName
 	m, found := c.RethinkDBs[connKey]
@@ -65,21 +82,86 @@ func (ar *ArRethinkDb) Client() *r.Session {
 		log.Panicln("ar.Self() cannot be blank!")
 	}
 
-	connectionKey := self.DBConnectionName() + "_" + self.DBConnectionEnvironment()
+	return session(goar.ResolveConnection(self))
+}
+
+// ShardClient returns the session for one of a sharded model's connections
+func (ar *ArRethinkDb) ShardClient(connName string) *r.Session {
+	self := ar.Self()
+	if self == nil {
+		log.Panicln("ar.Self() cannot be blank!")
+	}
+
+	_, env := goar.ResolveConnection(self)
+	return session(connName, env)
+}
+
+// session connects once per connection, dialing outside clientsMutex so an unreachable
+// server, EX: during goar.HealthCheck, doesn't block the sessions of other connections.
+func session(connName string, env string) *r.Session {
+	connectionKey := connName + "_" + env
+
+	clientsMutex.Lock()
 	conn, found := clients[connectionKey]
-	if !found {
-		conn = connect(self.DBConnectionName(), self.DBConnectionEnvironment())
-		clients[connectionKey] = conn
+	clientsMutex.Unlock()
+	if found {
+		return conn
+	}
+
+	conn = connect(connName, env)
+
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
+	if existing, found := clients[connectionKey]; found {
+		conn.Close() // another caller connected first
+		return existing
 	}
+	clients[connectionKey] = conn
 
 	return conn
 }
 
+func init() {
+	goar.RegisterHealthCheck(goar.RETHINKDB, healthCheck)
+}
+
+// healthCheck asks the server for its databases, which should include the connection's.
+// NOTE: gorethink has no context, so goar.HealthCheck stops waiting when ctx is done.
+func healthCheck(ctx context.Context, connName string, env string) error {
+	cursor, err := r.DBList().Run(session(connName, env))
+	if err != nil {
+		return err
+	}
+
+	dbNames := []string{}
+	if err = cursor.All(&dbNames); err != nil {
+		return err
+	}
+
+	dbName := goar.Configuration().RethinkDBs[env+"_rethinkdb_"+connName].DBName
+	for _, name := range dbNames {
+		if name == dbName {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("rethinkdb database %s not found", dbName)
+}
+
+// ColumnName is the field's gorethink name, which ValidatesUniqueness queries by
+func (ar *ArRethinkDb) ColumnName(field reflect.StructField) string {
+	return naming.Column(field.Name, field.Tag, naming.RethinkDB)
+}
+
 func (ar *ArRethinkDb) SetKey(key string) {
 	ar.ID = key
 }
 
 func (ar *ArRethinkDb) All(results interface{}, opts map[string]interface{}) error {
+	if sharder, ok := ar.Self().(goar.Sharder); ok {
+		return ar.runSharded(sharder, r.Table(ar.Self().ModelName()), results)
+	}
+
 	//result := []interface{}{}
 	//self := ar.Self()
 	//modelVal := reflect.ValueOf(self).Elem()
@@ -118,7 +200,18 @@ func (ar *ArRethinkDb) Truncate() (numRowsDeleted int, err error) {
 }
 
 func (ar *ArRethinkDb) Find(id interface{}, out interface{}) error {
-	row, err := r.Table(ar.ModelName()).Get(id).Run(ar.Client())
+	return goar.ReadThrough(ar.Self(), id, out, func() error {
+		return ar.find(id, out)
+	})
+}
+
+func (ar *ArRethinkDb) find(id interface{}, out interface{}) error {
+	client := ar.Client()
+	if sharder, ok := ar.Self().(goar.Sharder); ok {
+		client = ar.ShardClient(sharder.ShardMap().Shard(fmt.Sprint(id)))
+	}
+
+	row, err := r.Table(ar.ModelName()).Get(id).Run(client)
 
 	if err != nil {
 		log.Println(err)
@@ -137,18 +230,33 @@ func (ar *ArRethinkDb) Find(id interface{}, out interface{}) error {
 func (ar *ArRethinkDb) DbSave() error {
 	// Conflict parameter values: "error" (default), "replace", "update"
 	// http://rethinkdb.com/api/javascript/insert/
-	rslt, err := r.Table(ar.Self().ModelName()).Insert(ar.Self(), r.InsertOpts{Conflict: "update"}).RunWrite(ar.Client())
+	if _, ok := ar.Self().(goar.Sharder); ok && ar.ID == "" {
+		// the shard is picked before the insert, so the key can't be left to rethinkdb
+		ar.ID = uuid.NewV4().String()
+	}
+
+	_, outboxed := ar.Self().(goar.Outboxer)
+	rslt, err := r.Table(ar.Self().ModelName()).Insert(ar.Self(), r.InsertOpts{Conflict: "update", ReturnChanges: outboxed}).RunWrite(ar.Client())
 	if err == nil && ar.ID == "" { // if the client doesn't specify the PK, then Rethink will auto-generate it
 		ar.ID = rslt.GeneratedKeys[0]
 	}
 
+	if err == nil && outboxed {
+		err = ar.writeOutboxEvent(goar.OutboxUpdate, rslt)
+	}
+
 	return err
 }
 
 func (ar *ArRethinkDb) DbDelete() (err error) {
 	self := ar.Self()
 	modelVal := reflect.ValueOf(self).Elem()
-	_, err = r.Table(self.ModelName()).Get(modelVal.FieldByName("ID").Interface()).Delete().Run(ar.Client()) // TODO: use PrimaryKey
+	_, outboxed := self.(goar.Outboxer)
+	rslt, err := r.Table(self.ModelName()).Get(modelVal.FieldByName("ID").Interface()).Delete(r.DeleteOpts{ReturnChanges: outboxed}).RunWrite(ar.Client()) // TODO: use PrimaryKey
+
+	if err == nil && outboxed {
+		err = ar.writeOutboxEvent(goar.OutboxDelete, rslt)
+	}
 
 	return err
 }
@@ -172,9 +280,22 @@ func (ar *ArRethinkDb) DbSearch(results interface{}) (err error) {
 	// order bys
 	query = processOrderBys(query, ar)
 
+	// limit
+	if query, err = processLimit(query, ar); err != nil {
+		return err
+	}
+
 	// TODO: delete!
 	log.Printf("DbSearch query: %s", query)
 
+	if sharder, ok := ar.Self().(goar.Sharder); ok {
+		if len(ar.Query().Aggregations) > 0 || ar.Query().Distinct {
+			return errors.New("rethinkdb does not support aggregations across shards")
+		}
+
+		return ar.runSharded(sharder, query, results)
+	}
+
 	rows, err := query.Run(ar.Client())
 	if err != nil {
 		return err
@@ -183,6 +304,41 @@ func (ar *ArRethinkDb) DbSearch(results interface{}) (err error) {
 	return rows.All(results)
 }
 
+// runSharded runs the query against every shard in parallel, then merges
+// the rows according to the query's order bys and limit
+func (ar *ArRethinkDb) runSharded(sharder goar.Sharder, query r.Term, results interface{}) error {
+	var mutex sync.Mutex
+	shardRows := [][]map[string]interface{}{}
+
+	limit, err := goar.QueryLimit(ar.Query())
+	if err != nil {
+		return err
+	}
+
+	err = goar.FanOut(sharder.ShardMap().Shards(), func(connName string) error {
+		rows, err := query.Run(ar.ShardClient(connName))
+		if err != nil {
+			return err
+		}
+
+		var shard []map[string]interface{}
+		if err = rows.All(&shard); err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		shardRows = append(shardRows, shard)
+		mutex.Unlock()
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return encoding.Decode(results, goar.MergeShardRows(shardRows, ar.Query().OrderBys, limit))
+}
+
 func processPlucks(query r.Term, ar *ArRethinkDb) r.Term {
 	if plucks := ar.Query().Plucks; plucks != nil {
 		query = query.Pluck(plucks...)
@@ -209,6 +365,8 @@ func processWhereConditions(query r.Term, ar *ArRethinkDb) (r.Term, error) {
 				whereCondition = r.Row.Field(where.Key).Gt(where.Value)
 			case goar.GTE: // greater than or equal
 				whereCondition = r.Row.Field(where.Key).Ge(where.Value)
+			case goar.IN: // any of the values, EX: goar.Field.In
+				whereCondition = r.Expr(where.Value).Contains(r.Row.Field(where.Key))
 			default:
 				return query, errors.New(fmt.Sprintf("invalid comparison operator: %v", where.RelationalOperator))
 			}
@@ -276,3 +434,12 @@ func processOrderBys(query r.Term, ar *ArRethinkDb) r.Term {
 
 	return query
 }
+
+func processLimit(query r.Term, ar *ArRethinkDb) (r.Term, error) {
+	limit, err := goar.QueryLimit(ar.Query())
+	if err == nil && limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	return query, err
+}
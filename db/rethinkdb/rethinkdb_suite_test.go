@@ -88,6 +88,12 @@ var _ = BeforeSuite(func() {
 
 	err = Migration.CreateTable(migrationTestClient, rethinkTestDBName, "callback_error_models")
 	Expect(err).NotTo(HaveOccurred())
+
+	err = Migration.CreateTable(migrationTestClient, rethinkTestDBName, OutboxModelName)
+	Expect(err).NotTo(HaveOccurred())
+
+	err = Migration.CreateTable(migrationTestClient, rethinkTestDBName, "rethink_db_automobiles_versions")
+	Expect(err).NotTo(HaveOccurred())
 })
 
 var _ = AfterSuite(func() {
@@ -1,6 +1,9 @@
 package rethinkdb
 
 import (
+	"context"
+	"time"
+
 	r "github.com/dancannon/gorethink"
 	. "github.com/obieq/goar"
 	goar "github.com/obieq/goar"
@@ -97,14 +100,14 @@ var _ = Describe("ActiveRecord", func() {
 			connect("invalid", "invalid")
 		})
 
-		It("should panic when config file is missing", func() {
+		It("should load the config on first use", func() {
 			config := goar.Config
 			defer func() {
 				goar.Config = config // Revert the config, otherwise things go bump in the night for future tests
-				recover()
 			}()
 			goar.Config = nil
-			connect("invalid", "invalid")
+			Ω(func() { connect("invalid", "invalid") }).Should(Panic())
+			Ω(goar.Config).ShouldNot(BeNil())
 		})
 
 		It("should panic when connection opts are invalid", func() {
@@ -353,6 +356,21 @@ var _ = Describe("ActiveRecord", func() {
 						Ω(len(results)).Should(Equal(2))
 					})
 				})
+
+				Context("In", func() {
+					It("should query with an IN operator", func() {
+						var results []RethinkDbAutomobile
+						Ω(ModelS.Save()).Should(BeTrue())
+						Ω(MK.Save()).Should(BeTrue())
+						Ω(Sprite.Save()).Should(BeTrue())
+
+						ar := RethinkDbAutomobile{}.ToActiveRecord()
+						err := ar.Where(StringField{Field: Field{Key: "Model"}}.In("3000", "model s")).Run(&results)
+
+						Ω(err).NotTo(HaveOccurred())
+						Ω(len(results)).Should(Equal(2))
+					})
+				})
 			})
 		})
 
@@ -529,4 +547,27 @@ var _ = Describe("ActiveRecord", func() {
 			})
 		})
 	})
+
+	Context("Connection Options", func() {
+		It("should use the longer timeout and build a tls config", func() {
+			opts := connOpts(&goar.RethinkDBConfig{
+				DialTimeout: 5 * time.Second,
+				ReadTimeout: 30 * time.Second,
+				TLS:         goar.TLSConfig{Mode: goar.TLSRequire},
+			})
+			Ω(opts.Timeout).Should(Equal(30 * time.Second))
+			Ω(opts.TLSConfig).ShouldNot(BeNil())
+			Ω(opts.TLSConfig.InsecureSkipVerify).Should(BeTrue())
+		})
+
+		It("should connect without tls by default", func() {
+			Ω(connOpts(&goar.RethinkDBConfig{}).TLSConfig).Should(BeNil())
+		})
+	})
+
+	Context("Health Check", func() {
+		It("should find the connection's database", func() {
+			Ω(healthCheck(context.Background(), "aws", "test")).Should(Succeed())
+		})
+	})
 })
@@ -0,0 +1,29 @@
+package rethinkdb
+
+import (
+	r "github.com/dancannon/gorethink"
+	goar "github.com/obieq/goar"
+)
+
+// interface assertions
+var _ goar.VersionStore = (*ArRethinkDb)(nil)
+
+// WriteVersion inserts the version into the model's companion table, EX: rethink_db_automobiles_versions
+func (ar *ArRethinkDb) WriteVersion(version *goar.Version) error {
+	_, err := r.Table(versionsTable(ar.Self())).Insert(version).RunWrite(ar.Client())
+	return err
+}
+
+func (ar *ArRethinkDb) ReadVersions(key string) ([]*goar.Version, error) {
+	rows, err := r.Table(versionsTable(ar.Self())).Filter(r.Row.Field("key").Eq(key)).OrderBy("number").Run(ar.Client())
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []*goar.Version{}
+	return versions, rows.All(&versions)
+}
+
+func versionsTable(ari goar.ActiveRecordInterfacer) string {
+	return ari.ModelName() + "_versions"
+}
@@ -0,0 +1,68 @@
+package rethinkdb
+
+import (
+	"context"
+
+	r "github.com/dancannon/gorethink"
+	. "github.com/obieq/goar"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type VersionedRethinkDbAutomobile struct {
+	RethinkDbAutomobile
+}
+
+func (m *VersionedRethinkDbAutomobile) CustomModelName() string {
+	return "rethink_db_automobiles"
+}
+
+func (m *VersionedRethinkDbAutomobile) VersionMode() VersionMode {
+	return VersionDiff
+}
+
+var _ = Describe("Versioning", func() {
+
+	var auto *VersionedRethinkDbAutomobile
+
+	BeforeEach(func() {
+		RethinkDbAutomobile{}.ToActiveRecord().Truncate()
+		r.Table("rethink_db_automobiles_versions").Delete().RunWrite(RethinkDbAutomobile{}.ToActiveRecord().Client())
+
+		auto = ToAR(&VersionedRethinkDbAutomobile{}).(*VersionedRethinkDbAutomobile)
+		auto.ID, auto.SafetyRating, auto.Make, auto.Year, auto.Model = "model-s", 5, "tesla", 2014, "model s"
+		auto.WithContext(WithActor(context.Background(), "obie"))
+		Ω(auto.Save()).Should(BeTrue())
+
+		auto.Model = "model x"
+		Ω(auto.Save()).Should(BeTrue())
+	})
+
+	It("should list, diff and revert versions", func() {
+		versions, err := Versions(auto)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(versions).Should(HaveLen(2))
+		Ω(versions[0].Operation).Should(Equal(VersionCreate))
+		Ω(versions[1].Operation).Should(Equal(VersionUpdate))
+		Ω(versions[1].ActorID).Should(Equal("obie"))
+
+		changes, err := DiffVersions(auto, 1, 2)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(changes).Should(ContainElement(FieldChange{Field: "model", From: "model s", To: "model x"}))
+
+		Ω(RevertToVersion(auto, 1)).Should(BeTrue())
+		var found VersionedRethinkDbAutomobile
+		Ω(auto.Find("model-s", &found)).Should(Succeed())
+		Ω(found.Model).Should(Equal("model s"))
+	})
+
+	It("should record deletes", func() {
+		Ω(auto.Delete()).Should(Succeed())
+
+		versions, err := Versions(auto)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(versions).Should(HaveLen(3))
+		Ω(versions[2].Operation).Should(Equal(VersionDelete))
+	})
+})
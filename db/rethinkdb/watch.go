@@ -0,0 +1,262 @@
+package rethinkdb
+
+import (
+	"context"
+	"errors"
+	"log"
+	"reflect"
+	"time"
+
+	r "github.com/dancannon/gorethink"
+	"github.com/dancannon/gorethink/encoding"
+	goar "github.com/obieq/goar"
+)
+
+type ChangeOperation string
+
+const (
+	INSERT ChangeOperation = "insert"
+	UPDATE ChangeOperation = "update"
+	DELETE ChangeOperation = "delete"
+	RESYNC ChangeOperation = "resync" // current state of a record, re-read after a reconnect
+)
+
+// ChangeEvent is a single change to a watched table.  OldValue and NewValue
+// have the watched model's type, EX: *RethinkDbAutomobile, and are nil for
+// inserts and deletes respectively.
+type ChangeEvent struct {
+	Operation ChangeOperation
+	OldValue  goar.ActiveRecordInterfacer
+	NewValue  goar.ActiveRecordInterfacer
+}
+
+type WatchOpts struct {
+	// BufferSize is the capacity of the events channel
+	BufferSize int
+	// Squash lets the server batch multiple changes to the same record into one event
+	Squash bool
+	// Resync emits a RESYNC event for every matching record after a reconnect,
+	// so consumers can reconcile changes that happened while disconnected.
+	// NOTE: rethinkdb changefeeds can't be resumed, so changes made while
+	//       disconnected are otherwise lost
+	Resync bool
+	// MinBackoff and MaxBackoff bound the delay between reconnect attempts
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+var defaultWatchOpts = WatchOpts{
+	BufferSize: 100,
+	Resync:     true,
+	MinBackoff: 100 * time.Millisecond,
+	MaxBackoff: 30 * time.Second,
+}
+
+// Watch streams changes to the model's table.  When query is nil, the query
+// built via Where(...) is used, otherwise the given query's where conditions
+// filter the changes.  Dropped connections are re-established with backoff,
+// and the channel is closed once ctx is done.
+func (ar *ArRethinkDb) Watch(ctx context.Context, query *goar.Query, opts ...WatchOpts) (<-chan ChangeEvent, error) {
+	o := defaultWatchOpts
+	if len(opts) > 0 {
+		o = opts[0]
+		if o.MinBackoff <= 0 {
+			o.MinBackoff = defaultWatchOpts.MinBackoff
+		}
+		if o.MaxBackoff < o.MinBackoff {
+			o.MaxBackoff = o.MinBackoff
+		}
+	}
+
+	if query != nil {
+		ar.SetQuery(query)
+	}
+	term, err := ar.watchTerm()
+	ar.SetQuery(goar.NewQuery()) // reset the query struct for future queries
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := term.Changes(r.ChangesOpts{Squash: o.Squash}).Run(ar.Client())
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan ChangeEvent, o.BufferSize)
+	go ar.watch(ctx, term, cursor, events, o)
+
+	return events, nil
+}
+
+func (ar *ArRethinkDb) watchTerm() (r.Term, error) {
+	if _, ok := ar.Self().(goar.Sharder); ok {
+		return r.Term{}, errors.New("rethinkdb changefeeds are not supported for sharded models")
+	}
+
+	q := ar.Query()
+	if len(q.OrderBys) > 0 || len(q.Aggregations) > 0 || q.Distinct || q.Limit != "" {
+		return r.Term{}, errors.New("rethinkdb changefeeds only support where conditions and plucks")
+	}
+
+	term, err := processWhereConditions(r.Table(ar.Self().ModelName()), ar)
+	if err != nil {
+		return term, err
+	}
+
+	return processPlucks(term, ar), nil
+}
+
+func (ar *ArRethinkDb) watch(ctx context.Context, term r.Term, cursor *r.Cursor, events chan<- ChangeEvent, opts WatchOpts) {
+	defer close(events)
+
+	backoff := opts.MinBackoff
+	for {
+		err := ar.drain(ctx, cursor, events)
+		cursor.Close() // before reconnecting, so the interrupted feed doesn't hold its connection
+		if ctx.Err() != nil {
+			return
+		}
+		log.Println("rethinkdb changefeed interrupted.  will reconnect in", backoff, err)
+
+		// reconnect
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+
+			if backoff *= 2; backoff > opts.MaxBackoff {
+				backoff = opts.MaxBackoff
+			}
+
+			if cursor, err = term.Changes(r.ChangesOpts{Squash: opts.Squash}).Run(ar.Client()); err == nil {
+				break
+			}
+			log.Println("rethinkdb changefeed reconnect failed.  will retry in", backoff, err)
+		}
+		backoff = opts.MinBackoff
+
+		// subscribe before re-reading so no change falls between the two
+		if opts.Resync {
+			if err = ar.resync(ctx, term, events); err != nil {
+				log.Println("rethinkdb changefeed resync failed:", err)
+			}
+		}
+	}
+}
+
+// drain forwards the cursor's changes until it fails or ctx is done
+func (ar *ArRethinkDb) drain(ctx context.Context, cursor *r.Cursor, events chan<- ChangeEvent) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			cursor.Close() // unblocks Next
+		case <-done:
+		}
+	}()
+
+	for {
+		// NOTE: a fresh map per change, b/c gorethink decodes into the existing map and keeps the previous change's keys
+		var change map[string]interface{}
+		if !cursor.Next(&change) {
+			break
+		}
+
+		event, err := ar.changeEvent(change)
+		if err != nil {
+			log.Println("rethinkdb changefeed could not decode change:", err)
+			continue
+		} else if event == nil {
+			continue
+		}
+
+		select {
+		case events <- *event:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	return errors.New("rethinkdb changefeed closed")
+}
+
+func (ar *ArRethinkDb) resync(ctx context.Context, term r.Term, events chan<- ChangeEvent) error {
+	rows, err := term.Run(ar.Client())
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for {
+		var row map[string]interface{} // fresh, so a row w/o an omitempty field doesn't keep the previous row's
+		if !rows.Next(&row) {
+			break
+		}
+
+		model, err := ar.decodeModel(row)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case events <- ChangeEvent{Operation: RESYNC, NewValue: model}:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	return rows.Err()
+}
+
+// changeEvent converts a changefeed document, EX: {"old_val": ..., "new_val": ...}.
+// It returns nil for documents that don't describe a change, such as state notifications.
+func (ar *ArRethinkDb) changeEvent(change map[string]interface{}) (*ChangeEvent, error) {
+	oldVal, hasOld := change["old_val"]
+	newVal, hasNew := change["new_val"]
+	if !hasOld && !hasNew {
+		return nil, nil
+	}
+
+	event := &ChangeEvent{Operation: changeOperation(oldVal, newVal)}
+
+	var err error
+	if oldVal != nil {
+		if event.OldValue, err = ar.decodeModel(oldVal); err != nil {
+			return nil, err
+		}
+	}
+	if newVal != nil {
+		if event.NewValue, err = ar.decodeModel(newVal); err != nil {
+			return nil, err
+		}
+	}
+
+	return event, nil
+}
+
+func changeOperation(oldVal interface{}, newVal interface{}) ChangeOperation {
+	switch {
+	case oldVal == nil:
+		return INSERT
+	case newVal == nil:
+		return DELETE
+	}
+
+	return UPDATE
+}
+
+func (ar *ArRethinkDb) decodeModel(value interface{}) (goar.ActiveRecordInterfacer, error) {
+	model := reflect.New(reflect.TypeOf(ar.Self()).Elem()).Interface().(goar.ActiveRecordInterfacer)
+	if err := encoding.Decode(model, value); err != nil {
+		return nil, err
+	}
+
+	return goar.ToAR(model), nil
+}
@@ -0,0 +1,97 @@
+package rethinkdb
+
+import (
+	"context"
+	"time"
+
+	. "github.com/obieq/goar"
+	. "github.com/obieq/goar/tests/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Watch", func() {
+
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+		auto   *RethinkDbAutomobile
+	)
+
+	nextEvent := func(events <-chan ChangeEvent) ChangeEvent {
+		var event ChangeEvent
+		Eventually(events, 5*time.Second).Should(Receive(&event))
+		return event
+	}
+
+	BeforeEach(func() {
+		RethinkDbAutomobile{}.ToActiveRecord().Truncate()
+		ctx, cancel = context.WithCancel(context.Background())
+		auto = RethinkDbAutomobile{SafetyRating: 5, Automobile: Automobile{Vehicle: Vehicle{Make: "tesla", Year: 2014, Model: "model s"}}}.ToActiveRecord()
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("should stream inserts, updates and deletes", func() {
+		events, err := RethinkDbAutomobile{}.ToActiveRecord().Watch(ctx, nil)
+		Ω(err).NotTo(HaveOccurred())
+
+		Ω(auto.Save()).Should(BeTrue())
+		event := nextEvent(events)
+		Ω(event.Operation).Should(Equal(INSERT))
+		Ω(event.OldValue).Should(BeNil())
+		Ω(event.NewValue.(*RethinkDbAutomobile).ID).Should(Equal(auto.ID))
+
+		auto.Model = "model x"
+		Ω(auto.Save()).Should(BeTrue())
+		event = nextEvent(events)
+		Ω(event.Operation).Should(Equal(UPDATE))
+		Ω(event.OldValue.(*RethinkDbAutomobile).Model).Should(Equal("model s"))
+		Ω(event.NewValue.(*RethinkDbAutomobile).Model).Should(Equal("model x"))
+
+		Ω(auto.Delete()).Should(Succeed())
+		event = nextEvent(events)
+		Ω(event.Operation).Should(Equal(DELETE))
+		Ω(event.NewValue).Should(BeNil())
+	})
+
+	It("should filter changes with where conditions", func() {
+		query := NewQuery()
+		query.WhereConditions = []QueryCondition{{Key: "Make", RelationalOperator: EQ, Value: "austin healey"}}
+		events, err := RethinkDbAutomobile{}.ToActiveRecord().Watch(ctx, query)
+		Ω(err).NotTo(HaveOccurred())
+
+		Ω(auto.Save()).Should(BeTrue())
+		sprite := RethinkDbAutomobile{SafetyRating: 2, Automobile: Automobile{Vehicle: Vehicle{Make: "austin healey", Year: 1960, Model: "sprite"}}}.ToActiveRecord()
+		Ω(sprite.Save()).Should(BeTrue())
+
+		event := nextEvent(events)
+		Ω(event.NewValue.(*RethinkDbAutomobile).Model).Should(Equal("sprite"))
+		Consistently(events).ShouldNot(Receive())
+	})
+
+	It("should close the channel when the context is done", func() {
+		events, err := RethinkDbAutomobile{}.ToActiveRecord().Watch(ctx, nil)
+		Ω(err).NotTo(HaveOccurred())
+
+		cancel()
+		Eventually(events, 5*time.Second).Should(BeClosed())
+	})
+
+	It("should return an error for queries changefeeds can't support", func() {
+		ar := RethinkDbAutomobile{}.ToActiveRecord()
+		ar.Order(OrderBy{Key: "Year"})
+		_, err := ar.Watch(ctx, nil)
+		Ω(err).To(HaveOccurred())
+		Ω(ar.Query().OrderBys).Should(BeNil())
+	})
+
+	It("should derive the change operation", func() {
+		Ω(changeOperation(nil, map[string]interface{}{})).Should(Equal(INSERT))
+		Ω(changeOperation(map[string]interface{}{}, map[string]interface{}{})).Should(Equal(UPDATE))
+		Ω(changeOperation(map[string]interface{}{}, nil)).Should(Equal(DELETE))
+	})
+})
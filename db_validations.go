@@ -0,0 +1,104 @@
+package goar
+
+import (
+	"log"
+	"reflect"
+
+	validations "github.com/obieq/goar-validations"
+	"github.com/obieq/goar/naming"
+)
+
+// ColumnNamer is implemented by the adapters whose columns aren't named by the json tag, EX: postgres' snake case.
+// The validations query a field by its column, which is the json name otherwise, EX: dynamodb's.
+type ColumnNamer interface {
+	ColumnName(field reflect.StructField) string
+}
+
+// ValidatesUniqueness adds an error when another record has the same value for field.
+// The optional scope fields narrow the check, EX: a VIN only has to be unique per dealer
+//
+//	func (m *Vehicle) Validate() {
+//		m.ValidatesUniqueness("VIN", "DealerID")
+//	}
+//
+// field and scope are struct field names, which are queried by their columns.  Errors are keyed by
+// the field's json name, like the tag validations'.  Blank values are skipped (use Required to reject them).
+// NOTE: adapters that can't search, EX: couchbase, fail the check w/ "Could not be verified".
+func (ar *ActiveRecord) ValidatesUniqueness(field string, scope ...string) *validations.ValidationResult {
+	self := ar.Self()
+	f, value, ok := structField(self, field)
+	if !ok {
+		return ar.withCode(ar.Validation.Error(field, "Unknown field"), ErrUnknownField, nil)
+	} else if isBlank(value) {
+		return &validations.ValidationResult{Ok: true}
+	}
+
+	probe := newInstanceOf(self)
+	probe.Where(QueryCondition{Key: columnName(self, f), RelationalOperator: EQ, Value: value.Interface()})
+	for _, key := range scope {
+		scopeField, scopeValue, ok := structField(self, key)
+		if !ok {
+			return ar.withCode(ar.Validation.Error(key, "Unknown field"), ErrUnknownField, nil)
+		}
+		probe.Where(QueryCondition{LogicalOperator: AND, Key: columnName(self, scopeField), RelationalOperator: EQ, Value: scopeValue.Interface()})
+	}
+
+	results := reflect.New(reflect.SliceOf(reflect.TypeOf(self)))
+	if err := probe.Run(results.Interface()); err != nil {
+		log.Println("goar uniqueness validation failed:", err)
+		return ar.withCode(ar.Validation.Error(jsonName(f), "Could not be verified"), ErrUnverified, nil)
+	}
+
+	id, persisted := modelID(self)
+	for i := 0; i < results.Elem().Len(); i++ {
+		other, _ := modelID(results.Elem().Index(i).Interface().(ActiveRecordInterfacer))
+		if !persisted || other != id { // exclude the current record on update
+			return ar.withCode(ar.Validation.Error(jsonName(f), "Already taken"), ErrTaken, nil)
+		}
+	}
+
+	return &validations.ValidationResult{Ok: true}
+}
+
+// ValidatesExistence adds an error when foreignKeyField doesn't reference a record of
+// the given model, EX: m.ValidatesExistence("DealerID", Dealer{}.ToActiveRecord())
+// Blank foreign keys are skipped (use Required to reject them).
+func (ar *ActiveRecord) ValidatesExistence(foreignKeyField string, model ActiveRecordInterfacer) *validations.ValidationResult {
+	f, value, ok := structField(ar.Self(), foreignKeyField)
+	if !ok {
+		return ar.withCode(ar.Validation.Error(foreignKeyField, "Unknown field"), ErrUnknownField, nil)
+	} else if isBlank(value) {
+		return &validations.ValidationResult{Ok: true}
+	}
+
+	out := newInstanceOf(model)
+	if err := out.Find(value.Interface(), out); err != nil {
+		// NOTE: the adapters don't share a not found error, so every failure is reported as missing
+		log.Println("goar existence validation failed:", err)
+		return ar.withCode(ar.Validation.Error(jsonName(f), "Does not exist"), ErrNotFound, nil)
+	}
+
+	return &validations.ValidationResult{Ok: true}
+}
+
+func structField(ari ActiveRecordInterfacer, field string) (reflect.StructField, reflect.Value, bool) {
+	f, ok := reflect.TypeOf(ari).Elem().FieldByName(field)
+	if !ok {
+		return f, reflect.Value{}, false
+	}
+
+	return f, reflect.ValueOf(ari).Elem().FieldByIndex(f.Index), true
+}
+
+// columnName is the field's query key
+func columnName(ari ActiveRecordInterfacer, f reflect.StructField) string {
+	if c, ok := ari.(ColumnNamer); ok {
+		return c.ColumnName(f)
+	}
+
+	return naming.Column(f.Name, f.Tag, "")
+}
+
+func isBlank(v reflect.Value) bool {
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
@@ -0,0 +1,154 @@
+package goar
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/obieq/goar/naming"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type UniqueAutomobile struct {
+	ActiveRecordAutomobile
+	ID       string
+	VIN      string `json:"vin" gorm:"column:vin_number"`
+	DealerID string `json:"dealer_id"`
+}
+
+// ColumnName names columns like the postgres adapter, EX: vin_number
+func (m *UniqueAutomobile) ColumnName(field reflect.StructField) string {
+	return naming.Column(field.Name, field.Tag, naming.Postgres)
+}
+
+type AutomobileDealer struct {
+	ActiveRecordAutomobile
+	ID string
+}
+
+var uniqueAutomobileDb = map[string]UniqueAutomobile{}
+var automobileDealerDb = map[string]bool{}
+var uniqueAutomobileSearchedConns = []string{}
+var lastUniquenessQuery *Query
+
+func (m *UniqueAutomobile) Validate() {
+	m.ValidatesUniqueness("VIN", "DealerID")
+	m.ValidatesExistence("DealerID", ToAR(&AutomobileDealer{}))
+}
+
+func (m *UniqueAutomobile) DbSave() error {
+	uniqueAutomobileDb[m.ID] = *m
+	return nil
+}
+
+// DbSearch supports the EQ conditions the validations use, by column
+func (m *UniqueAutomobile) DbSearch(results interface{}) error {
+	connName, _ := ResolveConnection(m)
+	uniqueAutomobileSearchedConns = append(uniqueAutomobileSearchedConns, connName)
+	lastUniquenessQuery = m.Query()
+
+	columns := map[string]string{} // field names, by column
+	for _, name := range []string{"VIN", "DealerID"} {
+		f, _ := reflect.TypeOf(*m).FieldByName(name)
+		columns[m.ColumnName(f)] = name
+	}
+
+	out := reflect.ValueOf(results).Elem()
+	for _, row := range uniqueAutomobileDb {
+		row := row
+		matches := true
+		for _, where := range m.Query().WhereConditions {
+			if columns[where.Key] == "" || reflect.ValueOf(row).FieldByName(columns[where.Key]).Interface() != where.Value {
+				matches = false
+			}
+		}
+		if matches {
+			out.Set(reflect.Append(out, reflect.ValueOf(&row)))
+		}
+	}
+
+	return nil
+}
+
+func (m *AutomobileDealer) Find(id interface{}, out interface{}) error {
+	if !automobileDealerDb[id.(string)] {
+		return errors.New("record not found")
+	}
+	return nil
+}
+
+var _ = Describe("DB Validations", func() {
+
+	newAuto := func(id string, vin string, dealerID string) *UniqueAutomobile {
+		return ToAR(&UniqueAutomobile{ID: id, VIN: vin, DealerID: dealerID}).(*UniqueAutomobile)
+	}
+
+	BeforeEach(func() {
+		uniqueAutomobileDb = map[string]UniqueAutomobile{}
+		automobileDealerDb = map[string]bool{"dealer-1": true, "dealer-2": true}
+		Ω(newAuto("1", "WP0CA2988XS650219", "dealer-1").Save()).Should(BeTrue())
+		uniqueAutomobileSearchedConns = []string{}
+	})
+
+	Context("Uniqueness", func() {
+		It("should reject a duplicate value", func() {
+			auto := newAuto("2", "WP0CA2988XS650219", "dealer-1")
+			Ω(auto.Valid()).Should(BeFalse())
+			Ω(auto.Errors()["vin"].Message).Should(Equal("Already taken"))
+		})
+
+		It("should allow the same value in another scope", func() {
+			Ω(newAuto("2", "WP0CA2988XS650219", "dealer-2").Valid()).Should(BeTrue())
+		})
+
+		It("should exclude the current record on update", func() {
+			Ω(newAuto("1", "WP0CA2988XS650219", "dealer-1").Valid()).Should(BeTrue())
+		})
+
+		It("should query the fields by their columns", func() {
+			auto := newAuto("2", "WP0CA2988XS650219", "dealer-1")
+			auto.Valid()
+
+			keys := []string{}
+			for _, where := range lastUniquenessQuery.WhereConditions {
+				keys = append(keys, where.Key)
+			}
+			Ω(keys).Should(Equal([]string{"vin_number", "dealer_id"}))
+		})
+
+		It("should skip blank values", func() {
+			Ω(newAuto("2", "", "dealer-1").Valid()).Should(BeTrue())
+		})
+
+		It("should check the connection the record is routed to", func() {
+			SetConnectionResolver(func(ari ActiveRecordInterfacer) (string, string) {
+				if auto, ok := ari.(*UniqueAutomobile); ok && auto.DealerID != "" {
+					return "tenant_" + auto.DealerID, ""
+				}
+				return "", ""
+			})
+			defer SetConnectionResolver(nil)
+
+			Ω(newAuto("2", "1GCEK14T54Z188440", "dealer-2").Valid()).Should(BeTrue())
+			Ω(uniqueAutomobileSearchedConns).Should(Equal([]string{"tenant_dealer-2"}))
+		})
+
+		It("should reject an unknown field", func() {
+			auto := newAuto("2", "WP0CA2988XS650219", "dealer-1")
+			auto.ValidatesUniqueness("Serial")
+			Ω(auto.Errors()).Should(HaveKey("Serial"))
+		})
+	})
+
+	Context("Existence", func() {
+		It("should reject a foreign key that doesn't reference a record", func() {
+			auto := newAuto("2", "1GCEK14T54Z188440", "dealer-3")
+			Ω(auto.Valid()).Should(BeFalse())
+			Ω(auto.Errors()["dealer_id"].Message).Should(Equal("Does not exist"))
+		})
+
+		It("should skip blank foreign keys", func() {
+			Ω(newAuto("2", "1GCEK14T54Z188440", "").Valid()).Should(BeTrue())
+		})
+	})
+})
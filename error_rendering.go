@@ -0,0 +1,103 @@
+package goar
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Content types for the rendered validation errors
+const (
+	ProblemJSONContentType = "application/problem+json"
+	JSONAPIContentType     = "application/vnd.api+json"
+)
+
+// ProblemType is the problem+json type uri for validation errors
+var ProblemType = "about:blank"
+
+// ProblemDetails is an RFC 7807 problem, EX:
+//
+//	{"type": "about:blank", "title": "Validation Failed", "status": 422,
+//	 "detail": "vin is required", "invalid-params": [{"name": "vin", "reason": "is required", "code": "required"}]}
+type ProblemDetails struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	InvalidParams []InvalidParam `json:"invalid-params"`
+}
+
+type InvalidParam struct {
+	Name   string                 `json:"name"`
+	Reason string                 `json:"reason"`
+	Code   ErrorCode              `json:"code"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// JSONAPIError is a JSON:API error object, EX:
+//
+//	{"status": "422", "code": "required", "title": "Validation Failed",
+//	 "detail": "is required", "source": {"pointer": "/data/attributes/vin"}}
+type JSONAPIError struct {
+	Status string                 `json:"status"`
+	Code   ErrorCode              `json:"code"`
+	Title  string                 `json:"title"`
+	Detail string                 `json:"detail"`
+	Source JSONAPIErrorSource     `json:"source"`
+	Meta   map[string]interface{} `json:"meta,omitempty"`
+}
+
+type JSONAPIErrorSource struct {
+	Pointer string `json:"pointer"`
+}
+
+const validationFailedTitle = "Validation Failed"
+
+// Problem returns the model's validation errors as an RFC 7807 problem,
+// with the messages in the given locale
+func (ar *ActiveRecord) Problem(locale string) *ProblemDetails {
+	problem := &ProblemDetails{
+		Type:          ProblemType,
+		Title:         validationFailedTitle,
+		Status:        http.StatusUnprocessableEntity,
+		InvalidParams: []InvalidParam{},
+	}
+
+	for _, e := range ar.FieldErrors() {
+		reason := e.LocalizedMessage(locale)
+		problem.InvalidParams = append(problem.InvalidParams, InvalidParam{Name: e.Field, Reason: reason, Code: e.Code, Params: e.Params})
+		if problem.Detail == "" {
+			problem.Detail = fmt.Sprintf("%s %s", e.Field, reason)
+		}
+	}
+
+	return problem
+}
+
+// ProblemJSON renders the model's validation errors as application/problem+json
+func (ar *ActiveRecord) ProblemJSON(locale string) ([]byte, error) {
+	return json.Marshal(ar.Problem(locale))
+}
+
+// JSONAPIErrors returns the model's validation errors as JSON:API error objects,
+// with the messages in the given locale
+func (ar *ActiveRecord) JSONAPIErrors(locale string) []JSONAPIError {
+	errs := []JSONAPIError{}
+	for _, e := range ar.FieldErrors() {
+		errs = append(errs, JSONAPIError{
+			Status: fmt.Sprint(http.StatusUnprocessableEntity),
+			Code:   e.Code,
+			Title:  validationFailedTitle,
+			Detail: e.LocalizedMessage(locale),
+			Source: JSONAPIErrorSource{Pointer: "/data/attributes/" + e.Field},
+			Meta:   e.Params,
+		})
+	}
+
+	return errs
+}
+
+// JSONAPIErrorsJSON renders the model's validation errors as a JSON:API document, EX: {"errors": [...]}
+func (ar *ActiveRecord) JSONAPIErrorsJSON(locale string) ([]byte, error) {
+	return json.Marshal(map[string][]JSONAPIError{"errors": ar.JSONAPIErrors(locale)})
+}
@@ -0,0 +1,47 @@
+package goar
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Error Rendering", func() {
+
+	var auto *TaggedAutomobile
+
+	BeforeEach(func() {
+		auto = validTaggedAutomobile()
+		auto.VIN = ""
+		auto.Options = []string{"sport", "carbon", "leather"}
+		Ω(auto.Valid()).Should(BeFalse())
+	})
+
+	It("should render problem+json", func() {
+		problem := auto.Problem("en")
+		Ω(problem.Status).Should(Equal(422))
+		Ω(problem.Detail).Should(Equal("vin is required"))
+		Ω(problem.InvalidParams).Should(HaveLen(2))
+		Ω(problem.InvalidParams[1]).Should(Equal(InvalidParam{Name: "options", Reason: "must be at most 2 characters", Code: ErrTooLong, Params: map[string]interface{}{"max": float64(2)}}))
+
+		body, err := auto.ProblemJSON("en")
+		Ω(err).NotTo(HaveOccurred())
+		rendered := map[string]interface{}{}
+		Ω(json.Unmarshal(body, &rendered)).Should(Succeed())
+		Ω(rendered).Should(HaveKey("invalid-params"))
+		Ω(rendered["title"]).Should(Equal("Validation Failed"))
+	})
+
+	It("should render JSON:API error objects", func() {
+		errs := auto.JSONAPIErrors("en")
+		Ω(errs).Should(HaveLen(2))
+		Ω(errs[0].Status).Should(Equal("422"))
+		Ω(errs[0].Code).Should(Equal(ErrRequired))
+		Ω(errs[0].Source.Pointer).Should(Equal("/data/attributes/vin"))
+
+		body, err := auto.JSONAPIErrorsJSON("en")
+		Ω(err).NotTo(HaveOccurred())
+		Ω(string(body)).Should(MatchRegexp(`^\{"errors":\[\{"status":"422","code":"required"`))
+	})
+})
@@ -0,0 +1,296 @@
+// Package factory builds and saves models for tests, FactoryBot style, EX:
+//
+//	factory.Define("vehicle", &Vehicle{}, factory.Attrs{
+//		"Make":     "Honda",
+//		"VIN":      factory.Sequence("VIN-%04d"),
+//		"DealerID": factory.Association("dealer"),
+//	}).Trait("motorcycle", factory.Attrs{"Make": "Harley-Davidson", "Year": 1975})
+//
+//	v := factory.Build("vehicle", "motorcycle", factory.Attrs{"Year": 1980}).(*Vehicle)
+//	v, err := factory.Create("vehicle")
+//	list, err := factory.CreateList("vehicle", 3, "motorcycle")
+//
+// Attributes are keyed by struct field.  Create saves via ActiveRecord.Save,
+// so callbacks and validations run, which works with any adapter.
+package factory
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"sync"
+
+	goar "github.com/obieq/goar"
+)
+
+// Attrs are a model's attributes, keyed by struct field, EX: {"Year": 2015}
+type Attrs map[string]interface{}
+
+type Factory struct {
+	name      string
+	model     reflect.Type
+	defaults  Attrs
+	traits    map[string]Attrs
+	sequences map[string]int // by field
+	mutex     sync.Mutex
+}
+
+var (
+	factories      = map[string]*Factory{}
+	factoriesMutex sync.RWMutex
+)
+
+// sequence generates a value from a per factory and field counter, which starts at 1
+type sequence func(n int) interface{}
+
+// association builds or creates another factory's model
+type association struct {
+	name string
+	args []interface{}
+}
+
+// lazy is evaluated after the other attributes are set
+type lazy func(record goar.ActiveRecordInterfacer) interface{}
+
+// Define registers a factory.  It panics if the name is taken b/c that's a programming error.
+func Define(name string, prototype goar.ActiveRecordInterfacer, defaults Attrs) *Factory {
+	factoriesMutex.Lock()
+	defer factoriesMutex.Unlock()
+
+	if _, ok := factories[name]; ok {
+		log.Panicf("factory already defined: %s", name)
+	}
+	if defaults == nil {
+		defaults = Attrs{}
+	}
+
+	f := &Factory{name: name, model: reflect.TypeOf(prototype).Elem(), defaults: defaults, traits: map[string]Attrs{}, sequences: map[string]int{}}
+	factories[name] = f
+
+	return f
+}
+
+// Trait adds a named set of attributes, which Build applies over the defaults
+func (f *Factory) Trait(name string, attrs Attrs) *Factory {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.traits[name] = attrs
+	return f
+}
+
+// Sequence formats a counter, EX: Sequence("VIN-%04d") => VIN-0001, VIN-0002, ...
+func Sequence(format string) interface{} {
+	return sequence(func(n int) interface{} { return fmt.Sprintf(format, n) })
+}
+
+// SequenceFunc generates a value from a counter, EX: SequenceFunc(func(n int) interface{} { return 2000 + n })
+func SequenceFunc(fn func(n int) interface{}) interface{} {
+	return sequence(fn)
+}
+
+// Association builds, or creates, the named factory's model.  It's assigned to a field of
+// the model's type, otherwise its ID is, EX: "DealerID": Association("dealer", "used")
+func Association(name string, args ...interface{}) interface{} {
+	return association{name: name, args: args}
+}
+
+// Lazy derives an attribute from the others, EX:
+//
+//	"Name": factory.Lazy(func(r goar.ActiveRecordInterfacer) interface{} { return r.(*Vehicle).Make + " Civic" })
+func Lazy(fn func(record goar.ActiveRecordInterfacer) interface{}) interface{} {
+	return lazy(fn)
+}
+
+// Build returns the factory's model, unsaved.  The args are trait names and Attrs,
+// applied in order over the defaults, EX: Build("vehicle", "motorcycle", factory.Attrs{"Year": 1980})
+func Build(name string, args ...interface{}) goar.ActiveRecordInterfacer {
+	record, _ := lookup(name).make(false, args)
+	return record
+}
+
+// Create builds the factory's model and saves it, after creating its associations
+func Create(name string, args ...interface{}) (goar.ActiveRecordInterfacer, error) {
+	return lookup(name).make(true, args)
+}
+
+// CreateList creates n of the factory's models
+func CreateList(name string, n int, args ...interface{}) ([]goar.ActiveRecordInterfacer, error) {
+	records := []goar.ActiveRecordInterfacer{}
+	for i := 0; i < n; i++ {
+		record, err := Create(name, args...)
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// ResetSequences restarts every factory's sequences at 1
+func ResetSequences() {
+	factoriesMutex.RLock()
+	defer factoriesMutex.RUnlock()
+
+	for _, f := range factories {
+		f.mutex.Lock()
+		f.sequences = map[string]int{}
+		f.mutex.Unlock()
+	}
+}
+
+// Clear removes every factory, EX: between test suites that define the same names
+func Clear() {
+	factoriesMutex.Lock()
+	defer factoriesMutex.Unlock()
+
+	factories = map[string]*Factory{}
+}
+
+func lookup(name string) *Factory {
+	factoriesMutex.RLock()
+	defer factoriesMutex.RUnlock()
+
+	f, ok := factories[name]
+	if !ok {
+		log.Panicf("unknown factory: %s", name)
+	}
+
+	return f
+}
+
+func (f *Factory) make(create bool, args []interface{}) (goar.ActiveRecordInterfacer, error) {
+	attrs := f.attributes(args)
+	record := goar.ToAR(reflect.New(f.model).Interface().(goar.ActiveRecordInterfacer))
+
+	lazies := []string{}
+	for _, field := range sortedFields(attrs) {
+		value := attrs[field]
+		switch v := value.(type) {
+		case lazy:
+			lazies = append(lazies, field)
+			continue
+		case sequence:
+			value = v(f.next(field))
+		case association:
+			var err error
+			if value, err = f.associate(field, v, create); err != nil {
+				return nil, err
+			}
+		}
+		f.set(record, field, value)
+	}
+
+	for _, field := range lazies {
+		f.set(record, field, attrs[field].(lazy)(record))
+	}
+
+	if !create {
+		return record, nil
+	}
+
+	if success, err := record.Save(); err != nil {
+		return nil, err
+	} else if !success {
+		return nil, fmt.Errorf("could not create %s: %v", f.name, record.Errors())
+	}
+
+	return record, nil
+}
+
+// attributes merges the defaults with the traits and Attrs args, in order
+func (f *Factory) attributes(args []interface{}) Attrs {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	attrs := Attrs{}
+	merge := func(a Attrs) {
+		for k, v := range a {
+			attrs[k] = v
+		}
+	}
+
+	merge(f.defaults)
+	for _, arg := range args {
+		switch a := arg.(type) {
+		case string:
+			trait, ok := f.traits[a]
+			if !ok {
+				log.Panicf("unknown trait for factory %s: %s", f.name, a)
+			}
+			merge(trait)
+		case Attrs:
+			merge(a)
+		default:
+			log.Panicf("factory args are trait names and Attrs, not %T", arg)
+		}
+	}
+
+	return attrs
+}
+
+func (f *Factory) next(field string) int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.sequences[field]++
+	return f.sequences[field]
+}
+
+// associate builds or creates the associated model, and returns it or its ID, depending on the field's type
+func (f *Factory) associate(field string, a association, create bool) (interface{}, error) {
+	associated, err := lookup(a.name).make(create, a.args)
+	if err != nil {
+		return nil, fmt.Errorf("could not create %s's %s: %v", f.name, field, err)
+	}
+
+	if sf, ok := f.model.FieldByName(field); ok && reflect.TypeOf(associated).AssignableTo(sf.Type) {
+		return associated, nil
+	}
+
+	if id := reflect.ValueOf(associated).Elem().FieldByName("ID"); id.IsValid() {
+		return id.Interface(), nil
+	}
+
+	return nil, fmt.Errorf("%s has no ID for %s's %s", a.name, f.name, field)
+}
+
+// set assigns the value, converting it to the field's type, EX: an int to an int64.
+// It panics on an unknown field or a wrong type b/c that's a programming error.
+func (f *Factory) set(record goar.ActiveRecordInterfacer, field string, value interface{}) {
+	fv := reflect.ValueOf(record).Elem().FieldByName(field)
+	if !fv.IsValid() || !fv.CanSet() {
+		log.Panicf("factory %s: unknown field %s", f.name, field)
+	}
+
+	if value == nil {
+		fv.Set(reflect.Zero(fv.Type()))
+		return
+	}
+
+	v := reflect.ValueOf(value)
+	switch {
+	case v.Type().AssignableTo(fv.Type()):
+		fv.Set(v)
+	case v.Type().ConvertibleTo(fv.Type()) && fv.Kind() != reflect.String: // NOT an int to a string
+		fv.Set(v.Convert(fv.Type()))
+	case fv.Kind() == reflect.Ptr && v.Type().AssignableTo(fv.Type().Elem()): // EX: a time.Time for a *time.Time
+		p := reflect.New(fv.Type().Elem())
+		p.Elem().Set(v)
+		fv.Set(p)
+	default:
+		log.Panicf("factory %s: %s is a %s, not a %T", f.name, field, fv.Type(), value)
+	}
+}
+
+func sortedFields(attrs Attrs) []string {
+	fields := []string{}
+	for field := range attrs {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	return fields
+}
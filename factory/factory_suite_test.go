@@ -0,0 +1,74 @@
+package factory
+
+import (
+	"testing"
+	"time"
+
+	goar "github.com/obieq/goar"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestFactory(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Factory Suite")
+}
+
+// saved is the in memory "database" of the test double adapter
+var (
+	saved  = []goar.ActiveRecordInterfacer{}
+	nextID = 0
+)
+
+// ArMemory is a test double adapter, which assigns auto incremented keys
+type ArMemory struct {
+	goar.ActiveRecord
+	ID int `json:"id,omitempty"`
+	goar.Timestamps
+}
+
+func (m *ArMemory) SetKey(key string)                                          {}
+func (m *ArMemory) All(results interface{}, opts map[string]interface{}) error { return nil }
+func (m *ArMemory) Find(id interface{}, out interface{}) error                 { return nil }
+func (m *ArMemory) Truncate() (int, error)                                     { return 0, nil }
+func (m *ArMemory) DBConnectionName() string                                   { return "default" }
+func (m *ArMemory) DBConnectionEnvironment() string                            { return "test" }
+func (m *ArMemory) Validate()                                                  {}
+func (m *ArMemory) DbDelete() error                                            { return nil }
+func (m *ArMemory) DbSearch(results interface{}) error                         { return nil }
+
+func (m *ArMemory) DbSave() error {
+	nextID++
+	m.ID = nextID
+	saved = append(saved, m.Self())
+	return nil
+}
+
+type FactoryDealer struct {
+	ArMemory
+	Name string
+	Used bool
+}
+
+type FactoryVehicle struct {
+	ArMemory
+	VIN      string
+	Make     string
+	Year     int64
+	Name     string
+	SoldAt   *time.Time
+	DealerID int
+	Dealer   *FactoryDealer
+	Saves    int
+}
+
+func (m *FactoryVehicle) Validate() {
+	if m.Year < 1900 {
+		m.AddError("year", goar.ErrTooSmall, map[string]interface{}{"min": 1900})
+	}
+}
+
+func (m *FactoryVehicle) BeforeSave() error {
+	m.Saves++
+	return nil
+}
@@ -0,0 +1,109 @@
+package factory
+
+import (
+	"time"
+
+	goar "github.com/obieq/goar"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Factory", func() {
+
+	BeforeEach(func() {
+		Clear()
+		saved = []goar.ActiveRecordInterfacer{}
+
+		Define("dealer", &FactoryDealer{}, Attrs{"Name": Sequence("Dealer %d")}).
+			Trait("used", Attrs{"Used": true})
+
+		Define("vehicle", &FactoryVehicle{}, Attrs{
+			"VIN":      Sequence("VIN-%04d"),
+			"Make":     "Honda",
+			"Year":     2015,
+			"DealerID": Association("dealer"),
+			"Name": Lazy(func(r goar.ActiveRecordInterfacer) interface{} {
+				return r.(*FactoryVehicle).Make + " " + r.(*FactoryVehicle).VIN
+			}),
+		}).Trait("motorcycle", Attrs{"Make": "Harley-Davidson", "Year": 1975}).
+			Trait("antique", Attrs{"Year": 1899})
+	})
+
+	It("should build an unsaved model from the defaults", func() {
+		v := Build("vehicle").(*FactoryVehicle)
+		Ω(v.Make).Should(Equal("Honda"))
+		Ω(v.Year).Should(Equal(int64(2015)))
+		Ω(v.ID).Should(BeZero())
+		Ω(v.DealerID).Should(BeZero())
+		Ω(saved).Should(BeEmpty())
+	})
+
+	It("should apply traits and attributes in order", func() {
+		v := Build("vehicle", "motorcycle", Attrs{"Year": 1980}).(*FactoryVehicle)
+		Ω(v.Make).Should(Equal("Harley-Davidson"))
+		Ω(v.Year).Should(Equal(int64(1980)))
+
+		v = Build("vehicle", Attrs{"Year": 1980}, "motorcycle").(*FactoryVehicle)
+		Ω(v.Year).Should(Equal(int64(1975)))
+	})
+
+	It("should generate sequences", func() {
+		Ω(Build("vehicle").(*FactoryVehicle).VIN).Should(Equal("VIN-0001"))
+		Ω(Build("vehicle").(*FactoryVehicle).VIN).Should(Equal("VIN-0002"))
+
+		ResetSequences()
+		Ω(Build("vehicle").(*FactoryVehicle).VIN).Should(Equal("VIN-0001"))
+	})
+
+	It("should evaluate lazy attributes after the others", func() {
+		Ω(Build("vehicle", Attrs{"VIN": "ABC"}).(*FactoryVehicle).Name).Should(Equal("Honda ABC"))
+	})
+
+	It("should convert attributes to their field's type", func() {
+		soldAt := time.Date(2015, 10, 14, 0, 0, 0, 0, time.UTC)
+		v := Build("vehicle", Attrs{"SoldAt": soldAt, "Year": int32(2014)}).(*FactoryVehicle)
+		Ω(*v.SoldAt).Should(Equal(soldAt))
+		Ω(v.Year).Should(Equal(int64(2014)))
+	})
+
+	It("should create a model via Save, after its associations", func() {
+		record, err := Create("vehicle")
+		Ω(err).NotTo(HaveOccurred())
+
+		v := record.(*FactoryVehicle)
+		Ω(v.ID).Should(Equal(2))
+		Ω(v.DealerID).Should(Equal(1))
+		Ω(v.Saves).Should(Equal(1)) // BeforeSave
+		Ω(v.CreatedAt).ShouldNot(BeNil())
+		Ω(saved).Should(HaveLen(2))
+	})
+
+	It("should assign an association to a field of its type", func() {
+		v := Build("vehicle", Attrs{"Dealer": Association("dealer", "used")}).(*FactoryVehicle)
+		Ω(v.Dealer.Used).Should(BeTrue())
+		Ω(v.Dealer.Name).Should(Equal("Dealer 1"))
+	})
+
+	It("should not create invalid models", func() {
+		_, err := Create("vehicle", "antique")
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(HavePrefix("could not create vehicle"))
+	})
+
+	It("should create lists", func() {
+		records, err := CreateList("vehicle", 3, "motorcycle")
+		Ω(err).NotTo(HaveOccurred())
+		Ω(records).Should(HaveLen(3))
+		Ω(records[2].(*FactoryVehicle).VIN).Should(Equal("VIN-0003"))
+		Ω(saved).Should(HaveLen(6))
+	})
+
+	It("should panic on programming errors", func() {
+		Ω(func() { Define("vehicle", &FactoryVehicle{}, nil) }).Should(Panic())
+		Ω(func() { Build("truck") }).Should(Panic())
+		Ω(func() { Build("vehicle", "convertible") }).Should(Panic())
+		Ω(func() { Build("vehicle", Attrs{"Color": "red"}) }).Should(Panic())
+		Ω(func() { Build("vehicle", Attrs{"Year": "1975"}) }).Should(Panic())
+		Ω(func() { Build("vehicle", 1975) }).Should(Panic())
+	})
+})
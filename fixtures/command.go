@@ -0,0 +1,70 @@
+package fixtures
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const usage = "usage: db:seed [--truncate] [--env=<env>] [--conn=<conn>] [path ...]"
+
+// SeedPath is where db:seed looks for fixture files by default
+var SeedPath = "db/seeds"
+
+// ProtectedEnvironments can't be seeded, b/c seeds are for development environments
+var ProtectedEnvironments = []string{"prod", "production"}
+
+// Command runs a seed command, so an app can seed its development database, EX:
+//
+//	fixtures.Register(&Vehicle{}, &Dealer{})
+//	if err := fixtures.Command(os.Args[1:], os.Stdout); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// db:seed saves the fixtures in SeedPath, or the given paths, truncating their models first with --truncate.
+// --env and --conn seed another environment or connection than the models' own.
+func Command(args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] != "db:seed" {
+		return fmt.Errorf(usage)
+	}
+
+	truncate, connName, env, paths := false, "", "", []string{}
+	for _, arg := range args[1:] {
+		if arg == "--truncate" {
+			truncate = true
+		} else if strings.HasPrefix(arg, "--env=") {
+			env = strings.TrimPrefix(arg, "--env=")
+		} else if strings.HasPrefix(arg, "--conn=") {
+			connName = strings.TrimPrefix(arg, "--conn=")
+		} else if strings.HasPrefix(arg, "-") {
+			return fmt.Errorf("unknown flag %q, %s", arg, usage)
+		} else {
+			paths = append(paths, arg)
+		}
+	}
+	if len(paths) == 0 {
+		paths = []string{SeedPath}
+	}
+
+	s, err := load(truncate, true, connName, env, paths)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range s.modelNames() {
+		fmt.Fprintf(out, "seeded %d %s\n", len(s.records[name]), name)
+	}
+
+	return nil
+}
+
+// Protected reports whether env is one of the ProtectedEnvironments, ignoring case, EX: Production
+func Protected(env string) bool {
+	for _, p := range ProtectedEnvironments {
+		if strings.EqualFold(env, p) {
+			return true
+		}
+	}
+
+	return false
+}
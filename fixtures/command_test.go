@@ -0,0 +1,57 @@
+package fixtures
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Command", func() {
+	var out *bytes.Buffer
+
+	BeforeEach(func() {
+		resetMemory()
+		out = &bytes.Buffer{}
+	})
+
+	It("should require the db:seed command", func() {
+		Ω(Command([]string{}, out)).Should(MatchError(usage))
+		Ω(Command([]string{"db:drop"}, out)).Should(MatchError(usage))
+		Ω(Command([]string{"db:seed", "--force"}, out)).Should(HaveOccurred())
+	})
+
+	It("should seed the default path", func() {
+		defer func(path string) { SeedPath = path }(SeedPath)
+		SeedPath = "testdata/seeds"
+
+		Ω(Command([]string{"db:seed"}, out)).Should(Succeed())
+		Ω(out.String()).Should(Equal("seeded 2 dealers\n"))
+		Ω(truncated).Should(BeEmpty())
+	})
+
+	It("should truncate when asked", func() {
+		Ω(Command([]string{"db:seed", "--truncate", "testdata"}, out)).Should(Succeed())
+		Ω(out.String()).Should(Equal("seeded 2 dealers\nseeded 3 vehicles\n"))
+		Ω(truncated).Should(HaveLen(2))
+	})
+
+	It("should refuse to seed a protected environment", func() {
+		dir, err := ioutil.TempDir("", "seeds")
+		Ω(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+		Ω(ioutil.WriteFile(filepath.Join(dir, "ledgers.yml"), []byte("first:\n  id: one\n"), 0644)).Should(Succeed())
+
+		Ω(Command([]string{"db:seed", dir}, out)).Should(MatchError("refusing to load ledgers into the production environment"))
+		Ω(saved).Should(BeEmpty())
+	})
+
+	It("should match protected environments whatever their case", func() {
+		Ω(Protected("Production")).Should(BeTrue())
+		Ω(Protected("PROD")).Should(BeTrue())
+		Ω(Protected("staging")).Should(BeFalse())
+	})
+})
@@ -0,0 +1,390 @@
+// Package fixtures loads test and demo data from YAML or JSON files into any adapter.
+// Each file is named by its model, EX: vehicles.yml, and maps labels to attributes:
+//
+//	civic:
+//	  make: Honda
+//	  dealer: hondaland   # a reference, saved as dealer_id
+//	  sold_at: <%= daysAgo 3 %>
+//
+// An attribute is a reference when the model has no field by its name, but has one by
+// its name plus _id.  Its value is the label of another fixture, EX: hondaland or dealers.hondaland,
+// which is saved first and whose key is used.
+//
+// Files are templates with <%= %> delimiters and date helpers, see Funcs.
+// String keyed models use the label as their key unless an id is given, so keys are stable, EX: civic.
+package fixtures
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	goar "github.com/obieq/goar"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	models      = map[string]reflect.Type{}
+	modelsMutex sync.RWMutex
+)
+
+// Register adds the models fixture files can be loaded into, keyed by their model name
+func Register(ari ...goar.ActiveRecordInterfacer) {
+	modelsMutex.Lock()
+	defer modelsMutex.Unlock()
+
+	for _, m := range ari {
+		models[goar.ToAR(m).ModelName()] = reflect.TypeOf(m).Elem()
+	}
+}
+
+func model(name string) (goar.ActiveRecordInterfacer, error) {
+	modelsMutex.RLock()
+	defer modelsMutex.RUnlock()
+
+	t, ok := models[name]
+	if !ok {
+		return nil, fmt.Errorf("no model registered for fixtures: %s", name)
+	}
+
+	return goar.ToAR(reflect.New(t).Interface().(goar.ActiveRecordInterfacer)), nil
+}
+
+// Set is the loaded fixtures, by model name and label
+type Set struct {
+	records  map[string]map[string]goar.ActiveRecordInterfacer
+	pending  map[string]map[string]map[string]interface{} // attributes not saved yet
+	visiting map[string]bool
+	connName string // overrides the models' connection when set, see goar.UseConnection
+	env      string
+}
+
+// Load truncates the models of the fixture files, then saves their fixtures, EX:
+//
+//	BeforeEach(func() {
+//		set, err := fixtures.Load("testdata/fixtures")
+//		Ω(err).NotTo(HaveOccurred())
+//		civic = set.Get("vehicles", "civic").(*Vehicle)
+//	})
+//
+// A path is a fixture file or a directory of them.  Like db:seed, it refuses models connected
+// to a ProtectedEnvironments environment, so a suite pointed at production can't truncate it.
+func Load(paths ...string) (*Set, error) {
+	return load(true, true, "", "", paths)
+}
+
+// Seed saves the fixtures without truncating their models first
+func Seed(paths ...string) (*Set, error) {
+	return load(false, false, "", "", paths)
+}
+
+// load refuses models connected to a ProtectedEnvironments environment when protect is set.
+// A blank connName or env keeps the models' own.
+func load(truncate bool, protect bool, connName string, env string, paths []string) (*Set, error) {
+	files, err := fixtureFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Set{
+		records:  map[string]map[string]goar.ActiveRecordInterfacer{},
+		pending:  map[string]map[string]map[string]interface{}{},
+		visiting: map[string]bool{},
+		connName: connName,
+		env:      env,
+	}
+	for _, file := range files {
+		if err = s.read(file); err != nil {
+			return nil, err
+		}
+	}
+
+	names := s.modelNames()
+	for _, name := range names {
+		m, err := s.model(name)
+		if err != nil {
+			return nil, err
+		} else if _, env := goar.ResolveConnection(m); protect && Protected(env) {
+			return nil, fmt.Errorf("refusing to load %s into the %s environment", name, env)
+		}
+	}
+
+	if truncate {
+		for _, name := range names {
+			m, _ := s.model(name)
+			if _, err = m.Truncate(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, name := range names {
+		for _, label := range sortedLabels(s.pending[name]) {
+			if err = s.save(name, label); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return s, nil
+}
+
+// model returns a blank instance of the model, connected to the set's connection
+func (s *Set) model(name string) (goar.ActiveRecordInterfacer, error) {
+	m, err := model(name)
+	if err != nil || s.connName == "" && s.env == "" {
+		return m, err
+	}
+
+	if c, ok := m.(interface {
+		UseConnection(connName string, env string) *goar.ActiveRecord
+	}); ok {
+		c.UseConnection(s.connName, s.env)
+	}
+
+	return m, nil
+}
+
+// Get returns the saved fixture, or nil
+func (s *Set) Get(model string, label string) goar.ActiveRecordInterfacer {
+	return s.records[model][label]
+}
+
+// Key returns the saved fixture's ID, or nil
+func (s *Set) Key(model string, label string) interface{} {
+	if r := s.Get(model, label); r != nil {
+		return key(r)
+	}
+
+	return nil
+}
+
+func fixtureFiles(paths []string) ([]string, error) {
+	files := []string{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		} else if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		for _, pattern := range []string{"*.yml", "*.yaml", "*.json"} {
+			matches, _ := filepath.Glob(filepath.Join(path, pattern))
+			files = append(files, matches...)
+		}
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// read renders the file's template, then parses it into the pending fixtures of its model
+func (s *Set) read(file string) error {
+	text, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if text, err = render(file, text); err != nil {
+		return err
+	}
+
+	fixtures := map[string]map[string]interface{}{}
+	switch ext := filepath.Ext(file); ext {
+	case ".yml", ".yaml":
+		parsed := map[string]map[interface{}]interface{}{}
+		if err = yaml.Unmarshal(text, &parsed); err == nil {
+			for label, attributes := range parsed {
+				fixtures[label] = stringKeys(attributes).(map[string]interface{})
+			}
+		}
+	case ".json":
+		err = json.Unmarshal(text, &fixtures)
+	default:
+		err = fmt.Errorf("unknown fixture format: %s", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("could not parse %s: %v", file, err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	if s.pending[name] == nil {
+		s.pending[name] = map[string]map[string]interface{}{}
+	}
+	for label, attributes := range fixtures {
+		if attributes == nil {
+			attributes = map[string]interface{}{}
+		}
+		s.pending[name][label] = attributes
+	}
+
+	return nil
+}
+
+// save saves the fixture after the fixtures it references
+func (s *Set) save(name string, label string) error {
+	if s.records[name][label] != nil {
+		return nil
+	}
+
+	id := name + "." + label
+	if s.visiting[id] {
+		return fmt.Errorf("circular fixture reference: %s", id)
+	}
+	s.visiting[id] = true
+	defer delete(s.visiting, id)
+
+	m, err := s.model(name)
+	if err != nil {
+		return err
+	}
+
+	fields := fieldNames(reflect.TypeOf(m).Elem())
+	attributes := map[string]interface{}{}
+	for attribute, value := range s.pending[name][label] {
+		reference, isString := value.(string)
+		if fields[strings.ToLower(attribute)] || !fields[strings.ToLower(attribute)+"_id"] || !isString {
+			attributes[attribute] = value
+			continue
+		}
+
+		refName, refLabel, err := s.resolve(reference)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %v", id, attribute, err)
+		}
+		if err = s.save(refName, refLabel); err != nil {
+			return err
+		}
+		attributes[attribute+"_id"] = key(s.records[refName][refLabel])
+	}
+
+	if _, ok := attributes["id"]; !ok {
+		m.SetKey(label)
+	}
+	if err = assign(m, attributes); err != nil {
+		return fmt.Errorf("%s: %v", id, err)
+	}
+
+	if success, err := m.Save(); err != nil {
+		return fmt.Errorf("could not save %s: %v", id, err)
+	} else if !success {
+		return fmt.Errorf("could not save %s: %v", id, m.Errors())
+	}
+
+	if s.records[name] == nil {
+		s.records[name] = map[string]goar.ActiveRecordInterfacer{}
+	}
+	s.records[name][label] = m
+
+	return nil
+}
+
+// resolve finds the fixture a reference labels, EX: hondaland or dealers.hondaland
+func (s *Set) resolve(reference string) (string, string, error) {
+	if i := strings.Index(reference, "."); i > 0 {
+		if _, ok := s.pending[reference[:i]][reference[i+1:]]; ok {
+			return reference[:i], reference[i+1:], nil
+		}
+	}
+
+	found := []string{}
+	for _, name := range s.modelNames() {
+		if _, ok := s.pending[name][reference]; ok {
+			found = append(found, name)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return "", "", fmt.Errorf("unknown fixture %q", reference)
+	case 1:
+		return found[0], reference, nil
+	}
+
+	return "", "", fmt.Errorf("ambiguous fixture %q, qualify it with its model, EX: %s.%s", reference, found[0], reference)
+}
+
+func (s *Set) modelNames() []string {
+	names := []string{}
+	for name := range s.pending {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// assign sets the attributes via json, so they're matched to fields like the adapters match them
+func assign(m goar.ActiveRecordInterfacer, attributes map[string]interface{}) error {
+	b, err := json.Marshal(attributes)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, m)
+}
+
+// key returns the ID field, which the adapters set when the record is saved
+func key(m goar.ActiveRecordInterfacer) interface{} {
+	if f := reflect.ValueOf(m).Elem().FieldByName("ID"); f.IsValid() {
+		return f.Interface()
+	}
+
+	return nil
+}
+
+// fieldNames returns the lowercased json and struct field names, including embedded ones
+func fieldNames(t reflect.Type) map[string]bool {
+	names := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			for name := range fieldNames(f.Type) {
+				names[name] = true
+			}
+			continue
+		}
+
+		names[strings.ToLower(f.Name)] = true
+		if name := strings.Split(f.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+			names[strings.ToLower(name)] = true
+		}
+	}
+
+	return names
+}
+
+// stringKeys converts yaml's maps into ones json can marshal
+func stringKeys(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for k, value := range v {
+			m[fmt.Sprint(k)] = stringKeys(value)
+		}
+		return m
+	case []interface{}:
+		for i := range v {
+			v[i] = stringKeys(v[i])
+		}
+	}
+
+	return v
+}
+
+func sortedLabels(fixtures map[string]map[string]interface{}) []string {
+	labels := []string{}
+	for label := range fixtures {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	return labels
+}
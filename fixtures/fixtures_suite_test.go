@@ -0,0 +1,104 @@
+package fixtures
+
+import (
+	"testing"
+	"time"
+
+	goar "github.com/obieq/goar"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestFixtures(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Fixtures Suite")
+}
+
+// saved and truncated are the in memory "database" of the fixture models
+var (
+	saved     = map[string][]goar.ActiveRecordInterfacer{}
+	truncated = map[string]int{}
+	nextID    = 0
+)
+
+type memoryModel struct {
+	goar.ActiveRecord
+}
+
+func (m *memoryModel) All(results interface{}, opts map[string]interface{}) error { return nil }
+func (m *memoryModel) Find(id interface{}, out interface{}) error                 { return nil }
+func (m *memoryModel) DBConnectionName() string                                   { return "default" }
+func (m *memoryModel) DBConnectionEnvironment() string                            { return "test" }
+func (m *memoryModel) Validate()                                                  {}
+func (m *memoryModel) DbDelete() error                                            { return nil }
+func (m *memoryModel) DbSearch(results interface{}) error                         { return nil }
+
+func (m *memoryModel) DbSave() error {
+	saved[m.ModelName()] = append(saved[m.ModelName()], m.Self())
+	return nil
+}
+
+func (m *memoryModel) Truncate() (int, error) {
+	truncated[m.ModelName()]++
+	n := len(saved[m.ModelName()])
+	saved[m.ModelName()] = nil
+	return n, nil
+}
+
+// FixtureDealer has an auto incremented key, like the sql adapters
+type FixtureDealer struct {
+	memoryModel
+	ID   int    `json:"id,omitempty"`
+	Name string `json:"name"`
+}
+
+func (m *FixtureDealer) SetKey(key string) {}
+
+func (m *FixtureDealer) DbSave() error {
+	nextID++
+	m.ID = nextID
+	return m.memoryModel.DbSave()
+}
+
+func (m *FixtureDealer) CustomModelName() string { return "dealers" }
+
+// FixtureVehicle has a string key, like the nosql adapters
+type FixtureVehicle struct {
+	memoryModel
+	ID       string    `json:"id,omitempty"`
+	Make     string    `json:"make"`
+	Year     int       `json:"year"`
+	DealerID int       `json:"dealer_id"`
+	SoldAt   time.Time `json:"sold_at"`
+	Options  []string  `json:"options"`
+}
+
+func (m *FixtureVehicle) SetKey(key string) { m.ID = key }
+
+func (m *FixtureVehicle) CustomModelName() string { return "vehicles" }
+
+func (m *FixtureVehicle) Validate() {
+	if m.Make == "" {
+		m.AddError("make", goar.ErrRequired, nil)
+	}
+}
+
+// FixtureLedger is connected to production, so it can't be seeded
+type FixtureLedger struct {
+	memoryModel
+	ID string `json:"id,omitempty"`
+}
+
+func (m *FixtureLedger) SetKey(key string)               { m.ID = key }
+func (m *FixtureLedger) CustomModelName() string         { return "ledgers" }
+func (m *FixtureLedger) DBConnectionEnvironment() string { return "production" }
+
+func resetMemory() {
+	saved = map[string][]goar.ActiveRecordInterfacer{}
+	truncated = map[string]int{}
+	nextID = 0
+}
+
+var _ = BeforeSuite(func() {
+	Register(&FixtureDealer{}, &FixtureVehicle{}, &FixtureLedger{})
+})
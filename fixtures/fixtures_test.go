@@ -0,0 +1,117 @@
+package fixtures
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fixtures", func() {
+
+	BeforeEach(func() {
+		resetMemory()
+		Now = func() time.Time { return time.Date(2015, 10, 14, 9, 30, 0, 0, time.UTC) }
+	})
+
+	AfterEach(func() {
+		Now = time.Now
+	})
+
+	// fixtureFile writes a fixture file to a temp directory
+	fixtureFile := func(name string, text string) string {
+		dir, err := ioutil.TempDir("", "fixtures")
+		Ω(err).NotTo(HaveOccurred())
+		path := filepath.Join(dir, name)
+		Ω(ioutil.WriteFile(path, []byte(text), 0644)).Should(Succeed())
+		return path
+	}
+
+	It("should load a directory of yaml and json fixtures", func() {
+		set, err := Load("testdata")
+		Ω(err).NotTo(HaveOccurred())
+		Ω(saved["dealers"]).Should(HaveLen(2))
+		Ω(saved["vehicles"]).Should(HaveLen(3))
+
+		civic := set.Get("vehicles", "civic").(*FixtureVehicle)
+		Ω(civic.Make).Should(Equal("Honda"))
+		Ω(civic.Year).Should(Equal(2015))
+		Ω(civic.Options).Should(Equal([]string{"sunroof"}))
+		Ω(set.Get("vehicles", "missing")).Should(BeNil())
+	})
+
+	It("should truncate the models before loading", func() {
+		_, err := Load("testdata")
+		Ω(err).NotTo(HaveOccurred())
+		_, err = Load("testdata")
+		Ω(err).NotTo(HaveOccurred())
+
+		Ω(truncated).Should(Equal(map[string]int{"dealers": 2, "vehicles": 2}))
+		Ω(saved["vehicles"]).Should(HaveLen(3))
+	})
+
+	It("should refuse to truncate a protected environment", func() {
+		_, err := Load(fixtureFile("ledgers.yml", "first:\n  id: one\n"))
+		Ω(err).Should(MatchError("refusing to load ledgers into the production environment"))
+		Ω(truncated).Should(BeEmpty())
+		Ω(saved).Should(BeEmpty())
+	})
+
+	It("should not truncate seeded models", func() {
+		_, err := Seed("testdata/dealers.yml")
+		Ω(err).NotTo(HaveOccurred())
+		Ω(truncated).Should(BeEmpty())
+		Ω(saved["dealers"]).Should(HaveLen(2))
+	})
+
+	It("should key string keyed models by their label, unless an id is given", func() {
+		set, err := Load("testdata")
+		Ω(err).NotTo(HaveOccurred())
+		Ω(set.Key("vehicles", "civic")).Should(Equal("civic"))
+		Ω(set.Key("vehicles", "accord")).Should(Equal("honda-accord"))
+		Ω(set.Key("vehicles", "missing")).Should(BeNil())
+	})
+
+	It("should save referenced fixtures first and use their keys", func() {
+		set, err := Load("testdata")
+		Ω(err).NotTo(HaveOccurred())
+		Ω(set.Get("vehicles", "civic").(*FixtureVehicle).DealerID).Should(Equal(set.Key("dealers", "hondaland")))
+		Ω(set.Get("vehicles", "camry").(*FixtureVehicle).DealerID).Should(Equal(set.Key("dealers", "toyotatown")))
+		Ω(set.Get("vehicles", "accord").(*FixtureVehicle).DealerID).Should(BeZero())
+	})
+
+	It("should render dates", func() {
+		set, err := Load("testdata")
+		Ω(err).NotTo(HaveOccurred())
+		Ω(set.Get("vehicles", "civic").(*FixtureVehicle).SoldAt).Should(Equal(time.Date(2015, 10, 11, 9, 30, 0, 0, time.UTC)))
+		Ω(set.Get("vehicles", "camry").(*FixtureVehicle).SoldAt).Should(Equal(time.Date(2015, 10, 14, 0, 0, 0, 0, time.UTC)))
+	})
+
+	It("should fail on unknown references", func() {
+		path := fixtureFile("vehicles.yml", "civic:\n  make: Honda\n  dealer: nobody\n")
+		defer os.RemoveAll(filepath.Dir(path))
+
+		_, err := Load(path)
+		Ω(err).Should(MatchError(`vehicles.civic.dealer: unknown fixture "nobody"`))
+	})
+
+	It("should fail on invalid fixtures", func() {
+		path := fixtureFile("vehicles.yml", "civic:\n  year: 2015\n")
+		defer os.RemoveAll(filepath.Dir(path))
+
+		_, err := Load(path)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(HavePrefix("could not save vehicles.civic"))
+	})
+
+	It("should fail on unregistered models", func() {
+		path := fixtureFile("owners.yml", "alice:\n  name: Alice\n")
+		defer os.RemoveAll(filepath.Dir(path))
+
+		_, err := Load(path)
+		Ω(err).Should(MatchError("no model registered for fixtures: owners"))
+	})
+})
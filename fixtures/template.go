@@ -0,0 +1,51 @@
+package fixtures
+
+import (
+	"bytes"
+	"path/filepath"
+	"text/template"
+	"time"
+)
+
+// Now is the time the date helpers are relative to, which tests can freeze
+var Now = time.Now
+
+// Funcs are the helpers fixture files can call.  Each returns an RFC 3339 timestamp, EX:
+//
+//	sold_at: <%= daysAgo 3 %>
+//	listed_at: <%= hoursFromNow 12 %>
+//	built_at: <%= date "2015-10-14" %>
+//
+// NOTE: a json file is rendered before it's parsed, so quote arguments with backticks, EX: <%= date `2015-10-14` %>
+var Funcs = template.FuncMap{
+	"now":          func() string { return stamp(Now()) },
+	"today":        func() string { return stamp(midnight(Now())) },
+	"daysAgo":      func(n int) string { return stamp(Now().AddDate(0, 0, -n)) },
+	"daysFromNow":  func(n int) string { return stamp(Now().AddDate(0, 0, n)) },
+	"hoursAgo":     func(n int) string { return stamp(Now().Add(-time.Duration(n) * time.Hour)) },
+	"hoursFromNow": func(n int) string { return stamp(Now().Add(time.Duration(n) * time.Hour)) },
+	"date": func(value string) (string, error) {
+		t, err := time.Parse("2006-01-02", value)
+		return stamp(t), err
+	},
+}
+
+func render(file string, text []byte) ([]byte, error) {
+	t, err := template.New(filepath.Base(file)).Delims("<%=", "%>").Funcs(Funcs).Parse(string(text))
+	if err != nil {
+		return nil, err
+	}
+
+	out := &bytes.Buffer{}
+	err = t.Execute(out, nil)
+	return out.Bytes(), err
+}
+
+func stamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func midnight(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
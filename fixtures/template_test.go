@@ -0,0 +1,36 @@
+package fixtures
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Template", func() {
+
+	BeforeEach(func() {
+		Now = func() time.Time { return time.Date(2015, 10, 14, 9, 30, 0, 0, time.UTC) }
+	})
+
+	AfterEach(func() {
+		Now = time.Now
+	})
+
+	It("should render the date helpers", func() {
+		text, err := render("vehicles.yml", []byte(`<%= now %> <%= today %> <%= daysFromNow 1 %> <%= hoursAgo 2 %> <%= hoursFromNow 2 %>`))
+		Ω(err).NotTo(HaveOccurred())
+		Ω(string(text)).Should(Equal("2015-10-14T09:30:00Z 2015-10-14T00:00:00Z 2015-10-15T09:30:00Z 2015-10-14T07:30:00Z 2015-10-14T11:30:00Z"))
+	})
+
+	It("should leave go template delimiters alone", func() {
+		text, err := render("vehicles.yml", []byte("notes: {{ not a template }}"))
+		Ω(err).NotTo(HaveOccurred())
+		Ω(string(text)).Should(Equal("notes: {{ not a template }}"))
+	})
+
+	It("should fail on invalid dates", func() {
+		_, err := render("vehicles.yml", []byte(`<%= date "10/14/2015" %>`))
+		Ω(err).Should(HaveOccurred())
+	})
+})
@@ -0,0 +1,176 @@
+package goar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HealthChecker checks one of an adapter's connections, EX: postgres pings it.
+// The adapters register theirs when they're imported.
+type HealthChecker func(ctx context.Context, connName string, env string) error
+
+var (
+	healthCheckers      = map[string]HealthChecker{}
+	healthCheckersMutex sync.RWMutex
+)
+
+// RegisterHealthCheck sets the adapter's checker, EX: goar.RegisterHealthCheck(goar.RETHINKDB, healthCheck)
+func RegisterHealthCheck(adapter string, check HealthChecker) {
+	healthCheckersMutex.Lock()
+	defer healthCheckersMutex.Unlock()
+
+	healthCheckers[adapter] = check
+}
+
+// the statuses of a connection
+const (
+	HealthOK        = "ok"
+	HealthFailed    = "failed"
+	HealthUnchecked = "unchecked" // the adapter has no health check, or isn't imported
+)
+
+// ConnectionHealth is the status of one connection
+type ConnectionHealth struct {
+	Adapter    string        `json:"adapter"`
+	Connection string        `json:"connection"`
+	Status     string        `json:"status"`
+	Error      string        `json:"error,omitempty"`
+	Duration   time.Duration `json:"duration_ms"`
+}
+
+// HealthReport is the status of every connection in the environment
+type HealthReport struct {
+	Environment string             `json:"environment"`
+	Healthy     bool               `json:"healthy"`
+	Connections []ConnectionHealth `json:"connections"`
+}
+
+// MarshalJSON reports the duration in milliseconds
+func (h ConnectionHealth) MarshalJSON() ([]byte, error) {
+	type connectionHealth ConnectionHealth
+	c := connectionHealth(h)
+	c.Duration = h.Duration / time.Millisecond
+
+	return json.Marshal(c)
+}
+
+// HealthCheck checks every connection in the config's environment at once, waiting until
+// they're done or ctx is, EX: Ping, rethinkdb's databases, couchbase's bucket stats or dynamodb's ListTables.
+// It's healthy when none failed.
+func HealthCheck(ctx context.Context) *HealthReport {
+	c := Configuration()
+	report := &HealthReport{Environment: c.Environment, Healthy: true}
+
+	type connection struct{ adapter, connName string }
+	connections := []connection{}
+	add := func(adapter string, connKey string) {
+		if prefix := c.Environment + "_" + adapter + "_"; strings.HasPrefix(connKey, prefix) {
+			connections = append(connections, connection{adapter, strings.TrimPrefix(connKey, prefix)})
+		}
+	}
+	for connKey := range c.MSSQLDBs {
+		add(MSSQL, connKey)
+	}
+	for connKey := range c.PostgresqlDBs {
+		add(POSTGRESQL, connKey)
+	}
+	for connKey := range c.RethinkDBs {
+		add(RETHINKDB, connKey)
+	}
+	for connKey := range c.CouchbaseDBs {
+		add(COUCHBASE, connKey)
+	}
+	for connKey := range c.DynamoDBs {
+		add(DYNAMODB, connKey)
+	}
+	for connKey := range c.OrchestrateDBs {
+		add(ORCHESTRATE, connKey)
+	}
+	for connKey := range c.CloudantDBs {
+		add(CLOUDANT, connKey)
+	}
+
+	report.Connections = make([]ConnectionHealth, len(connections))
+	var wg sync.WaitGroup
+	for i, conn := range connections {
+		wg.Add(1)
+		go func(i int, conn connection) {
+			defer wg.Done()
+			report.Connections[i] = checkHealth(ctx, conn.adapter, conn.connName, c.Environment)
+		}(i, conn)
+	}
+	wg.Wait()
+
+	sort.Slice(report.Connections, func(i, j int) bool {
+		a, b := report.Connections[i], report.Connections[j]
+		return a.Adapter < b.Adapter || a.Adapter == b.Adapter && a.Connection < b.Connection
+	})
+	for _, h := range report.Connections {
+		if h.Status == HealthFailed {
+			report.Healthy = false
+		}
+	}
+
+	return report
+}
+
+// checkHealth runs the adapter's check, which is abandoned when ctx is done first.
+// NOTE: the adapters panic when they can't connect, so the panic is the error.
+func checkHealth(ctx context.Context, adapter string, connName string, env string) ConnectionHealth {
+	h := ConnectionHealth{Adapter: adapter, Connection: connName, Status: HealthUnchecked}
+
+	healthCheckersMutex.RLock()
+	check, ok := healthCheckers[adapter]
+	healthCheckersMutex.RUnlock()
+	if !ok {
+		return h
+	}
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("%v", r)
+			}
+		}()
+		done <- check(ctx, connName, env)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	h.Duration, h.Status = time.Since(start), HealthOK
+	if err != nil {
+		h.Status, h.Error = HealthFailed, strings.TrimSpace(err.Error())
+	}
+
+	return h
+}
+
+// HealthHandler serves the HealthReport as json, with a 503 status when it isn't healthy, EX:
+//
+//	http.Handle("/healthz", goar.HealthHandler(5*time.Second))
+func HealthHandler(timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		report := HealthCheck(ctx)
+		w.Header().Set("Content-Type", "application/json")
+		if !report.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+	})
+}
@@ -0,0 +1,90 @@
+package migration
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const usage = "usage: migrate up [version] | down [steps] | status | redo [steps]"
+
+// Command runs a migrate command against a connection, so an app can build
+// its own migrate binary from its registered migrations, EX:
+//
+//	import _ "myapp/migrations"
+//
+//	func main() {
+//		d := postgres.NewMigrationDriver("default", os.Getenv("GOAR_ENV"))
+//		if err := migration.Command(d, os.Args[1:], os.Stdout); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+func Command(d Driver, args []string, out io.Writer) error {
+	if len(args) == 0 || len(args) > 2 {
+		return fmt.Errorf(usage)
+	}
+
+	var arg int64
+	if len(args) == 2 {
+		var err error
+		if arg, err = strconv.ParseInt(args[1], 10, 64); err != nil || arg < 0 {
+			return fmt.Errorf("invalid argument %q, %s", args[1], usage)
+		}
+	}
+
+	switch args[0] {
+	case "up":
+		migrations, err := Up(d, arg)
+		report(out, "applied", migrations)
+		return err
+	case "down":
+		migrations, err := Down(d, steps(arg))
+		report(out, "rolled back", migrations)
+		return err
+	case "redo":
+		migrations, err := Redo(d, steps(arg))
+		report(out, "reapplied", migrations)
+		return err
+	case "status":
+		if len(args) > 1 {
+			return fmt.Errorf(usage)
+		}
+		return printStatus(d, out)
+	}
+
+	return fmt.Errorf("unknown command %q, %s", args[0], usage)
+}
+
+func steps(arg int64) int {
+	if arg == 0 {
+		return 1
+	}
+	return int(arg)
+}
+
+func report(out io.Writer, action string, migrations []*Migration) {
+	for _, m := range migrations {
+		fmt.Fprintf(out, "%s %s\n", action, m)
+	}
+}
+
+func printStatus(d Driver, out io.Writer) error {
+	statuses, err := StatusOf(d)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "%-8s %-16s %s\n", "Status", "Version", "Name")
+	for _, s := range statuses {
+		status, name := "down", s.Name
+		if s.Applied {
+			status = "up"
+		}
+		if name == "" {
+			name = "** NO FILE **"
+		}
+		fmt.Fprintf(out, "%-8s %-16d %s\n", status, s.Version, name)
+	}
+
+	return nil
+}
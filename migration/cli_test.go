@@ -0,0 +1,54 @@
+package migration
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Command", func() {
+
+	var (
+		d   *memoryDriver
+		out *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		resetRegistry()
+		d, out = newMemoryDriver(), &bytes.Buffer{}
+		Register(1, "create_vehicles", createTable("vehicles"), dropTable("vehicles"))
+		Register(2, "create_dealers", createTable("dealers"), dropTable("dealers"))
+	})
+
+	It("should migrate up and down", func() {
+		Ω(Command(d, []string{"up"}, out)).Should(Succeed())
+		Ω(out.String()).Should(Equal("applied 1_create_vehicles\napplied 2_create_dealers\n"))
+
+		out.Reset()
+		Ω(Command(d, []string{"down"}, out)).Should(Succeed())
+		Ω(out.String()).Should(Equal("rolled back 2_create_dealers\n"))
+
+		out.Reset()
+		Ω(Command(d, []string{"redo"}, out)).Should(Succeed())
+		Ω(out.String()).Should(Equal("reapplied 1_create_vehicles\n"))
+	})
+
+	It("should migrate up to a version", func() {
+		Ω(Command(d, []string{"up", "1"}, out)).Should(Succeed())
+		Ω(d.ledger).Should(Equal([]int64{1}))
+	})
+
+	It("should print the status", func() {
+		Command(d, []string{"up", "1"}, &bytes.Buffer{})
+		Ω(Command(d, []string{"status"}, out)).Should(Succeed())
+		Ω(out.String()).Should(MatchRegexp(`up\s+1\s+create_vehicles\n`))
+		Ω(out.String()).Should(MatchRegexp(`down\s+2\s+create_dealers\n`))
+	})
+
+	It("should reject invalid commands", func() {
+		Ω(Command(d, []string{}, out)).ShouldNot(Succeed())
+		Ω(Command(d, []string{"sideways"}, out)).ShouldNot(Succeed())
+		Ω(Command(d, []string{"down", "two"}, out)).ShouldNot(Succeed())
+	})
+})
@@ -1,5 +1,6 @@
 package migration
 
+// Migrator is implemented by each adapter's migration driver
 type Migrator interface {
 	CreateDb(dbName string) error
 	DropDb(dbName string) error
@@ -7,3 +8,20 @@ type Migrator interface {
 	DropTable(tableName string) error
 	AddIndex(tableName string, fields []string, opts map[string]interface{}) error
 }
+
+// LedgerName is the table, or collection, that records a connection's applied migrations
+const LedgerName = "schema_migrations"
+
+// Ledger records which migrations have been applied to a connection
+type Ledger interface {
+	EnsureLedger() error // creates the ledger if it doesn't exist yet
+	AppliedVersions() ([]int64, error)
+	RecordVersion(version int64) error
+	RemoveVersion(version int64) error
+}
+
+// Driver runs migrations against one connection, EX: rethinkdb.NewMigrationDriver("aws", "prod")
+type Driver interface {
+	Migrator
+	Ledger
+}
@@ -0,0 +1,82 @@
+package migration
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestMigration(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Migration Suite")
+}
+
+// memoryDriver records the tables it creates and the versions it applies
+type memoryDriver struct {
+	tables  map[string]bool
+	ledger  []int64
+	ensured bool
+}
+
+func newMemoryDriver() *memoryDriver {
+	return &memoryDriver{tables: map[string]bool{}}
+}
+
+func (d *memoryDriver) CreateDb(dbName string) error { return nil }
+func (d *memoryDriver) DropDb(dbName string) error   { return nil }
+
+func (d *memoryDriver) CreateTable(tableName string) error {
+	if d.tables[tableName] {
+		return errors.New("table already exists: " + tableName)
+	}
+	d.tables[tableName] = true
+	return nil
+}
+
+func (d *memoryDriver) DropTable(tableName string) error {
+	delete(d.tables, tableName)
+	return nil
+}
+
+func (d *memoryDriver) AddIndex(tableName string, fields []string, opts map[string]interface{}) error {
+	return nil
+}
+
+func (d *memoryDriver) EnsureLedger() error {
+	d.ensured = true
+	return nil
+}
+
+func (d *memoryDriver) AppliedVersions() ([]int64, error) {
+	return append([]int64{}, d.ledger...), nil
+}
+
+func (d *memoryDriver) RecordVersion(version int64) error {
+	d.ledger = append(d.ledger, version)
+	return nil
+}
+
+func (d *memoryDriver) RemoveVersion(version int64) error {
+	for i, v := range d.ledger {
+		if v == version {
+			d.ledger = append(d.ledger[:i], d.ledger[i+1:]...)
+		}
+	}
+	return nil
+}
+
+func createTable(name string) func(d Driver) error {
+	return func(d Driver) error { return d.CreateTable(name) }
+}
+
+func dropTable(name string) func(d Driver) error {
+	return func(d Driver) error { return d.DropTable(name) }
+}
+
+func resetRegistry() {
+	registryMutex.Lock()
+	registry = map[int64]*Migration{}
+	registryMutex.Unlock()
+}
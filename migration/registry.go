@@ -0,0 +1,72 @@
+package migration
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+)
+
+// Migration is a versioned schema change.  Versions are usually timestamps, EX: 20151014093000
+type Migration struct {
+	Version int64
+	Name    string
+	Up      func(d Driver) error
+	Down    func(d Driver) error // nil if the migration can't be rolled back
+}
+
+func (m *Migration) String() string {
+	return fmt.Sprintf("%d_%s", m.Version, m.Name)
+}
+
+var (
+	registry      = map[int64]*Migration{}
+	registryMutex sync.RWMutex
+)
+
+// Register adds a migration, typically from the init() of a migration file, EX:
+//
+//	func init() {
+//		migration.Register(20151014093000, "create_vehicles", func(d migration.Driver) error {
+//			return d.CreateTable("vehicles")
+//		}, func(d migration.Driver) error {
+//			return d.DropTable("vehicles")
+//		})
+//	}
+//
+// Drivers expose adapter specific methods, EX: Exec for SQL, via a type assertion.
+func Register(version int64, name string, up func(d Driver) error, down func(d Driver) error) {
+	if version <= 0 {
+		log.Panicf("migration %s: version must be positive", name)
+	} else if up == nil {
+		log.Panicf("migration %d_%s: up cannot be nil", version, name)
+	}
+
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if m, found := registry[version]; found {
+		log.Panicf("migration %d_%s: version is already registered by %s", version, name, m)
+	}
+	registry[version] = &Migration{Version: version, Name: name, Up: up, Down: down}
+}
+
+// Registered returns every registered migration, oldest first
+func Registered() []*Migration {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	migrations := []*Migration{}
+	for _, m := range registry {
+		migrations = append(migrations, m)
+	}
+	sort.Sort(byVersion(migrations))
+
+	return migrations
+}
+
+type byVersion []*Migration
+
+func (a byVersion) Len() int           { return len(a) }
+func (a byVersion) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byVersion) Less(i, j int) bool { return a[i].Version < a[j].Version }
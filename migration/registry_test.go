@@ -0,0 +1,31 @@
+package migration
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Registry", func() {
+
+	BeforeEach(resetRegistry)
+
+	It("should return migrations oldest first", func() {
+		Register(20151015000000, "add_dealers", createTable("dealers"), nil)
+		Register(20151014000000, "create_vehicles", createTable("vehicles"), dropTable("vehicles"))
+
+		migrations := Registered()
+		Ω(migrations).Should(HaveLen(2))
+		Ω(migrations[0].String()).Should(Equal("20151014000000_create_vehicles"))
+		Ω(migrations[1].Version).Should(Equal(int64(20151015000000)))
+	})
+
+	It("should panic on a duplicate version", func() {
+		Register(1, "create_vehicles", createTable("vehicles"), nil)
+		Ω(func() { Register(1, "create_dealers", createTable("dealers"), nil) }).Should(Panic())
+	})
+
+	It("should panic on an invalid migration", func() {
+		Ω(func() { Register(0, "create_vehicles", createTable("vehicles"), nil) }).Should(Panic())
+		Ω(func() { Register(1, "create_vehicles", nil, nil) }).Should(Panic())
+	})
+})
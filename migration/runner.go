@@ -0,0 +1,164 @@
+package migration
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Status describes a migration that is registered, applied, or both
+type Status struct {
+	Version int64
+	Name    string // blank if the migration was applied but is no longer registered
+	Applied bool
+}
+
+// Up applies every pending migration up to and including the target version.
+// A target of 0 applies them all.  Returns the migrations that were applied.
+// NOTE: migrations aren't wrapped in a transaction, so a failed migration may
+// be partially applied, and isn't recorded in the ledger.
+func Up(d Driver, target int64) ([]*Migration, error) {
+	applied, err := appliedSet(d)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []*Migration{}
+	for _, m := range Registered() {
+		if applied[m.Version] {
+			continue
+		} else if target > 0 && m.Version > target {
+			break
+		}
+
+		if err = apply(d, m); err != nil {
+			return done, err
+		}
+		done = append(done, m)
+	}
+
+	return done, nil
+}
+
+// apply runs the migration and records its version
+func apply(d Driver, m *Migration) error {
+	if err := m.Up(d); err != nil {
+		return fmt.Errorf("migration %s failed: %v", m, err)
+	} else if err = d.RecordVersion(m.Version); err != nil {
+		return fmt.Errorf("migration %s was applied but not recorded: %v", m, err)
+	}
+
+	return nil
+}
+
+// Down rolls back the given number of applied migrations, newest first.
+// Returns the migrations that were rolled back.
+func Down(d Driver, steps int) ([]*Migration, error) {
+	versions, err := appliedVersions(d)
+	if err != nil {
+		return nil, err
+	}
+
+	registered := map[int64]*Migration{}
+	for _, m := range Registered() {
+		registered[m.Version] = m
+	}
+
+	done := []*Migration{}
+	for i := len(versions) - 1; i >= 0 && len(done) < steps; i-- {
+		m, found := registered[versions[i]]
+		if !found {
+			return done, fmt.Errorf("migration %d was applied but is not registered", versions[i])
+		} else if m.Down == nil {
+			return done, fmt.Errorf("migration %s can't be rolled back", m)
+		}
+
+		if err = m.Down(d); err != nil {
+			return done, fmt.Errorf("rollback of migration %s failed: %v", m, err)
+		} else if err = d.RemoveVersion(m.Version); err != nil {
+			return done, fmt.Errorf("migration %s was rolled back but is still recorded: %v", m, err)
+		}
+		done = append(done, m)
+	}
+
+	return done, nil
+}
+
+// Redo rolls back the given number of migrations and then reapplies them, oldest first.
+// Pending migrations older than the rolled back ones are left pending, EX: a teammate's.
+func Redo(d Driver, steps int) ([]*Migration, error) {
+	rolledBack, err := Down(d, steps)
+	if err != nil || len(rolledBack) == 0 {
+		return nil, err
+	}
+
+	done := []*Migration{}
+	for i := len(rolledBack) - 1; i >= 0; i-- {
+		if err = apply(d, rolledBack[i]); err != nil {
+			return done, err
+		}
+		done = append(done, rolledBack[i])
+	}
+
+	return done, nil
+}
+
+// StatusOf lists every registered or applied migration, oldest first
+func StatusOf(d Driver) ([]Status, error) {
+	applied, err := appliedSet(d)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []Status{}
+	for _, m := range Registered() {
+		statuses = append(statuses, Status{Version: m.Version, Name: m.Name, Applied: applied[m.Version]})
+		delete(applied, m.Version)
+	}
+	for version := range applied {
+		statuses = append(statuses, Status{Version: version, Applied: true})
+	}
+	sort.Sort(byStatusVersion(statuses))
+
+	return statuses, nil
+}
+
+// appliedVersions ensures the ledger exists and returns its versions, oldest first
+func appliedVersions(d Driver) ([]int64, error) {
+	if err := d.EnsureLedger(); err != nil {
+		return nil, err
+	}
+
+	versions, err := d.AppliedVersions()
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(int64s(versions))
+
+	return versions, nil
+}
+
+func appliedSet(d Driver) (map[int64]bool, error) {
+	versions, err := appliedVersions(d)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := map[int64]bool{}
+	for _, v := range versions {
+		applied[v] = true
+	}
+
+	return applied, nil
+}
+
+type byStatusVersion []Status
+
+func (a byStatusVersion) Len() int           { return len(a) }
+func (a byStatusVersion) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byStatusVersion) Less(i, j int) bool { return a[i].Version < a[j].Version }
+
+type int64s []int64
+
+func (a int64s) Len() int           { return len(a) }
+func (a int64s) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a int64s) Less(i, j int) bool { return a[i] < a[j] }
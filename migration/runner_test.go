@@ -0,0 +1,110 @@
+package migration
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Runner", func() {
+
+	var d *memoryDriver
+
+	BeforeEach(func() {
+		resetRegistry()
+		d = newMemoryDriver()
+		Register(1, "create_vehicles", createTable("vehicles"), dropTable("vehicles"))
+		Register(2, "create_dealers", createTable("dealers"), dropTable("dealers"))
+		Register(3, "create_owners", createTable("owners"), dropTable("owners"))
+	})
+
+	It("should apply pending migrations in order", func() {
+		applied, err := Up(d, 0)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(applied).Should(HaveLen(3))
+		Ω(d.ensured).Should(BeTrue())
+		Ω(d.ledger).Should(Equal([]int64{1, 2, 3}))
+
+		applied, err = Up(d, 0)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(applied).Should(BeEmpty())
+	})
+
+	It("should apply migrations up to a target version", func() {
+		_, err := Up(d, 2)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(d.ledger).Should(Equal([]int64{1, 2}))
+		Ω(d.tables).ShouldNot(HaveKey("owners"))
+	})
+
+	It("should stop at the first failed migration", func() {
+		Register(4, "create_vehicles_again", createTable("vehicles"), nil)
+		Register(5, "create_parts", createTable("parts"), nil)
+
+		applied, err := Up(d, 0)
+		Ω(err).Should(MatchError("migration 4_create_vehicles_again failed: table already exists: vehicles"))
+		Ω(applied).Should(HaveLen(3))
+		Ω(d.ledger).Should(Equal([]int64{1, 2, 3}))
+		Ω(d.tables).ShouldNot(HaveKey("parts"))
+	})
+
+	It("should roll back the newest migrations", func() {
+		Up(d, 0)
+		rolledBack, err := Down(d, 2)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(rolledBack[0].Name).Should(Equal("create_owners"))
+		Ω(d.ledger).Should(Equal([]int64{1}))
+		Ω(d.tables).Should(Equal(map[string]bool{"vehicles": true}))
+	})
+
+	It("should not roll back an irreversible migration", func() {
+		Register(4, "load_vehicles", func(d Driver) error { return nil }, nil)
+		Up(d, 0)
+		_, err := Down(d, 1)
+		Ω(err).Should(MatchError("migration 4_load_vehicles can't be rolled back"))
+		Ω(d.ledger).Should(HaveLen(4))
+	})
+
+	It("should redo the newest migration", func() {
+		Up(d, 0)
+		redone, err := Redo(d, 1)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(redone).Should(HaveLen(1))
+		Ω(redone[0].Name).Should(Equal("create_owners"))
+		Ω(d.ledger).Should(Equal([]int64{1, 2, 3}))
+	})
+
+	It("should only reapply the migrations it rolled back", func() {
+		Up(d, 0)
+		d.ledger = []int64{1, 3} // 2 is a teammate's, still pending
+		delete(d.tables, "dealers")
+
+		redone, err := Redo(d, 1)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(redone).Should(HaveLen(1))
+		Ω(redone[0].Name).Should(Equal("create_owners"))
+		Ω(d.ledger).Should(Equal([]int64{1, 3}))
+		Ω(d.tables).ShouldNot(HaveKey("dealers"))
+	})
+
+	It("should report the status of registered and applied migrations", func() {
+		Up(d, 1)
+		d.ledger = append(d.ledger, 99)
+
+		statuses, err := StatusOf(d)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(statuses).Should(Equal([]Status{
+			{Version: 1, Name: "create_vehicles", Applied: true},
+			{Version: 2, Name: "create_dealers"},
+			{Version: 3, Name: "create_owners"},
+			{Version: 99, Applied: true},
+		}))
+	})
+
+	It("should return migration errors", func() {
+		Register(4, "fails", func(d Driver) error { return errors.New("boom") }, nil)
+		_, err := Up(d, 0)
+		Ω(err).Should(HaveOccurred())
+	})
+})
@@ -0,0 +1,98 @@
+// Package naming maps struct fields onto the columns each adapter's library uses.
+// It has no goar dependency, so tools like goar-query can use it w/o a config.json.
+package naming
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Dialects, which match schema's
+const (
+	Postgres  = "postgres"
+	MSSQL     = "mssql"
+	RethinkDB = "rethinkdb"
+)
+
+// Skipped reports whether the field isn't persisted, EX: `gorethink:"-"` or `schema:"-"`
+func Skipped(tag reflect.StructTag, dialect string) bool {
+	if tag.Get("schema") == "-" {
+		return true
+	}
+
+	switch dialect {
+	case Postgres:
+		return tag.Get("gorm") == "-" || tag.Get("sql") == "-"
+	case MSSQL:
+		return tag.Get("xorm") == "-"
+	case RethinkDB:
+		return tag.Get("gorethink") == "-"
+	}
+
+	return tag.Get("json") == "-"
+}
+
+// Column names the field's column.  Other dialects, EX: dynamodb, use the json name.
+func Column(field string, tag reflect.StructTag, dialect string) string {
+	switch dialect {
+	case Postgres:
+		for _, option := range strings.Split(tag.Get("gorm"), ";") {
+			if strings.HasPrefix(option, "column:") {
+				return strings.TrimPrefix(option, "column:")
+			}
+		}
+		return SnakeCase(field)
+	case MSSQL:
+		for _, option := range strings.Fields(tag.Get("xorm")) {
+			if strings.HasPrefix(option, "'") && strings.HasSuffix(option, "'") {
+				return strings.Trim(option, "'")
+			}
+		}
+		return XormSnakeCase(field)
+	case RethinkDB:
+		if name := strings.Split(tag.Get("gorethink"), ",")[0]; name != "" {
+			return name
+		}
+		return field
+	}
+
+	if name := strings.Split(tag.Get("json"), ",")[0]; name != "" {
+		return name
+	}
+	return field
+}
+
+// SnakeCase names columns like gorm, keeping acronyms together, EX: VINNumber => vin_number
+func SnakeCase(name string) string {
+	runes := []rune(name)
+	out := []rune{}
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				out = append(out, '_')
+			}
+		}
+		out = append(out, unicode.ToLower(r))
+	}
+
+	return string(out)
+}
+
+// XormSnakeCase names columns like xorm's default SnakeMapper, EX: SafetyRating => safety_rating, ID => i_d
+func XormSnakeCase(name string) string {
+	out := []rune{}
+	for i, r := range name {
+		if 'A' <= r && r <= 'Z' {
+			if i > 0 {
+				out = append(out, '_')
+			}
+			r += 'a' - 'A'
+		}
+		out = append(out, r)
+	}
+
+	return string(out)
+}
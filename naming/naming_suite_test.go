@@ -0,0 +1,13 @@
+package naming
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestNaming(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Naming Suite")
+}
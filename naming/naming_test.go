@@ -0,0 +1,34 @@
+package naming
+
+import (
+	"reflect"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Naming", func() {
+
+	It("should name columns per dialect", func() {
+		Ω(Column("SafetyRating", ``, Postgres)).Should(Equal("safety_rating"))
+		Ω(Column("SafetyRating", `gorm:"column:rating"`, Postgres)).Should(Equal("rating"))
+		Ω(Column("OwnerID", ``, MSSQL)).Should(Equal("owner_i_d"))
+		Ω(Column("OwnerID", `xorm:"'owner_id' notnull"`, MSSQL)).Should(Equal("owner_id"))
+		Ω(Column("Make", ``, RethinkDB)).Should(Equal("Make"))
+		Ω(Column("Make", `gorethink:"make,omitempty"`, RethinkDB)).Should(Equal("make"))
+		Ω(Column("Make", `json:"make,omitempty"`, "dynamodb")).Should(Equal("make"))
+	})
+
+	It("should skip unpersisted fields", func() {
+		Ω(Skipped(reflect.StructTag(`schema:"-"`), Postgres)).Should(BeTrue())
+		Ω(Skipped(reflect.StructTag(`gorethink:"-"`), RethinkDB)).Should(BeTrue())
+		Ω(Skipped(reflect.StructTag(`gorethink:"-"`), "dynamodb")).Should(BeFalse())
+	})
+
+	It("should snake case like gorm", func() {
+		Ω(SnakeCase("SafetyRating")).Should(Equal("safety_rating"))
+		Ω(SnakeCase("VINNumber")).Should(Equal("vin_number"))
+		Ω(SnakeCase("OwnerID")).Should(Equal("owner_id"))
+		Ω(XormSnakeCase("OwnerID")).Should(Equal("owner_i_d"))
+	})
+})
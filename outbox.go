@@ -0,0 +1,209 @@
+package goar
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// OutboxModelName is the table/collection the adapters write outbox events to
+const OutboxModelName = "goar_outbox"
+
+const (
+	OutboxCreate = "create"
+	OutboxUpdate = "update"
+	OutboxDelete = "delete"
+)
+
+// Outboxer is implemented by models whose Save and Delete record an OutboxEvent
+// with the model, EX: func (v *Vehicle) OutboxTopic() string { return "inventory.vehicles" }
+type Outboxer interface {
+	OutboxTopic() string
+}
+
+// OutboxEvent describes a single change to a model.  ID is unique per event,
+// so consumers can use it to discard the duplicates at-least-once delivery implies.
+type OutboxEvent struct {
+	ID         string    `json:"id" gorethink:"id"`
+	Topic      string    `json:"topic" gorethink:"topic"`
+	ModelName  string    `json:"model_name" gorethink:"model_name"`
+	Key        string    `json:"key" gorethink:"key"`
+	Operation  string    `json:"operation" gorethink:"operation"`
+	Changes    []string  `json:"changes" gorethink:"changes"` // the names of the fields that changed
+	OccurredAt time.Time `json:"occurred_at" gorethink:"occurred_at"`
+}
+
+// OutboxStore is implemented by the adapters that record outbox events.
+// The SQL adapters write the event in the same transaction as the model,
+// while the document stores write it immediately after the model.
+type OutboxStore interface {
+	// PendingOutboxEvents returns the oldest unpublished events
+	PendingOutboxEvents(limit int) ([]*OutboxEvent, error)
+	// MarkOutboxEventsPublished removes the events from the outbox
+	MarkOutboxEventsPublished(ids []string) error
+}
+
+// Publisher delivers events to a message bus
+type Publisher interface {
+	Publish(event *OutboxEvent) error
+}
+
+// NewOutboxEvent is called by the adapters after the model has been written.
+// It returns nil when the model doesn't implement Outboxer.
+func NewOutboxEvent(ari ActiveRecordInterfacer, operation string, changes []string) *OutboxEvent {
+	o, ok := ari.(Outboxer)
+	if !ok {
+		return nil
+	}
+
+	key, _ := modelID(ari)
+
+	return &OutboxEvent{
+		ID:         newEventID(),
+		Topic:      o.OutboxTopic(),
+		ModelName:  ari.ModelName(),
+		Key:        key,
+		Operation:  operation,
+		Changes:    changes,
+		OccurredAt: time.Now().UTC(),
+	}
+}
+
+// ChangedFields compares the json representations of two versions of a model
+// and returns the sorted names of the fields that differ.  Either version may be nil,
+// EX: ChangedFields(nil, model) returns every field of a newly created model
+func ChangedFields(before interface{}, after interface{}) ([]string, error) {
+	b, err := fieldValues(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := fieldValues(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []string{}
+	for name, value := range a {
+		if old, found := b[name]; !found || !reflect.DeepEqual(old, value) {
+			changes = append(changes, name)
+		}
+	}
+	for name := range b {
+		if _, found := a[name]; !found {
+			changes = append(changes, name)
+		}
+	}
+	sort.Strings(changes)
+
+	return changes, nil
+}
+
+// checkOutbox fails fast when a model uses the outbox but its adapter can't record events
+func checkOutbox(ari ActiveRecordInterfacer) error {
+	if _, ok := ari.(Outboxer); !ok {
+		return nil
+	}
+	if _, ok := ari.(OutboxStore); !ok {
+		return errors.New(fmt.Sprintf("%s: the adapter does not support outbox events", ari.ModelName()))
+	}
+
+	return nil
+}
+
+func fieldValues(v interface{}) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	if v == nil {
+		return values, nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return values, json.Unmarshal(b, &values)
+}
+
+// newEventID returns a random (version 4) uuid
+func newEventID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Panic("goar could not generate an event id:", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// OutboxDispatcher drains an OutboxStore to a Publisher.  An event is only removed
+// from the outbox once it has been published, so delivery is at-least-once.
+// EX: go goar.NewOutboxDispatcher(Vehicle{}.ToActiveRecord(), publisher).Run(ctx)
+type OutboxDispatcher struct {
+	Store     OutboxStore
+	Publisher Publisher
+	BatchSize int
+	Interval  time.Duration // how long to wait before polling an empty outbox again
+}
+
+func NewOutboxDispatcher(store OutboxStore, publisher Publisher) *OutboxDispatcher {
+	return &OutboxDispatcher{
+		Store:     store,
+		Publisher: publisher,
+		BatchSize: 100,
+		Interval:  time.Second,
+	}
+}
+
+// Run dispatches events until ctx is done
+func (d *OutboxDispatcher) Run(ctx context.Context) {
+	for {
+		published, err := d.Dispatch()
+		if err != nil {
+			log.Println("goar outbox dispatch failed:", err)
+		}
+
+		wait := d.Interval
+		if err == nil && published == d.BatchSize { // more events are likely waiting
+			wait = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Dispatch publishes one batch of pending events in the order they occurred.
+// It stops at the first event that can't be published so the order is preserved on retry.
+func (d *OutboxDispatcher) Dispatch() (published int, err error) {
+	events, err := d.Store.PendingOutboxEvents(d.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	ids := []string{}
+	for _, event := range events {
+		if err = d.Publisher.Publish(event); err != nil {
+			break
+		}
+		ids = append(ids, event.ID)
+	}
+
+	if len(ids) > 0 {
+		// NOTE: if this fails, the events are published again on the next dispatch
+		if markErr := d.Store.MarkOutboxEventsPublished(ids); markErr != nil {
+			return 0, markErr
+		}
+	}
+
+	return len(ids), err
+}
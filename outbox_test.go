@@ -0,0 +1,224 @@
+package goar
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type OutboxedAutomobile struct {
+	ActiveRecordAutomobile
+	ID string `json:"id,omitempty"`
+}
+
+func (m *OutboxedAutomobile) OutboxTopic() string {
+	return "inventory.automobiles"
+}
+
+// memoryOutbox is an OutboxStore that keeps events in memory
+type memoryOutbox struct {
+	sync.Mutex
+	events  []*OutboxEvent
+	markErr error
+}
+
+func (o *memoryOutbox) PendingOutboxEvents(limit int) ([]*OutboxEvent, error) {
+	o.Lock()
+	defer o.Unlock()
+
+	if len(o.events) < limit {
+		limit = len(o.events)
+	}
+	return append([]*OutboxEvent{}, o.events[:limit]...), nil
+}
+
+func (o *memoryOutbox) MarkOutboxEventsPublished(ids []string) error {
+	o.Lock()
+	defer o.Unlock()
+
+	if o.markErr != nil {
+		return o.markErr
+	}
+
+	published := map[string]bool{}
+	for _, id := range ids {
+		published[id] = true
+	}
+
+	pending := []*OutboxEvent{}
+	for _, event := range o.events {
+		if !published[event.ID] {
+			pending = append(pending, event)
+		}
+	}
+	o.events = pending
+
+	return nil
+}
+
+func (o *memoryOutbox) pending() int {
+	o.Lock()
+	defer o.Unlock()
+	return len(o.events)
+}
+
+type recordingPublisher struct {
+	sync.Mutex
+	published []string
+	failOn    string
+}
+
+func (p *recordingPublisher) Publish(event *OutboxEvent) error {
+	p.Lock()
+	defer p.Unlock()
+
+	if event.Key == p.failOn {
+		return errors.New("bus unavailable")
+	}
+	p.published = append(p.published, event.Key)
+
+	return nil
+}
+
+func (p *recordingPublisher) keys() []string {
+	p.Lock()
+	defer p.Unlock()
+	return append([]string{}, p.published...)
+}
+
+var _ = Describe("Outbox", func() {
+
+	Context("Events", func() {
+		It("should only create events for models that use the outbox", func() {
+			Ω(NewOutboxEvent(validAutomobileFactory(), OutboxCreate, nil)).Should(BeNil())
+
+			auto := ToAR(&OutboxedAutomobile{ID: "carrera"}).(*OutboxedAutomobile)
+			event := NewOutboxEvent(auto, OutboxUpdate, []string{"Model"})
+			Ω(event).ShouldNot(BeNil())
+			Ω(event.Topic).Should(Equal("inventory.automobiles"))
+			Ω(event.ModelName).Should(Equal(auto.ModelName()))
+			Ω(event.Key).Should(Equal("carrera"))
+			Ω(event.Operation).Should(Equal(OutboxUpdate))
+			Ω(event.Changes).Should(Equal([]string{"Model"}))
+			Ω(event.OccurredAt.IsZero()).Should(BeFalse())
+		})
+
+		It("should give every event a unique id", func() {
+			auto := ToAR(&OutboxedAutomobile{ID: "carrera"})
+			id := NewOutboxEvent(auto, OutboxCreate, nil).ID
+			Ω(id).Should(MatchRegexp("^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$"))
+			Ω(NewOutboxEvent(auto, OutboxCreate, nil).ID).ShouldNot(Equal(id))
+		})
+
+		It("should list the fields that changed", func() {
+			before := map[string]interface{}{"Make": "porsche", "Model": "carrera gt", "Year": 2007}
+			after := map[string]interface{}{"Make": "porsche", "Model": "918 spyder", "Color": "silver"}
+
+			changes, err := ChangedFields(before, after)
+			Ω(err).NotTo(HaveOccurred())
+			Ω(changes).Should(Equal([]string{"Color", "Model", "Year"}))
+
+			changes, err = ChangedFields(nil, after)
+			Ω(err).NotTo(HaveOccurred())
+			Ω(changes).Should(Equal([]string{"Color", "Make", "Model"}))
+
+			changes, err = ChangedFields(before, before)
+			Ω(err).NotTo(HaveOccurred())
+			Ω(changes).Should(BeEmpty())
+		})
+
+		It("should not save a model whose adapter can't record events", func() {
+			auto := ToAR(&OutboxedAutomobile{ID: "carrera"}).(*OutboxedAutomobile)
+			auto.Year, auto.Make, auto.Model = 2007, "porsche", "carrera gt"
+
+			success, err := auto.Save()
+			Ω(success).Should(BeFalse())
+			Ω(err).To(HaveOccurred())
+			Ω(auto.Delete()).ShouldNot(Succeed())
+		})
+	})
+
+	Context("Dispatcher", func() {
+		var (
+			outbox     *memoryOutbox
+			publisher  *recordingPublisher
+			dispatcher *OutboxDispatcher
+		)
+
+		BeforeEach(func() {
+			outbox = &memoryOutbox{}
+			for _, key := range []string{"a", "b", "c"} {
+				outbox.events = append(outbox.events, &OutboxEvent{ID: newEventID(), Key: key})
+			}
+			publisher = &recordingPublisher{}
+			dispatcher = NewOutboxDispatcher(outbox, publisher)
+		})
+
+		It("should publish pending events in order", func() {
+			published, err := dispatcher.Dispatch()
+			Ω(err).NotTo(HaveOccurred())
+			Ω(published).Should(Equal(3))
+			Ω(publisher.keys()).Should(Equal([]string{"a", "b", "c"}))
+			Ω(outbox.pending()).Should(Equal(0))
+		})
+
+		It("should publish in batches", func() {
+			dispatcher.BatchSize = 2
+			published, err := dispatcher.Dispatch()
+			Ω(err).NotTo(HaveOccurred())
+			Ω(published).Should(Equal(2))
+			Ω(outbox.pending()).Should(Equal(1))
+		})
+
+		It("should stop at the first event that can't be published and retry it later", func() {
+			publisher.failOn = "b"
+			published, err := dispatcher.Dispatch()
+			Ω(err).To(HaveOccurred())
+			Ω(published).Should(Equal(1))
+			Ω(outbox.pending()).Should(Equal(2))
+
+			publisher.failOn = ""
+			published, err = dispatcher.Dispatch()
+			Ω(err).NotTo(HaveOccurred())
+			Ω(published).Should(Equal(2))
+			Ω(publisher.keys()).Should(Equal([]string{"a", "b", "c"}))
+		})
+
+		It("should publish events again when they can't be marked as published", func() {
+			outbox.markErr = errors.New("db unavailable")
+			_, err := dispatcher.Dispatch()
+			Ω(err).To(HaveOccurred())
+			Ω(outbox.pending()).Should(Equal(3))
+
+			outbox.markErr = nil
+			_, err = dispatcher.Dispatch()
+			Ω(err).NotTo(HaveOccurred())
+			Ω(publisher.keys()).Should(Equal([]string{"a", "b", "c", "a", "b", "c"}))
+		})
+
+		It("should drain the outbox until the context is done", func() {
+			dispatcher.Interval = 10 * time.Millisecond
+			ctx, cancel := context.WithCancel(context.Background())
+			stopped := make(chan struct{})
+			go func() {
+				dispatcher.Run(ctx)
+				close(stopped)
+			}()
+
+			Eventually(outbox.pending).Should(Equal(0))
+
+			outbox.Lock()
+			outbox.events = append(outbox.events, &OutboxEvent{ID: newEventID(), Key: "d"})
+			outbox.Unlock()
+			Eventually(outbox.pending).Should(Equal(0))
+			Ω(publisher.keys()).Should(Equal([]string{"a", "b", "c", "d"}))
+
+			cancel()
+			Eventually(stopped).Should(BeClosed())
+		})
+	})
+})
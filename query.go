@@ -46,6 +46,7 @@ type Querier interface {
 	Order(OrderBy) *ActiveRecord
 	Sum(fields ...interface{}) *ActiveRecord
 	Distinct() *ActiveRecord
+	Limit(int) *ActiveRecord
 	//Or(QueryCondition) *ActiveRecord
 	Run(results interface{}) error
 }
@@ -0,0 +1,112 @@
+package goar
+
+import "time"
+
+// Field is a model's queryable column, which goar-query generates so a typo in a key
+// fails to compile, EX: m.Where(VehicleQ.Year.Gte(2010)).Order(VehicleQ.Year.Desc())
+type Field struct {
+	Key string
+}
+
+func (f Field) Eq(value interface{}) QueryCondition  { return f.condition(EQ, value) }
+func (f Field) Ne(value interface{}) QueryCondition  { return f.condition(NE, value) }
+func (f Field) Lt(value interface{}) QueryCondition  { return f.condition(LT, value) }
+func (f Field) Lte(value interface{}) QueryCondition { return f.condition(LTE, value) }
+func (f Field) Gt(value interface{}) QueryCondition  { return f.condition(GT, value) }
+func (f Field) Gte(value interface{}) QueryCondition { return f.condition(GTE, value) }
+
+// In matches any of the values, which the condition holds as a []interface{}
+func (f Field) In(values ...interface{}) QueryCondition { return f.condition(IN, values) }
+
+func (f Field) Asc() OrderBy  { return OrderBy{Key: f.Key, SortOrder: ASC} }
+func (f Field) Desc() OrderBy { return OrderBy{Key: f.Key, SortOrder: DESC} }
+
+func (f Field) condition(op EnumRelationalOperators, value interface{}) QueryCondition {
+	return QueryCondition{Key: f.Key, RelationalOperator: op, Value: value}
+}
+
+type StringField struct{ Field }
+
+func (f StringField) Eq(value string) QueryCondition  { return f.Field.Eq(value) }
+func (f StringField) Ne(value string) QueryCondition  { return f.Field.Ne(value) }
+func (f StringField) Lt(value string) QueryCondition  { return f.Field.Lt(value) }
+func (f StringField) Lte(value string) QueryCondition { return f.Field.Lte(value) }
+func (f StringField) Gt(value string) QueryCondition  { return f.Field.Gt(value) }
+func (f StringField) Gte(value string) QueryCondition { return f.Field.Gte(value) }
+func (f StringField) In(values ...string) QueryCondition {
+	in := make([]interface{}, len(values))
+	for i, v := range values {
+		in[i] = v
+	}
+	return f.Field.In(in...)
+}
+
+type IntField struct{ Field }
+
+func (f IntField) Eq(value int) QueryCondition  { return f.Field.Eq(value) }
+func (f IntField) Ne(value int) QueryCondition  { return f.Field.Ne(value) }
+func (f IntField) Lt(value int) QueryCondition  { return f.Field.Lt(value) }
+func (f IntField) Lte(value int) QueryCondition { return f.Field.Lte(value) }
+func (f IntField) Gt(value int) QueryCondition  { return f.Field.Gt(value) }
+func (f IntField) Gte(value int) QueryCondition { return f.Field.Gte(value) }
+func (f IntField) In(values ...int) QueryCondition {
+	in := make([]interface{}, len(values))
+	for i, v := range values {
+		in[i] = v
+	}
+	return f.Field.In(in...)
+}
+
+type Int64Field struct{ Field }
+
+func (f Int64Field) Eq(value int64) QueryCondition  { return f.Field.Eq(value) }
+func (f Int64Field) Ne(value int64) QueryCondition  { return f.Field.Ne(value) }
+func (f Int64Field) Lt(value int64) QueryCondition  { return f.Field.Lt(value) }
+func (f Int64Field) Lte(value int64) QueryCondition { return f.Field.Lte(value) }
+func (f Int64Field) Gt(value int64) QueryCondition  { return f.Field.Gt(value) }
+func (f Int64Field) Gte(value int64) QueryCondition { return f.Field.Gte(value) }
+func (f Int64Field) In(values ...int64) QueryCondition {
+	in := make([]interface{}, len(values))
+	for i, v := range values {
+		in[i] = v
+	}
+	return f.Field.In(in...)
+}
+
+type FloatField struct{ Field }
+
+func (f FloatField) Eq(value float64) QueryCondition  { return f.Field.Eq(value) }
+func (f FloatField) Ne(value float64) QueryCondition  { return f.Field.Ne(value) }
+func (f FloatField) Lt(value float64) QueryCondition  { return f.Field.Lt(value) }
+func (f FloatField) Lte(value float64) QueryCondition { return f.Field.Lte(value) }
+func (f FloatField) Gt(value float64) QueryCondition  { return f.Field.Gt(value) }
+func (f FloatField) Gte(value float64) QueryCondition { return f.Field.Gte(value) }
+func (f FloatField) In(values ...float64) QueryCondition {
+	in := make([]interface{}, len(values))
+	for i, v := range values {
+		in[i] = v
+	}
+	return f.Field.In(in...)
+}
+
+// BoolField only compares for (in)equality
+type BoolField struct{ Field }
+
+func (f BoolField) Eq(value bool) QueryCondition { return f.Field.Eq(value) }
+func (f BoolField) Ne(value bool) QueryCondition { return f.Field.Ne(value) }
+
+type TimeField struct{ Field }
+
+func (f TimeField) Eq(value time.Time) QueryCondition  { return f.Field.Eq(value) }
+func (f TimeField) Ne(value time.Time) QueryCondition  { return f.Field.Ne(value) }
+func (f TimeField) Lt(value time.Time) QueryCondition  { return f.Field.Lt(value) }
+func (f TimeField) Lte(value time.Time) QueryCondition { return f.Field.Lte(value) }
+func (f TimeField) Gt(value time.Time) QueryCondition  { return f.Field.Gt(value) }
+func (f TimeField) Gte(value time.Time) QueryCondition { return f.Field.Gte(value) }
+func (f TimeField) In(values ...time.Time) QueryCondition {
+	in := make([]interface{}, len(values))
+	for i, v := range values {
+		in[i] = v
+	}
+	return f.Field.In(in...)
+}
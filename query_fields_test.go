@@ -0,0 +1,34 @@
+package goar
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Query Fields", func() {
+	year := IntField{Field{Key: "year"}}
+	vehicleMake := StringField{Field{Key: "make"}}
+
+	It("should build conditions", func() {
+		Ω(year.Gte(2010)).Should(Equal(QueryCondition{Key: "year", RelationalOperator: GTE, Value: 2010}))
+		Ω(vehicleMake.Ne("Honda")).Should(Equal(QueryCondition{Key: "make", RelationalOperator: NE, Value: "Honda"}))
+		Ω(vehicleMake.In("Honda", "Acura")).Should(Equal(QueryCondition{Key: "make", RelationalOperator: IN, Value: []interface{}{"Honda", "Acura"}}))
+
+		t := time.Date(2015, 10, 14, 0, 0, 0, 0, time.UTC)
+		Ω(TimeField{Field{Key: "sold_at"}}.Lt(t).Value).Should(Equal(t))
+	})
+
+	It("should build order bys", func() {
+		Ω(year.Desc()).Should(Equal(OrderBy{Key: "year", SortOrder: DESC}))
+		Ω(vehicleMake.Asc()).Should(Equal(OrderBy{Key: "make", SortOrder: ASC}))
+	})
+
+	It("should compile down to the query", func() {
+		q := NewQuery()
+		q.WhereConditions = append(q.WhereConditions, year.Gte(2010), vehicleMake.Eq("Honda"))
+		Ω(q.WhereConditions).Should(HaveLen(2))
+		Ω(q.WhereConditions[1].Key).Should(Equal("make"))
+	})
+})
@@ -0,0 +1,349 @@
+// Package querygen generates typed query fields and finders for models, so query keys
+// are checked by the compiler instead of failing, or on rethinkdb silently matching
+// nothing, at runtime.  For a model like
+//
+//	type Vehicle struct {
+//		rethinkdb.ArRethinkDb
+//		Year int
+//		Make string `gorethink:"make"`
+//	}
+//
+// it generates VehicleQ, whose fields build goar.QueryConditions and goar.OrderBys
+// keyed by each field's column, and a FindBy method per field, EX:
+//
+//	m := Vehicle{}.ToActiveRecord()
+//	m.Where(VehicleQ.Year.Gte(2010)).Where(VehicleQ.Make.In("Honda", "Acura")).Order(VehicleQ.Year.Desc()).Run(&results)
+//	m.FindByMake("Honda", &results)
+//
+// It parses source rather than importing goar, so it runs w/o a config.json.
+package querygen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/obieq/goar/naming"
+)
+
+// Header starts every generated file, which Generate skips when it parses the package
+const Header = "// Code generated by goar-query; DO NOT EDIT."
+
+// base is the fields an adapter's embedded type contributes, and how it names columns
+type base struct {
+	dialect string
+	fields  []field
+}
+
+var timestamps = []field{
+	{Name: "CreatedAt", Type: "*time.Time", Tag: `json:"created_at,omitempty"`},
+	{Name: "UpdatedAt", Type: "*time.Time", Tag: `json:"updated_at,omitempty"`},
+}
+
+// bases are keyed by the adapters' type names, EX: rethinkdb.ArRethinkDb
+var bases = map[string]base{
+	"ArRethinkDb": {naming.RethinkDB, []field{
+		{Name: "ID", Type: "string", Tag: `gorethink:"id,omitempty"`},
+		{Name: "CreatedAt", Type: "time.Time", Tag: `gorethink:"created_at,omitempty"`},
+		{Name: "UpdatedAt", Type: "time.Time", Tag: `gorethink:"updated_at,omitempty"`},
+	}},
+	"ArPostgres":    {naming.Postgres, append([]field{{Name: "ID", Type: "int"}}, timestamps...)},
+	"ArMsSql":       {naming.MSSQL, append([]field{{Name: "ID", Type: "int", Tag: `xorm:"pk autoincr 'id'"`}}, timestamps...)},
+	"ArDynamodb":    {"dynamodb", append([]field{{Name: "ID", Type: "string", Tag: `json:"id,omitempty"`}}, timestamps...)},
+	"ArCouchbase":   {"couchbase", append([]field{{Name: "ID", Type: "string", Tag: `json:"id,omitempty"`}}, timestamps...)},
+	"ArOrchestrate": {"orchestrate", append([]field{{Name: "ID", Type: "string", Tag: `json:"id,omitempty"`}}, timestamps...)},
+}
+
+// fieldTypes maps go types onto the goar field types, and the type their values take.
+// Any other type is a goar.Field, whose values are interface{}.
+var fieldTypes = map[string][2]string{
+	"string":     {"goar.StringField", "string"},
+	"int":        {"goar.IntField", "int"},
+	"int64":      {"goar.Int64Field", "int64"},
+	"float64":    {"goar.FloatField", "float64"},
+	"bool":       {"goar.BoolField", "bool"},
+	"time.Time":  {"goar.TimeField", "time.Time"},
+	"*time.Time": {"goar.TimeField", "time.Time"},
+}
+
+type field struct {
+	Name  string // EX: SafetyRating
+	Type  string // the go type, EX: *time.Time
+	Tag   reflect.StructTag
+	depth int // of embedding, so outer fields hide embedded ones
+}
+
+type column struct {
+	Name      string // EX: SafetyRating
+	Key       string // EX: safety_rating
+	FieldType string // EX: goar.IntField
+	ValueType string // EX: int
+}
+
+type model struct {
+	Name    string
+	Columns []column
+}
+
+type file struct {
+	Package string
+	Types   string
+	Time    bool // imports time
+	Models  []model
+}
+
+// Generate parses the package in dir and returns the query helpers for the types,
+// or for every model that isn't embedded by another when none are given
+func Generate(dir string, typeNames []string) ([]byte, error) {
+	pkg, structs, err := parse(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(typeNames) == 0 {
+		typeNames = models(structs)
+		if len(typeNames) == 0 {
+			return nil, fmt.Errorf("no models in %s, which embed an adapter's type, EX: rethinkdb.ArRethinkDb", dir)
+		}
+	}
+
+	f := &file{Package: pkg, Types: strings.Join(typeNames, ","), Models: []model{}}
+	for _, name := range typeNames {
+		st, ok := structs[name]
+		if !ok {
+			return nil, fmt.Errorf("no struct named %s in %s", name, dir)
+		}
+
+		fields, dialect, ok := flatten(st, structs, 0, map[string]bool{name: true})
+		if !ok {
+			return nil, fmt.Errorf("%s isn't a model b/c it doesn't embed an adapter's type, EX: rethinkdb.ArRethinkDb", name)
+		}
+
+		m := model{Name: name, Columns: []column{}}
+		for _, fd := range fields {
+			if naming.Skipped(fd.Tag, dialect) {
+				continue
+			}
+
+			ft, ok := fieldTypes[fd.Type]
+			if !ok {
+				ft = [2]string{"goar.Field", "interface{}"}
+			}
+			f.Time = f.Time || ft[1] == "time.Time"
+			m.Columns = append(m.Columns, column{Name: fd.Name, Key: naming.Column(fd.Name, fd.Tag, dialect), FieldType: ft[0], ValueType: ft[1]})
+		}
+		f.Models = append(f.Models, m)
+	}
+
+	src := &bytes.Buffer{}
+	if err = fileTemplate.Execute(src, f); err != nil {
+		return nil, err
+	}
+
+	return format.Source(src.Bytes())
+}
+
+// Output names the generated file after the first type, EX: Vehicle => vehicle_query.go
+func Output(dir string, typeNames []string) string {
+	name := "models"
+	if len(typeNames) > 0 {
+		name = naming.SnakeCase(typeNames[0])
+	}
+
+	return filepath.Join(dir, name+"_query.go")
+}
+
+// parse returns the package's name and structs, skipping tests and generated files
+func parse(dir string) (string, map[string]*ast.StructType, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return "", nil, err
+	}
+
+	fset := token.NewFileSet()
+	pkg, structs := "", map[string]*ast.StructType{}
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", nil, err
+		}
+		if bytes.HasPrefix(src, []byte(Header)) {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+		if err != nil {
+			return "", nil, err
+		}
+		pkg = f.Name.Name
+
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if st, ok := ts.Type.(*ast.StructType); ok {
+					structs[ts.Name.Name] = st
+				}
+			}
+		}
+	}
+
+	if pkg == "" {
+		return "", nil, fmt.Errorf("no go files in %s", dir)
+	}
+
+	return pkg, structs, nil
+}
+
+// flatten returns the struct's exported fields, including embedded ones, and its adapter's dialect.
+// ok is false when it doesn't embed an adapter's type.
+func flatten(st *ast.StructType, structs map[string]*ast.StructType, depth int, visiting map[string]bool) (fields []field, dialect string, ok bool) {
+	all := []field{}
+	for _, f := range st.Fields.List {
+		tag := reflect.StructTag("")
+		if f.Tag != nil {
+			unquoted, _ := strconv.Unquote(f.Tag.Value)
+			tag = reflect.StructTag(unquoted)
+		}
+
+		if len(f.Names) > 0 {
+			for _, name := range f.Names {
+				if name.IsExported() {
+					all = append(all, field{Name: name.Name, Type: types.ExprString(f.Type), Tag: tag, depth: depth})
+				}
+			}
+			continue
+		}
+
+		// embedded, EX: rethinkdb.ArRethinkDb, goar.Timestamps, Automobile or *Automobile
+		typ := f.Type
+		if star, isStar := typ.(*ast.StarExpr); isStar {
+			typ = star.X
+		}
+
+		switch t := typ.(type) {
+		case *ast.SelectorExpr:
+			if b, isBase := bases[t.Sel.Name]; isBase {
+				dialect, ok = b.dialect, true
+				for _, bf := range b.fields {
+					bf.depth = depth + 1
+					all = append(all, bf)
+				}
+			} else if t.Sel.Name == "Timestamps" {
+				for _, tf := range timestamps {
+					tf.depth = depth + 1
+					all = append(all, tf)
+				}
+			}
+		case *ast.Ident:
+			if b, isBase := bases[t.Name]; isBase { // EX: dot imported . "github.com/obieq/goar/db/postgres"
+				dialect, ok = b.dialect, true
+				for _, bf := range b.fields {
+					bf.depth = depth + 1
+					all = append(all, bf)
+				}
+			} else if t.Name == "Timestamps" {
+				for _, tf := range timestamps {
+					tf.depth = depth + 1
+					all = append(all, tf)
+				}
+			} else if embedded, isLocal := structs[t.Name]; isLocal && !visiting[t.Name] {
+				visiting[t.Name] = true
+				embeddedFields, embeddedDialect, embeddedOK := flatten(embedded, structs, depth+1, visiting)
+				delete(visiting, t.Name)
+
+				all = append(all, embeddedFields...)
+				if embeddedOK {
+					dialect, ok = embeddedDialect, true
+				}
+			}
+		}
+	}
+
+	// the shallowest field wins, like go's own selectors
+	shallowest := map[string]int{}
+	for _, f := range all {
+		if d, seen := shallowest[f.Name]; !seen || f.depth < d {
+			shallowest[f.Name] = f.depth
+		}
+	}
+	for _, f := range all {
+		if d, kept := shallowest[f.Name]; kept && d == f.depth {
+			fields = append(fields, f)
+			delete(shallowest, f.Name)
+		}
+	}
+
+	return fields, dialect, ok
+}
+
+// models returns the structs that embed an adapter's type and aren't embedded by another model
+func models(structs map[string]*ast.StructType) []string {
+	isModel := map[string]bool{}
+	for name, st := range structs {
+		if _, _, ok := flatten(st, structs, 0, map[string]bool{name: true}); ok {
+			isModel[name] = true
+		}
+	}
+
+	embedded := map[string]bool{}
+	for name := range isModel {
+		for _, f := range structs[name].Fields.List {
+			if id, ok := f.Type.(*ast.Ident); ok && len(f.Names) == 0 {
+				embedded[id.Name] = true
+			}
+		}
+	}
+
+	names := []string{}
+	for name := range isModel {
+		if !embedded[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+var fileTemplate = template.Must(template.New("query").Parse(`// Code generated by goar-query; DO NOT EDIT.
+// goar-query -type={{.Types}}
+
+package {{.Package}}
+
+import (
+{{if .Time}}	"time"
+{{end}}
+	goar "github.com/obieq/goar"
+)
+{{range $m := .Models}}
+// {{$m.Name}}Q are {{$m.Name}}'s typed query fields
+var {{$m.Name}}Q = struct {
+{{range $m.Columns}}	{{.Name}} {{.FieldType}}
+{{end}}}{
+{{range $m.Columns}}	{{.Name}}: {{.FieldType}}{{if ne .FieldType "goar.Field"}}{Field: goar.Field{Key: "{{.Key}}"}}{{else}}{Key: "{{.Key}}"}{{end}},
+{{end}}}
+{{range $m.Columns}}{{if ne .Name "ID"}}
+// FindBy{{.Name}} runs a query for the {{$m.Name}}s whose {{.Name}} equals the value
+func (m *{{$m.Name}}) FindBy{{.Name}}(value {{.ValueType}}, results interface{}) error {
+	return m.Where({{$m.Name}}Q.{{.Name}}.Eq(value)).Run(results)
+}
+{{end}}{{end}}{{end}}`))
@@ -0,0 +1,13 @@
+package querygen
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestQuerygen(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Querygen Suite")
+}
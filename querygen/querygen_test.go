@@ -0,0 +1,72 @@
+package querygen
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Querygen", func() {
+	dir := "testdata/models"
+
+	It("should generate fields keyed by the adapter's column names", func() {
+		src, err := Generate(dir, []string{"RethinkDbVehicle"})
+		Ω(err).NotTo(HaveOccurred())
+
+		out := string(src)
+		Ω(out).Should(HavePrefix(Header))
+		Ω(out).Should(ContainSubstring("package models"))
+		Ω(out).Should(ContainSubstring(`ID:        goar.StringField{Field: goar.Field{Key: "id"}}`))
+		Ω(out).Should(ContainSubstring(`Make:      goar.StringField{Field: goar.Field{Key: "make"}}`))
+		Ω(out).Should(ContainSubstring(`Year:      goar.IntField{Field: goar.Field{Key: "Year"}}`))
+		Ω(out).Should(ContainSubstring(`SoldAt:    goar.TimeField{Field: goar.Field{Key: "SoldAt"}}`))
+		Ω(out).Should(ContainSubstring(`Options:   goar.Field{Key: "Options"}`))
+		Ω(out).ShouldNot(ContainSubstring("Notes"))
+		Ω(out).ShouldNot(ContainSubstring("secret"))
+	})
+
+	It("should generate finders", func() {
+		src, err := Generate(dir, []string{"RethinkDbVehicle"})
+		Ω(err).NotTo(HaveOccurred())
+
+		out := string(src)
+		Ω(out).Should(ContainSubstring("func (m *RethinkDbVehicle) FindByMake(value string, results interface{}) error {\n\treturn m.Where(RethinkDbVehicleQ.Make.Eq(value)).Run(results)"))
+		Ω(out).Should(ContainSubstring("FindBySoldAt(value time.Time, results interface{})"))
+		Ω(out).Should(ContainSubstring("FindByOptions(value interface{}, results interface{})"))
+		Ω(out).ShouldNot(ContainSubstring("FindByID"))
+		Ω(out).Should(ContainSubstring(`"time"`))
+	})
+
+	It("should name postgres columns like gorm", func() {
+		src, err := Generate(dir, []string{"PostgresVehicle"})
+		Ω(err).NotTo(HaveOccurred())
+
+		out := string(src)
+		Ω(out).Should(ContainSubstring(`goar.FloatField{Field: goar.Field{Key: "list_price"}}`))
+		Ω(out).Should(ContainSubstring(`goar.Int64Field{Field: goar.Field{Key: "safety_rating"}}`))
+		Ω(out).Should(ContainSubstring(`goar.TimeField{Field: goar.Field{Key: "created_at"}}`))
+		Ω(out).Should(ContainSubstring("FindByCertified(value bool, results interface{})"))
+	})
+
+	It("should default to every model that isn't embedded", func() {
+		src, err := Generate(dir, nil)
+		Ω(err).NotTo(HaveOccurred())
+
+		out := string(src)
+		Ω(out).Should(ContainSubstring("goar-query -type=PostgresVehicle,RethinkDbVehicle"))
+		Ω(out).ShouldNot(ContainSubstring("var VehicleQ"))
+		Ω(out).ShouldNot(ContainSubstring("NotAModelQ"))
+		Ω(out).ShouldNot(ContainSubstring("StaleQ"))
+	})
+
+	It("should reject unknown types and non models", func() {
+		_, err := Generate(dir, []string{"Boat"})
+		Ω(err).Should(HaveOccurred())
+
+		_, err = Generate(dir, []string{"NotAModel"})
+		Ω(err).Should(MatchError(ContainSubstring("doesn't embed an adapter's type")))
+	})
+
+	It("should name the output after the first type", func() {
+		Ω(Output("models", []string{"RethinkDbVehicle", "PostgresVehicle"})).Should(Equal("models/rethink_db_vehicle_query.go"))
+	})
+})
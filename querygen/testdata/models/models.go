@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	goar "github.com/obieq/goar"
+	"github.com/obieq/goar/db/postgres"
+	"github.com/obieq/goar/db/rethinkdb"
+)
+
+type Vehicle struct {
+	Make  string `gorethink:"make,omitempty"`
+	Year  int
+	Price float64 `gorm:"column:list_price"`
+}
+
+type RethinkDbVehicle struct {
+	rethinkdb.ArRethinkDb
+	Vehicle
+	SoldAt  *time.Time
+	Options []string
+	Notes   string `gorethink:"-"`
+	secret  string
+}
+
+type PostgresVehicle struct {
+	postgres.ArPostgres
+	Vehicle
+	SafetyRating int64
+	Certified    bool
+}
+
+// NotAModel doesn't embed an adapter's type
+type NotAModel struct {
+	goar.Timestamps
+	Name string
+}
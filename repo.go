@@ -0,0 +1,220 @@
+//go:build go1.18
+// +build go1.18
+
+package goar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrRecordNotFound is returned by First when no record matches
+var ErrRecordNotFound = errors.New("record not found")
+
+// Model is satisfied by a pointer to a model struct, EX: *Vehicle
+type Model[T any] interface {
+	*T
+	ActiveRecordInterfacer
+}
+
+// Repo wraps a model's Persister with typed results, so call sites neither pass
+// interface{} out params nor type assert ToAR's result, EX:
+//
+//	vehicles := goar.NewRepo[Vehicle]()
+//	v, err := vehicles.Find(ctx, "vin-1")
+//	list, err := vehicles.Where(VehicleQ.Year.Gte(2010)).Order(VehicleQ.Year.Desc()).All(ctx)
+//	err = vehicles.Save(ctx, v)
+//
+// NOTE: the adapters don't take a context yet, so ctx is checked before each call and is
+// what the model's Context() returns, EX: for versioning's actor.
+type Repo[T any, PT Model[T]] struct {
+	connName string
+	connEnv  string
+}
+
+// RepoQuery is a Repo's query, built up via Where, Order and Limit
+type RepoQuery[T any, PT Model[T]] struct {
+	repo  *Repo[T, PT]
+	query *Query
+}
+
+// InvalidRecordError is returned by Save when the model fails validation
+type InvalidRecordError struct {
+	Model  string
+	Errors []FieldError
+}
+
+func (e *InvalidRecordError) Error() string {
+	return fmt.Sprintf("%s failed validation with %d error(s)", e.Model, len(e.Errors))
+}
+
+func NewRepo[T any, PT Model[T]]() *Repo[T, PT] {
+	return &Repo[T, PT]{}
+}
+
+// UseConnection routes the repo's models to the connection, like ActiveRecord.UseConnection
+func (r *Repo[T, PT]) UseConnection(connName string, env string) *Repo[T, PT] {
+	r.connName = connName
+	r.connEnv = env
+	return r
+}
+
+// New returns a blank model, ready to save
+func (r *Repo[T, PT]) New(ctx context.Context) *T {
+	return (*T)(r.model(ctx, nil))
+}
+
+func (r *Repo[T, PT]) Find(ctx context.Context, id interface{}) (*T, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m := r.model(ctx, nil)
+	if err := m.Find(id, m); err != nil {
+		return nil, err
+	}
+
+	return (*T)(m), nil
+}
+
+// All returns every record, which some adapters don't support, EX: dynamodb
+func (r *Repo[T, PT]) All(ctx context.Context) ([]T, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	results := []T{}
+	if err := r.model(ctx, nil).All(&results, nil); err != nil {
+		return nil, err
+	}
+
+	return r.models(ctx, results), nil
+}
+
+func (r *Repo[T, PT]) Where(conditions ...QueryCondition) *RepoQuery[T, PT] {
+	return (&RepoQuery[T, PT]{repo: r, query: NewQuery()}).Where(conditions...)
+}
+
+func (r *Repo[T, PT]) Order(orderBy OrderBy) *RepoQuery[T, PT] {
+	return r.Where().Order(orderBy)
+}
+
+// Save validates and saves the model, returning an *InvalidRecordError when it's invalid
+func (r *Repo[T, PT]) Save(ctx context.Context, record *T) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m := r.model(ctx, record)
+	success, err := m.Save()
+	if err != nil {
+		return err
+	} else if !success {
+		invalid := &InvalidRecordError{Model: m.ModelName()}
+		if ar, ok := activeRecordOf(m); ok {
+			invalid.Errors = ar.FieldErrors()
+		}
+		return invalid
+	}
+
+	return nil
+}
+
+func (r *Repo[T, PT]) Delete(ctx context.Context, record *T) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.model(ctx, record).Delete()
+}
+
+// model readies the record, or a blank one, for a db operation.
+// NOTE: a copied record's Self is the original, so ToAR is always called.
+func (r *Repo[T, PT]) model(ctx context.Context, record *T) PT {
+	if record == nil {
+		record = new(T)
+	}
+
+	m := PT(record)
+	ToAR(m)
+	if ar, ok := activeRecordOf(m); ok {
+		if r.connName != "" || r.connEnv != "" {
+			ar.UseConnection(r.connName, r.connEnv)
+		}
+		ar.WithContext(ctx)
+	}
+
+	return m
+}
+
+// models readies the results, so they can be saved or deleted w/o ToAR
+func (r *Repo[T, PT]) models(ctx context.Context, results []T) []T {
+	for i := range results {
+		r.model(ctx, &results[i])
+	}
+
+	return results
+}
+
+func (q *RepoQuery[T, PT]) Where(conditions ...QueryCondition) *RepoQuery[T, PT] {
+	q.query.WhereConditions = append(q.query.WhereConditions, conditions...)
+	return q
+}
+
+func (q *RepoQuery[T, PT]) Order(orderBy OrderBy) *RepoQuery[T, PT] {
+	q.query.OrderBys = append(q.query.OrderBys, orderBy)
+	return q
+}
+
+func (q *RepoQuery[T, PT]) Limit(limit int) *RepoQuery[T, PT] {
+	q.query.Limit = strconv.Itoa(limit)
+	return q
+}
+
+// All runs the query via the model's DbSearch.  Adapters that can't search return an
+// *UnsupportedError, EX: cloudant, rather than no results.
+func (q *RepoQuery[T, PT]) All(ctx context.Context) ([]T, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m := q.repo.model(ctx, nil)
+	m.SetQuery(q.query)
+
+	results := []T{}
+	if err := m.Run(&results); err != nil {
+		return nil, err
+	}
+
+	return q.repo.models(ctx, results), nil
+}
+
+// First returns the query's first record, or ErrRecordNotFound.
+// NOTE: the query's own limit is kept, so it can still be run via All.
+func (q *RepoQuery[T, PT]) First(ctx context.Context) (*T, error) {
+	first := &RepoQuery[T, PT]{repo: q.repo, query: copyQuery(q.query)}
+	results, err := first.Limit(1).All(ctx)
+	if err != nil {
+		return nil, err
+	} else if len(results) == 0 {
+		return nil, ErrRecordNotFound
+	}
+
+	return &results[0], nil
+}
+
+// copyQuery copies the query's conditions, orders and aggregations, so the copy can be changed
+func copyQuery(query *Query) *Query {
+	copied := *query
+	copied.Plucks = append([]interface{}{}, query.Plucks...)
+	copied.WhereConditions = append([]QueryCondition{}, query.WhereConditions...)
+	copied.OrderBys = append([]OrderBy{}, query.OrderBys...)
+	copied.Aggregations = map[EnumAggregations][]interface{}{}
+	for aggregation, fields := range query.Aggregations {
+		copied.Aggregations[aggregation] = append([]interface{}{}, fields...)
+	}
+
+	return &copied
+}
@@ -0,0 +1,199 @@
+//go:build go1.18
+// +build go1.18
+
+package goar
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+
+	. "github.com/obieq/goar/tests/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type RepoAutomobile struct {
+	ActiveRecordAutomobile
+	ID string `json:"id,omitempty"`
+}
+
+var repoAutomobileDb = map[string]RepoAutomobile{}
+
+// UnsearchableAutomobile's adapter can't search, like cloudant's
+type UnsearchableAutomobile struct {
+	ActiveRecordAutomobile
+	ID string `json:"id,omitempty"`
+}
+
+func (m *UnsearchableAutomobile) DbSearch(results interface{}) error {
+	return &UnsupportedError{Adapter: CLOUDANT, Operation: "DbSearch"}
+}
+
+func (m *RepoAutomobile) Find(id interface{}, out interface{}) error {
+	row, found := repoAutomobileDb[id.(string)]
+	if !found {
+		return errors.New("record not found")
+	}
+
+	out.(*RepoAutomobile).ID = row.ID
+	out.(*RepoAutomobile).Vehicle = row.Vehicle
+	return nil
+}
+
+func (m *RepoAutomobile) All(results interface{}, opts map[string]interface{}) error {
+	return m.DbSearch(results)
+}
+
+func (m *RepoAutomobile) DbSave() error {
+	repoAutomobileDb[m.ID] = *m
+	return nil
+}
+
+func (m *RepoAutomobile) DbDelete() error {
+	delete(repoAutomobileDb, m.ID)
+	return nil
+}
+
+// DbSearch supports EQ and GTE conditions on ints, and orders by ID
+func (m *RepoAutomobile) DbSearch(results interface{}) error {
+	ids := []string{}
+	for id := range repoAutomobileDb {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	out := results.(*[]RepoAutomobile)
+	for _, id := range ids {
+		row := reflect.ValueOf(repoAutomobileDb[id])
+		matches := true
+		for _, where := range m.Query().WhereConditions {
+			value := row.FieldByName(where.Key).Interface()
+			switch where.RelationalOperator {
+			case EQ:
+				matches = matches && value == where.Value
+			case GTE:
+				matches = matches && value.(int) >= where.Value.(int)
+			}
+		}
+		if matches && (m.Query().Limit == "" || len(*out) < 1) {
+			*out = append(*out, repoAutomobileDb[id])
+		}
+	}
+
+	return nil
+}
+
+var _ = Describe("Repo", func() {
+	var (
+		ctx      context.Context
+		autos    *Repo[RepoAutomobile, *RepoAutomobile]
+		year     = IntField{Field{Key: "Year"}}
+		autoMake = StringField{Field{Key: "Make"}}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		autos = NewRepo[RepoAutomobile]()
+		repoAutomobileDb = map[string]RepoAutomobile{
+			"1": {ID: "1", ActiveRecordAutomobile: ActiveRecordAutomobile{ActiveRecordVehicle{Vehicle: Vehicle{Year: 1960, Make: "austin healey", Model: "3000"}}}},
+			"2": {ID: "2", ActiveRecordAutomobile: ActiveRecordAutomobile{ActiveRecordVehicle{Vehicle: Vehicle{Year: 2014, Make: "tesla", Model: "model s"}}}},
+			"3": {ID: "3", ActiveRecordAutomobile: ActiveRecordAutomobile{ActiveRecordVehicle{Vehicle: Vehicle{Year: 2015, Make: "tesla", Model: "model x"}}}},
+		}
+	})
+
+	It("should find a typed record", func() {
+		auto, err := autos.Find(ctx, "2")
+		Ω(err).NotTo(HaveOccurred())
+		Ω(auto.Model).Should(Equal("model s"))
+		Ω(auto.Self()).Should(Equal(auto))
+
+		_, err = autos.Find(ctx, "4")
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("should query typed records", func() {
+		all, err := autos.All(ctx)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(all).Should(HaveLen(3))
+
+		teslas, err := autos.Where(autoMake.Eq("tesla"), year.Gte(2015)).All(ctx)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(teslas).Should(HaveLen(1))
+		Ω(teslas[0].Model).Should(Equal("model x"))
+	})
+
+	It("should return the first record", func() {
+		auto, err := autos.Where(autoMake.Eq("tesla")).First(ctx)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(auto.ID).Should(Equal("2"))
+
+		_, err = autos.Where(autoMake.Eq("ford")).First(ctx)
+		Ω(err).Should(Equal(ErrRecordNotFound))
+	})
+
+	It("should keep the query's limit when returning the first record", func() {
+		teslas := autos.Where(autoMake.Eq("tesla"))
+		_, err := teslas.First(ctx)
+		Ω(err).NotTo(HaveOccurred())
+
+		all, err := teslas.All(ctx)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(all).Should(HaveLen(2))
+	})
+
+	It("should return an adapter's unsupported error rather than no results", func() {
+		unsearchable := NewRepo[UnsearchableAutomobile]()
+		_, err := unsearchable.Where(autoMake.Eq("tesla")).All(ctx)
+		Ω(err).Should(MatchError("cloudant does not support DbSearch"))
+
+		_, err = unsearchable.Where(autoMake.Eq("tesla")).First(ctx)
+		Ω(err).Should(BeAssignableToTypeOf(&UnsupportedError{}))
+	})
+
+	It("should save and delete", func() {
+		auto := autos.New(ctx)
+		auto.ID, auto.Year, auto.Make, auto.Model = "4", 1967, "ford", "mustang"
+		Ω(autos.Save(ctx, auto)).Should(Succeed())
+		Ω(repoAutomobileDb).Should(HaveKey("4"))
+
+		found, err := autos.Where(autoMake.Eq("ford")).First(ctx)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(autos.Delete(ctx, found)).Should(Succeed())
+		Ω(repoAutomobileDb).ShouldNot(HaveKey("4"))
+	})
+
+	It("should save a copy of a record, rather than the original", func() {
+		found, err := autos.Find(ctx, "2")
+		Ω(err).NotTo(HaveOccurred())
+
+		copied := *found
+		copied.ID, copied.Model = "6", "roadster"
+		Ω(autos.Save(ctx, &copied)).Should(Succeed())
+		Ω(repoAutomobileDb["6"].Model).Should(Equal("roadster"))
+		Ω(repoAutomobileDb["2"].Model).Should(Equal("model s"))
+	})
+
+	It("should return the validation errors of an invalid record", func() {
+		err := autos.Save(ctx, &RepoAutomobile{ID: "5"})
+		Ω(err).Should(HaveOccurred())
+
+		invalid, ok := err.(*InvalidRecordError)
+		Ω(ok).Should(BeTrue())
+		Ω(invalid.Errors).Should(HaveLen(2))
+		Ω(repoAutomobileDb).ShouldNot(HaveKey("5"))
+	})
+
+	It("should pass the context to the model and stop once it's canceled", func() {
+		auto, err := autos.Find(WithActor(ctx, "obie"), "1")
+		Ω(err).NotTo(HaveOccurred())
+		Ω(ActorFromContext(auto.Context())).Should(Equal("obie"))
+
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err = autos.Find(canceled, "1")
+		Ω(err).Should(Equal(context.Canceled))
+		Ω(autos.Save(canceled, auto)).Should(Equal(context.Canceled))
+	})
+})
@@ -0,0 +1,80 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const usage = "usage: schema diff [migrations/<version>_<name>.go]"
+
+// ErrDrift is returned by Command when the live database has drifted from the models,
+// so a CI step running the diff fails
+var ErrDrift = errors.New("schema drift detected")
+
+// Command runs a schema command against the registered models, EX:
+//
+//	schema.Register(&Vehicle{}, &Dealer{})
+//	d := postgres.NewMigrationDriver("default", os.Getenv("GOAR_ENV"))
+//	if err := schema.Command(d, os.Args[1:], os.Stdout); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// diff reports each difference, and writes a migration that fixes them when given a file,
+// EX: schema diff migrations/20151014093000_fix_drift.go
+func Command(in Inspector, args []string, out io.Writer) error {
+	if len(args) == 0 || len(args) > 2 || args[0] != "diff" {
+		return fmt.Errorf(usage)
+	}
+
+	diffs, err := DiffModels(in)
+	if err != nil {
+		return err
+	}
+
+	if len(diffs) == 0 {
+		fmt.Fprintln(out, "schema is up to date")
+		return nil
+	}
+
+	for _, d := range diffs {
+		fmt.Fprintln(out, d)
+	}
+
+	if len(args) == 2 {
+		if err = writeMigrationFile(args[1], diffs); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "wrote %s\n", args[1])
+	}
+
+	return ErrDrift
+}
+
+// writeMigrationFile takes the package from the file's directory,
+// and the version and name from the file's name
+func writeMigrationFile(path string, diffs []Difference) error {
+	base := strings.TrimSuffix(filepath.Base(path), ".go")
+	parts := strings.SplitN(base, "_", 2)
+	version, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || version <= 0 || len(parts) < 2 {
+		return fmt.Errorf("invalid migration file %q, %s", path, usage)
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return WriteMigration(f, filepath.Base(dir), version, parts[1], diffs)
+}
@@ -0,0 +1,64 @@
+package schema
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Command", func() {
+	var (
+		in  *fakeInspector
+		out *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		Register(&KeyedVehicle{})
+		in = &fakeInspector{dialect: Postgres, tables: map[string]*Table{}}
+		out = &bytes.Buffer{}
+	})
+
+	AfterEach(func() {
+		resetModels()
+	})
+
+	It("should require the diff command", func() {
+		Ω(Command(in, []string{}, out)).Should(MatchError(usage))
+		Ω(Command(in, []string{"drop"}, out)).Should(MatchError(usage))
+	})
+
+	It("should report an up to date schema", func() {
+		in.tables["keyed_vehicles"] = Of(&KeyedVehicle{}, Postgres)
+		Ω(Command(in, []string{"diff"}, out)).Should(Succeed())
+		Ω(out.String()).Should(Equal("schema is up to date\n"))
+	})
+
+	It("should fail on drift", func() {
+		Ω(Command(in, []string{"diff"}, out)).Should(Equal(ErrDrift))
+		Ω(out.String()).Should(Equal("missing table: keyed_vehicles\n"))
+	})
+
+	It("should write a migration that fixes the drift", func() {
+		dir, err := ioutil.TempDir("", "migrations")
+		Ω(err).ShouldNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "20151014093000_fix_drift.go")
+		Ω(Command(in, []string{"diff", path}, out)).Should(Equal(ErrDrift))
+		Ω(out.String()).Should(HaveSuffix("wrote " + path + "\n"))
+
+		src, err := ioutil.ReadFile(path)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(src)).Should(HavePrefix("package " + filepath.Base(dir) + "\n"))
+		Ω(string(src)).Should(ContainSubstring(`migration.Register(20151014093000, "fix_drift"`))
+		Ω(string(src)).Should(ContainSubstring(`exec("CREATE TABLE \"keyed_vehicles\"`))
+	})
+
+	It("should require a versioned migration file", func() {
+		Ω(Command(in, []string{"diff", "migrations/fix_drift.go"}, out)).Should(HaveOccurred())
+	})
+})
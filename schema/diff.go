@@ -0,0 +1,184 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	goar "github.com/obieq/goar"
+)
+
+// Inspector reads a table's live schema.  Each adapter's MigrationDriver implements it,
+// via postgres' information_schema, mssql's sys.columns, rethinkdb's TableList and IndexList,
+// and dynamodb's DescribeTable.
+type Inspector interface {
+	Dialect() Dialect
+	InspectTable(name string) (*Table, error) // nil if the table doesn't exist
+}
+
+type DifferenceKind string
+
+const (
+	MissingTable        DifferenceKind = "missing table"
+	MissingColumn       DifferenceKind = "missing column"
+	TypeMismatch        DifferenceKind = "type mismatch"
+	NullabilityMismatch DifferenceKind = "nullability mismatch"
+	MissingIndex        DifferenceKind = "missing index"
+)
+
+// Difference is a way the live database has drifted from a model
+type Difference struct {
+	Kind     DifferenceKind
+	Table    *Table // the model's expected table
+	Column   *Column
+	Index    *Index
+	Expected string
+	Actual   string
+}
+
+func (d Difference) String() string {
+	switch {
+	case d.Column != nil && d.Expected != "":
+		return fmt.Sprintf("%s: %s.%s is %s, expected %s", d.Kind, d.Table.Name, d.Column.Name, d.Actual, d.Expected)
+	case d.Column != nil:
+		return fmt.Sprintf("%s: %s.%s", d.Kind, d.Table.Name, d.Column.Name)
+	case d.Index != nil:
+		return fmt.Sprintf("%s: %s.%s (%s)", d.Kind, d.Table.Name, d.Index.Name, strings.Join(d.Index.Columns, ", "))
+	}
+
+	return fmt.Sprintf("%s: %s", d.Kind, d.Table.Name)
+}
+
+var (
+	models      = map[reflect.Type]goar.ActiveRecordInterfacer{}
+	modelsMutex sync.RWMutex
+)
+
+// Register adds models to the set DiffModels checks by default
+func Register(ari ...goar.ActiveRecordInterfacer) {
+	modelsMutex.Lock()
+	defer modelsMutex.Unlock()
+
+	for _, m := range ari {
+		models[reflect.TypeOf(m)] = m
+	}
+}
+
+// Registered returns the registered models, sorted by type name
+func Registered() []goar.ActiveRecordInterfacer {
+	modelsMutex.RLock()
+	defer modelsMutex.RUnlock()
+
+	registered := []goar.ActiveRecordInterfacer{}
+	for _, m := range models {
+		registered = append(registered, m)
+	}
+	sort.Sort(byTypeName(registered))
+
+	return registered
+}
+
+// DiffModels compares the models, or every registered model, with the live database
+func DiffModels(in Inspector, ari ...goar.ActiveRecordInterfacer) ([]Difference, error) {
+	if len(ari) == 0 {
+		ari = Registered()
+	}
+
+	diffs := []Difference{}
+	for _, m := range ari {
+		expected := Of(m, in.Dialect())
+		actual, err := in.InspectTable(expected.Name)
+		if err != nil {
+			return nil, fmt.Errorf("could not inspect %s: %v", expected.Name, err)
+		}
+		diffs = append(diffs, Diff(expected, actual)...)
+	}
+
+	return diffs, nil
+}
+
+// Diff compares a model's expected table with the live one, which is nil if it doesn't exist.
+// rethinkdb is schemaless, so only its indexes are compared, and only dynamodb's key attributes are.
+func Diff(expected *Table, actual *Table) []Difference {
+	if actual == nil {
+		return []Difference{{Kind: MissingTable, Table: expected}}
+	}
+
+	diffs := []Difference{}
+	for i := range expected.Columns {
+		c := &expected.Columns[i]
+		if expected.Dialect == RethinkDB || (expected.Dialect == DynamoDB && c.KeyType == "") {
+			continue
+		}
+
+		live := actual.Column(c.Name)
+		switch {
+		case live == nil:
+			diffs = append(diffs, Difference{Kind: MissingColumn, Table: expected, Column: c})
+		case normalizeType(c.Type) != normalizeType(live.Type) || c.KeyType != live.KeyType:
+			diffs = append(diffs, Difference{Kind: TypeMismatch, Table: expected, Column: c, Expected: describeType(c), Actual: describeType(live)})
+		case expected.Dialect != DynamoDB && c.Nullable != live.Nullable:
+			diffs = append(diffs, Difference{Kind: NullabilityMismatch, Table: expected, Column: c, Expected: nullability(c), Actual: nullability(live)})
+		}
+	}
+
+	for i := range expected.Indexes {
+		if !hasIndex(actual, expected.Indexes[i]) {
+			diffs = append(diffs, Difference{Kind: MissingIndex, Table: expected, Index: &expected.Indexes[i]})
+		}
+	}
+
+	return diffs
+}
+
+// hasIndex matches an index by its columns, whatever it's named, or by its name
+// when the database doesn't report the columns, EX: rethinkdb
+func hasIndex(t *Table, index Index) bool {
+	for _, live := range t.Indexes {
+		if strings.Join(live.Columns, ",") == strings.Join(index.Columns, ",") || (len(live.Columns) == 0 && live.Name == index.Name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeType maps the types a dialect creates onto the types it reports, EX: serial => integer
+func normalizeType(t string) string {
+	t = strings.ToLower(strings.TrimSpace(t))
+	t = strings.Replace(t, " identity(1,1)", "", 1)
+	t = strings.Replace(t, "character varying", "varchar", 1)
+
+	switch t {
+	case "serial":
+		return "integer"
+	case "bigserial":
+		return "bigint"
+	}
+
+	return t
+}
+
+func describeType(c *Column) string {
+	if c.KeyType != "" {
+		return c.Type + " " + c.KeyType
+	}
+	return c.Type
+}
+
+func nullability(c *Column) string {
+	if c.Nullable {
+		return "NULL"
+	}
+	return "NOT NULL"
+}
+
+type byTypeName []goar.ActiveRecordInterfacer
+
+func (a byTypeName) Len() int      { return len(a) }
+func (a byTypeName) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byTypeName) Less(i, j int) bool {
+	return reflect.TypeOf(a[i]).String() < reflect.TypeOf(a[j]).String()
+}
@@ -0,0 +1,79 @@
+package schema
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Diff", func() {
+	var live *Table
+
+	BeforeEach(func() {
+		live = Of(&SchemaVehicle{}, Postgres)
+		live.Column("id").Type = "integer"
+		live.Column("vin").Type = "character varying(17)"
+	})
+
+	It("should not report a table that matches its model", func() {
+		Ω(Diff(Of(&SchemaVehicle{}, Postgres), live)).Should(BeEmpty())
+	})
+
+	It("should report a missing table", func() {
+		diffs := Diff(Of(&SchemaVehicle{}, Postgres), nil)
+		Ω(diffs).Should(HaveLen(1))
+		Ω(diffs[0].Kind).Should(Equal(MissingTable))
+		Ω(diffs[0].String()).Should(Equal("missing table: vehicles"))
+	})
+
+	It("should report missing columns, type and nullability mismatches", func() {
+		live.Columns = live.Columns[1:] // id
+		live.Column("year").Type = "integer"
+		live.Column("make").Nullable = true
+
+		diffs := Diff(Of(&SchemaVehicle{}, Postgres), live)
+		Ω(diffs).Should(HaveLen(3))
+		Ω(diffs[0].String()).Should(Equal("missing column: vehicles.id"))
+		Ω(diffs[1].String()).Should(Equal("nullability mismatch: vehicles.make is NULL, expected NOT NULL"))
+		Ω(diffs[2].String()).Should(Equal("type mismatch: vehicles.year is integer, expected bigint"))
+	})
+
+	It("should match indexes by their columns, whatever they're named", func() {
+		live.Indexes[0].Name = "vehicles_vin_key"
+		live.Indexes = live.Indexes[:1]
+
+		diffs := Diff(Of(&SchemaVehicle{}, Postgres), live)
+		Ω(diffs).Should(HaveLen(1))
+		Ω(diffs[0].String()).Should(Equal("missing index: vehicles.idx_make_model (make, model)"))
+	})
+
+	It("should only compare rethinkdb indexes, by name", func() {
+		live = &Table{Name: "schema_vehicles", Dialect: RethinkDB, Indexes: []Index{{Name: "Make_Model"}}}
+
+		diffs := Diff(Of(&SchemaVehicle{}, RethinkDB), live)
+		Ω(diffs).Should(HaveLen(1))
+		Ω(diffs[0].Index.Name).Should(Equal("VIN"))
+	})
+
+	It("should only compare dynamodb key attributes", func() {
+		live = &Table{Name: "keyed_vehicles", Dialect: DynamoDB, Columns: []Column{
+			{Name: "dealer", Type: "S", KeyType: "HASH"},
+			{Name: "vin", Type: "S", KeyType: "HASH"},
+		}}
+
+		diffs := Diff(Of(&KeyedVehicle{}, DynamoDB), live)
+		Ω(diffs).Should(HaveLen(1))
+		Ω(diffs[0].String()).Should(Equal("type mismatch: keyed_vehicles.vin is S HASH, expected S RANGE"))
+	})
+
+	It("should diff the registered models against an inspector", func() {
+		defer resetModels()
+		Register(&SchemaVehicle{}, &KeyedVehicle{})
+		Ω(Registered()).Should(HaveLen(2))
+
+		in := &fakeInspector{dialect: Postgres, tables: map[string]*Table{"vehicles": live}}
+		diffs, err := DiffModels(in)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(diffs).Should(HaveLen(1))
+		Ω(diffs[0].String()).Should(Equal("missing table: keyed_vehicles"))
+	})
+})
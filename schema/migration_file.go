@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strings"
+)
+
+// WriteMigration writes a go migration file that fixes the differences, EX:
+//
+//	schema.WriteMigration(f, "migrations", 20151014093000, "fix_drift", diffs)
+//
+// Drift that can't be fixed in place, EX: a dynamodb key change, is left as a TODO comment.
+// The migration can't be rolled back, so its down func is nil.
+func WriteMigration(w io.Writer, pkg string, version int64, name string, diffs []Difference) error {
+	body := &bytes.Buffer{}
+	for _, d := range diffs {
+		writeFix(body, d)
+	}
+
+	src := &bytes.Buffer{}
+	fmt.Fprintf(src, "package %s\n\n", pkg)
+	fmt.Fprintf(src, "import \"github.com/obieq/goar/migration\"\n\n")
+	fmt.Fprintf(src, "// generated by goar schema diff\n")
+	fmt.Fprintf(src, "func init() {\n")
+	fmt.Fprintf(src, "migration.Register(%d, %q, func(d migration.Driver) error {\n", version, name)
+	if strings.Contains(body.String(), "exec(") {
+		fmt.Fprintf(src, "exec := d.(interface {\nExec(sql string, values ...interface{}) error\n}).Exec\n")
+	}
+	src.Write(body.Bytes())
+	fmt.Fprintf(src, "return nil\n}, nil)\n}\n")
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(formatted)
+	return err
+}
+
+func writeFix(w io.Writer, d Difference) {
+	t := d.Table
+
+	switch t.Dialect {
+	case Postgres, MSSQL:
+		for _, statement := range fixSQL(d) {
+			fmt.Fprintf(w, "if err := exec(%q); err != nil {\nreturn err\n}\n", statement)
+		}
+	case RethinkDB:
+		switch d.Kind {
+		case MissingTable:
+			fmt.Fprintf(w, "if err := d.CreateTable(%q); err != nil {\nreturn err\n}\n", t.Name)
+			for _, index := range t.Indexes {
+				writeAddIndex(w, t, index)
+			}
+		case MissingIndex:
+			writeAddIndex(w, t, *d.Index)
+		}
+	case DynamoDB:
+		fmt.Fprintf(w, "// TODO: %s, recreate the table via MigrationDriver.CreateTableFor\n", d)
+	}
+}
+
+func writeAddIndex(w io.Writer, t *Table, index Index) {
+	fmt.Fprintf(w, "if err := d.AddIndex(%q, %#v, nil); err != nil {\nreturn err\n}\n", t.Name, index.Columns)
+}
+
+// fixSQL returns the statements that fix a sql difference
+func fixSQL(d Difference) []string {
+	t := d.Table
+
+	switch d.Kind {
+	case MissingTable:
+		return t.SQL()
+	case MissingColumn:
+		column := "COLUMN "
+		if t.Dialect == MSSQL {
+			column = ""
+		}
+		return []string{fmt.Sprintf("ALTER TABLE %s ADD %s%s", t.quote(t.Name), column, columnDefinition(t, d.Column))}
+	case TypeMismatch, NullabilityMismatch:
+		if t.Dialect == MSSQL {
+			return []string{fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s", t.quote(t.Name), columnDefinition(t, d.Column))}
+		}
+
+		name := t.quote(t.Name) + " ALTER COLUMN " + t.quote(d.Column.Name)
+		if d.Kind == TypeMismatch {
+			return []string{fmt.Sprintf("ALTER TABLE %s TYPE %s", name, normalizeType(d.Column.Type))}
+		} else if d.Column.Nullable {
+			return []string{fmt.Sprintf("ALTER TABLE %s DROP NOT NULL", name)}
+		}
+		return []string{fmt.Sprintf("ALTER TABLE %s SET NOT NULL", name)}
+	case MissingIndex:
+		copy := *t
+		copy.Indexes = []Index{*d.Index}
+		return copy.CreateIndexSQL()
+	}
+
+	return nil
+}
+
+// columnDefinition omits mssql's IDENTITY, which can't be added to an existing column
+func columnDefinition(t *Table, c *Column) string {
+	definition := t.quote(c.Name) + " " + strings.Replace(c.Type, " IDENTITY(1,1)", "", 1)
+	if c.Nullable {
+		return definition + " NULL"
+	}
+	return definition + " NOT NULL"
+}
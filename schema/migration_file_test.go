@@ -0,0 +1,55 @@
+package schema
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Migration File", func() {
+
+	generate := func(diffs []Difference) string {
+		out := &bytes.Buffer{}
+		Ω(WriteMigration(out, "migrations", 20151014093000, "fix_drift", diffs)).Should(Succeed())
+		return out.String()
+	}
+
+	It("should register a migration that fixes sql drift", func() {
+		t := Of(&SchemaVehicle{}, Postgres)
+		src := generate([]Difference{
+			{Kind: MissingColumn, Table: t, Column: t.Column("vin")},
+			{Kind: TypeMismatch, Table: t, Column: t.Column("year")},
+			{Kind: NullabilityMismatch, Table: t, Column: t.Column("make")},
+			{Kind: MissingIndex, Table: t, Index: &t.Indexes[1]},
+		})
+
+		Ω(src).Should(HavePrefix("package migrations\n"))
+		Ω(src).Should(ContainSubstring(`migration.Register(20151014093000, "fix_drift", func(d migration.Driver) error {`))
+		Ω(src).Should(ContainSubstring(`exec("ALTER TABLE \"vehicles\" ADD COLUMN \"vin\" varchar(17) NOT NULL")`))
+		Ω(src).Should(ContainSubstring(`exec("ALTER TABLE \"vehicles\" ALTER COLUMN \"year\" TYPE bigint")`))
+		Ω(src).Should(ContainSubstring(`exec("ALTER TABLE \"vehicles\" ALTER COLUMN \"make\" SET NOT NULL")`))
+		Ω(src).Should(ContainSubstring(`exec("CREATE INDEX \"idx_make_model\" ON \"vehicles\" (\"make\", \"model\")")`))
+		Ω(src).Should(HaveSuffix("}, nil)\n}\n"))
+	})
+
+	It("should alter mssql columns without their identity", func() {
+		t := Of(&SchemaVehicle{}, MSSQL)
+		src := generate([]Difference{{Kind: TypeMismatch, Table: t, Column: t.Column("i_d")}})
+		Ω(src).Should(ContainSubstring(`exec("ALTER TABLE [vehicles] ALTER COLUMN [i_d] int NOT NULL")`))
+	})
+
+	It("should create rethinkdb tables and indexes via the driver", func() {
+		t := Of(&SchemaVehicle{}, RethinkDB)
+		src := generate([]Difference{{Kind: MissingTable, Table: t}})
+		Ω(src).ShouldNot(ContainSubstring("exec"))
+		Ω(src).Should(ContainSubstring(`d.CreateTable("schema_vehicles")`))
+		Ω(src).Should(ContainSubstring(`d.AddIndex("schema_vehicles", []string{"Make", "Model"}, nil)`))
+	})
+
+	It("should leave dynamodb drift as a todo", func() {
+		t := Of(&KeyedVehicle{}, DynamoDB)
+		src := generate([]Difference{{Kind: MissingTable, Table: t}})
+		Ω(src).Should(ContainSubstring("// TODO: missing table: keyed_vehicles"))
+	})
+})
@@ -0,0 +1,268 @@
+// Package schema reflects models into the tables their adapter reads and writes.
+// Columns are named the way each adapter's library names them: gorm for postgres,
+// xorm for mssql, gorethink for rethinkdb and json for dynamodb.
+//
+// Nullability, sizes, keys and indexes are declared via the schema tag, EX:
+//
+//	type Vehicle struct {
+//		postgres.ArPostgres
+//		VIN     string   `schema:"size=17,unique"`
+//		Make    string   `schema:"index=idx_make_model"`
+//		Model   string   `schema:"index=idx_make_model,null"`
+//		Options []string `schema:"-"`
+//	}
+//
+// Tag rules:
+// pk, null, notnull, size=N and type=T (overrides the column's type),
+// index and unique (single column, or composite when several fields share a name, EX: index=name),
+// hash and range (dynamodb key attributes), ttl (dynamodb's expiry, in epoch seconds), and - (no column).
+// Pointers, slices, maps and interfaces are nullable, everything else defaults to NOT NULL.
+package schema
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	goar "github.com/obieq/goar"
+	"github.com/obieq/goar/naming"
+)
+
+type Dialect string
+
+const (
+	Postgres  Dialect = "postgres"
+	MSSQL     Dialect = "mssql"
+	RethinkDB Dialect = "rethinkdb"
+	DynamoDB  Dialect = "dynamodb"
+)
+
+type Column struct {
+	Name          string
+	Field         string // the struct field, EX: SafetyRating
+	Type          string // in the dialect, EX: varchar(17), or S, N and B for dynamodb
+	Nullable      bool
+	PrimaryKey    bool
+	AutoIncrement bool
+	KeyType       string // dynamodb only: HASH or RANGE
+	TTL           bool   // dynamodb only: the attribute items expire by
+}
+
+type Index struct {
+	Name    string
+	Columns []string
+	Unique  bool
+}
+
+type Table struct {
+	Name    string
+	Dialect Dialect
+	Columns []Column
+	Indexes []Index
+}
+
+// Of reflects the model's struct.  It panics on an invalid schema tag
+// b/c that's a programming error, EX: schema:"size=lots"
+func Of(ari goar.ActiveRecordInterfacer, dialect Dialect) *Table {
+	switch dialect {
+	case Postgres, MSSQL, RethinkDB, DynamoDB:
+	default:
+		log.Panicf("unknown schema dialect: %s", dialect)
+	}
+
+	self := goar.ToAR(ari)
+	t := &Table{Name: tableName(self, dialect), Dialect: dialect, Columns: []Column{}, Indexes: []Index{}}
+
+	indexes := map[string]*Index{}
+	indexNames := []string{} // in declaration order
+	t.addFields(reflect.TypeOf(self).Elem(), func(name string, unique bool, column string) {
+		if indexes[name] == nil {
+			indexes[name] = &Index{Name: name}
+			indexNames = append(indexNames, name)
+		}
+		indexes[name].Columns = append(indexes[name].Columns, column)
+		indexes[name].Unique = indexes[name].Unique || unique
+	})
+
+	for _, name := range indexNames {
+		if dialect == RethinkDB { // rethinkdb queries an index by name, so it's named by its fields, EX: make_model
+			indexes[name].Name = strings.Join(indexes[name].Columns, "_")
+		}
+		t.Indexes = append(t.Indexes, *indexes[name])
+	}
+	t.defaultKeys()
+
+	return t
+}
+
+// PrimaryKey returns the names of the primary key columns
+func (t *Table) PrimaryKey() []string {
+	keys := []string{}
+	for _, c := range t.Columns {
+		if c.PrimaryKey {
+			keys = append(keys, c.Name)
+		}
+	}
+
+	return keys
+}
+
+// Column returns the named column, or nil
+func (t *Table) Column(name string) *Column {
+	for i := range t.Columns {
+		if t.Columns[i].Name == name {
+			return &t.Columns[i]
+		}
+	}
+
+	return nil
+}
+
+// gorm and xorm write to the table named by TableName(), when the model has one
+func tableName(ari goar.ActiveRecordInterfacer, dialect Dialect) string {
+	if tn, ok := ari.(interface {
+		TableName() string
+	}); ok && (dialect == Postgres || dialect == MSSQL) {
+		return tn.TableName()
+	}
+
+	return ari.ModelName()
+}
+
+type addIndexFunc func(name string, unique bool, column string)
+
+func (t *Table) addFields(st reflect.Type, addIndex addIndexFunc) {
+	for i := 0; i < st.NumField(); i++ {
+		f := st.Field(i)
+		if f.Type == reflect.TypeOf(goar.ActiveRecord{}) || skipped(f, t.Dialect) {
+			continue
+		}
+
+		ft := f.Type
+		if f.Anonymous && ft.Kind() == reflect.Struct && ft != reflect.TypeOf(time.Time{}) {
+			t.addFields(ft, addIndex) // EX: Timestamps, or a model embedding its base model
+			continue
+		}
+
+		if f.PkgPath != "" { // unexported
+			continue
+		}
+
+		t.addField(f, addIndex)
+	}
+}
+
+func (t *Table) addField(f reflect.StructField, addIndex addIndexFunc) {
+	c := Column{Name: columnName(f, t.Dialect), Field: f.Name, Nullable: nullable(f.Type)}
+	c.PrimaryKey = c.Name == "id" || f.Name == "ID" || hasTagOption(f.Tag.Get("gorm"), "primary_key") || hasTagOption(f.Tag.Get("xorm"), "pk")
+	c.AutoIncrement = c.PrimaryKey && isInteger(f.Type)
+
+	size := 0
+	for _, rule := range strings.Split(f.Tag.Get("schema"), ",") {
+		name, arg := rule, ""
+		if i := strings.Index(rule, "="); i >= 0 {
+			name, arg = rule[:i], rule[i+1:]
+		}
+
+		var err error
+		switch name = strings.TrimSpace(name); name {
+		case "":
+		case "pk":
+			c.PrimaryKey = true
+		case "null":
+			c.Nullable = true
+		case "notnull":
+			c.Nullable = false
+		case "size":
+			size, err = strconv.Atoi(arg)
+		case "type":
+			c.Type = arg
+		case "index", "unique":
+			if arg == "" {
+				arg = "idx_" + t.Name + "_" + c.Name
+			}
+			addIndex(arg, name == "unique", c.Name)
+		case "hash", "range":
+			c.KeyType = strings.ToUpper(name)
+		case "ttl":
+			c.TTL = true
+		default:
+			err = fmt.Errorf("unknown rule %q", name)
+		}
+
+		if err != nil {
+			log.Panicf("invalid schema tag on %s.%s: %v", t.Name, f.Name, err)
+		}
+	}
+
+	if c.PrimaryKey {
+		c.Nullable = false
+	}
+	if c.Type == "" {
+		c.Type = columnType(f.Type, t.Dialect, size, c.AutoIncrement)
+	}
+
+	t.Columns = append(t.Columns, c)
+}
+
+// defaultKeys makes the primary key dynamodb's hash key, unless the model declared its own keys
+func (t *Table) defaultKeys() {
+	if t.Dialect != DynamoDB {
+		return
+	}
+
+	for _, c := range t.Columns {
+		if c.KeyType != "" {
+			return
+		}
+	}
+
+	for i := range t.Columns {
+		if t.Columns[i].PrimaryKey {
+			t.Columns[i].KeyType = "HASH"
+			return
+		}
+	}
+}
+
+func skipped(f reflect.StructField, dialect Dialect) bool {
+	return naming.Skipped(f.Tag, string(dialect))
+}
+
+func columnName(f reflect.StructField, dialect Dialect) string {
+	return naming.Column(f.Name, f.Tag, string(dialect))
+}
+
+func hasTagOption(tag string, option string) bool {
+	for _, o := range strings.FieldsFunc(tag, func(r rune) bool { return r == ';' || r == ' ' || r == ',' }) {
+		if strings.EqualFold(o, option) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func nullable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Uint8 // []byte
+	}
+
+	return false
+}
+
+func isInteger(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}
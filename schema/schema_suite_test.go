@@ -0,0 +1,50 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	goar "github.com/obieq/goar"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestSchema(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Schema Suite")
+}
+
+// schemaModel stubs the ActiveRecordInterfacer methods an adapter would provide
+type schemaModel struct {
+	goar.ActiveRecord
+	ID int `gorm:"primary_key" json:"id,omitempty"`
+	goar.Timestamps
+}
+
+func (m *schemaModel) SetKey(key string)                                          {}
+func (m *schemaModel) All(results interface{}, opts map[string]interface{}) error { return nil }
+func (m *schemaModel) Truncate() (int, error)                                     { return 0, nil }
+func (m *schemaModel) Find(id interface{}, out interface{}) error                 { return nil }
+func (m *schemaModel) DBConnectionName() string                                   { return "aws" }
+func (m *schemaModel) DBConnectionEnvironment() string                            { return "test" }
+func (m *schemaModel) Validate()                                                  {}
+
+// fakeInspector serves live tables from memory
+type fakeInspector struct {
+	dialect Dialect
+	tables  map[string]*Table
+}
+
+func (in *fakeInspector) Dialect() Dialect {
+	return in.dialect
+}
+
+func (in *fakeInspector) InspectTable(name string) (*Table, error) {
+	return in.tables[name], nil
+}
+
+func resetModels() {
+	modelsMutex.Lock()
+	defer modelsMutex.Unlock()
+	models = map[reflect.Type]goar.ActiveRecordInterfacer{}
+}
@@ -0,0 +1,117 @@
+package schema
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type SchemaVehicle struct {
+	schemaModel
+	VIN          string     `json:"vin" schema:"size=17,unique"`
+	Make         string     `json:"make" schema:"index=idx_make_model"`
+	Model        string     `json:"model" schema:"index=idx_make_model,null"`
+	Year         int        `json:"year" gorethink:"year" xorm:"'model_year'"`
+	SafetyRating *float64   `json:"safety_rating"`
+	SoldAt       time.Time  `json:"sold_at" gorm:"column:sold_on"`
+	Options      []string   `json:"options"`
+	Notes        string     `schema:"-"`
+	Internal     string     `json:"-" gorm:"-" xorm:"-" gorethink:"-"`
+	ListedAt     *time.Time `json:"listed_at" schema:"notnull"`
+}
+
+type KeyedVehicle struct {
+	schemaModel
+	Dealer string `json:"dealer" schema:"hash"`
+	VIN    string `json:"vin" schema:"range"`
+}
+
+type ExpiringVehicle struct {
+	schemaModel
+	ExpiresAt int64 `json:"expires_at" schema:"ttl"`
+}
+
+type BadlySizedVehicle struct {
+	schemaModel
+	VIN string `schema:"size=lots"`
+}
+
+func (m *SchemaVehicle) TableName() string {
+	return "vehicles"
+}
+
+var _ = Describe("Schema", func() {
+
+	It("should name columns like each adapter's library", func() {
+		t := Of(&SchemaVehicle{}, Postgres)
+		Ω(t.Column("safety_rating")).ShouldNot(BeNil())
+		Ω(t.Column("sold_on")).ShouldNot(BeNil())
+		Ω(t.Column("created_at")).ShouldNot(BeNil())
+
+		t = Of(&SchemaVehicle{}, MSSQL)
+		Ω(t.Column("model_year")).ShouldNot(BeNil())
+		Ω(t.Column("v_i_n")).ShouldNot(BeNil())
+
+		t = Of(&SchemaVehicle{}, RethinkDB)
+		Ω(t.Column("year")).ShouldNot(BeNil())
+		Ω(t.Column("VIN")).ShouldNot(BeNil())
+
+		t = Of(&SchemaVehicle{}, DynamoDB)
+		Ω(t.Column("vin")).ShouldNot(BeNil())
+	})
+
+	It("should use TableName for the sql adapters and ModelName otherwise", func() {
+		Ω(Of(&SchemaVehicle{}, Postgres).Name).Should(Equal("vehicles"))
+		Ω(Of(&SchemaVehicle{}, RethinkDB).Name).Should(Equal("schema_vehicles"))
+	})
+
+	It("should skip excluded and embedded active record fields", func() {
+		t := Of(&SchemaVehicle{}, Postgres)
+		Ω(t.Column("notes")).Should(BeNil())
+		Ω(t.Column("internal")).Should(BeNil())
+		Ω(t.Column("validation")).Should(BeNil())
+		Ω(t.Columns).Should(HaveLen(11))
+	})
+
+	It("should declare nullability", func() {
+		t := Of(&SchemaVehicle{}, Postgres)
+		Ω(t.Column("id").Nullable).Should(BeFalse())
+		Ω(t.Column("make").Nullable).Should(BeFalse())
+		Ω(t.Column("model").Nullable).Should(BeTrue())
+		Ω(t.Column("safety_rating").Nullable).Should(BeTrue())
+		Ω(t.Column("updated_at").Nullable).Should(BeTrue())
+		Ω(t.Column("listed_at").Nullable).Should(BeFalse())
+	})
+
+	It("should declare single and composite indexes", func() {
+		t := Of(&SchemaVehicle{}, Postgres)
+		Ω(t.Indexes).Should(Equal([]Index{
+			{Name: "idx_vehicles_vin", Columns: []string{"vin"}, Unique: true},
+			{Name: "idx_make_model", Columns: []string{"make", "model"}},
+		}))
+	})
+
+	It("should declare dynamodb keys", func() {
+		t := Of(&SchemaVehicle{}, DynamoDB)
+		Ω(t.Column("id").KeyType).Should(Equal("HASH"))
+		Ω(t.Column("id").Type).Should(Equal("N"))
+
+		t = Of(&KeyedVehicle{}, DynamoDB)
+		Ω(t.Column("id").KeyType).Should(BeEmpty())
+		Ω(t.Column("dealer").KeyType).Should(Equal("HASH"))
+		Ω(t.Column("vin").KeyType).Should(Equal("RANGE"))
+	})
+
+	It("should declare dynamodb's ttl attribute", func() {
+		t := Of(&ExpiringVehicle{}, DynamoDB)
+		Ω(t.Column("expires_at").TTL).Should(BeTrue())
+		Ω(t.Column("expires_at").Type).Should(Equal("N"))
+		Ω(t.Column("id").TTL).Should(BeFalse())
+	})
+
+	It("should panic on an invalid tag or dialect", func() {
+		Ω(func() { Of(&BadlySizedVehicle{}, Postgres) }).Should(Panic())
+		Ω(func() { Of(&SchemaVehicle{}, Dialect("oracle")) }).Should(Panic())
+	})
+})
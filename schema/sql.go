@@ -0,0 +1,177 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// SQL returns the statements that create the table and its indexes, EX:
+//
+//	CREATE TABLE "vehicles" ("id" serial PRIMARY KEY, "vin" varchar(17) NOT NULL, ...)
+//	CREATE UNIQUE INDEX "idx_vehicles_vin" ON "vehicles" ("vin")
+func (t *Table) SQL() []string {
+	return append([]string{t.CreateTableSQL()}, t.CreateIndexSQL()...)
+}
+
+func (t *Table) CreateTableSQL() string {
+	definitions := []string{}
+	for _, c := range t.Columns {
+		definition := t.quote(c.Name) + " " + c.Type
+		if c.PrimaryKey && len(t.PrimaryKey()) == 1 {
+			definition += " PRIMARY KEY"
+		} else if !c.Nullable {
+			definition += " NOT NULL"
+		}
+		definitions = append(definitions, definition)
+	}
+
+	if keys := t.PrimaryKey(); len(keys) > 1 {
+		definitions = append(definitions, "PRIMARY KEY ("+t.quoteAll(keys)+")")
+	}
+
+	return fmt.Sprintf("CREATE TABLE %s (%s)", t.quote(t.Name), strings.Join(definitions, ", "))
+}
+
+func (t *Table) CreateIndexSQL() []string {
+	statements := []string{}
+	for _, index := range t.Indexes {
+		unique := ""
+		if index.Unique {
+			unique = "UNIQUE "
+		}
+		statements = append(statements, fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)", unique, t.quote(index.Name), t.quote(t.Name), t.quoteAll(index.Columns)))
+	}
+
+	return statements
+}
+
+func (t *Table) quote(identifier string) string {
+	if t.Dialect == MSSQL {
+		return "[" + strings.Replace(identifier, "]", "]]", -1) + "]"
+	}
+
+	return `"` + strings.Replace(identifier, `"`, `""`, -1) + `"`
+}
+
+func (t *Table) quoteAll(identifiers []string) string {
+	quoted := []string{}
+	for _, identifier := range identifiers {
+		quoted = append(quoted, t.quote(identifier))
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+// columnType maps a field's go type onto the dialect's column type
+func columnType(t reflect.Type, dialect Dialect, size int, autoIncrement bool) string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch dialect {
+	case Postgres:
+		return postgresType(t, size, autoIncrement)
+	case MSSQL:
+		return mssqlType(t, size, autoIncrement)
+	case DynamoDB:
+		return dynamodbType(t)
+	}
+
+	return "" // rethinkdb is schemaless
+}
+
+func postgresType(t reflect.Type, size int, autoIncrement bool) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int8, reflect.Int16, reflect.Uint8:
+		return "smallint"
+	case reflect.Int32, reflect.Uint16:
+		if autoIncrement {
+			return "serial"
+		}
+		return "integer"
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		if autoIncrement { // NOTE: ArPostgres.ID is an int, which gorm creates as a serial
+			return "serial"
+		}
+		return "bigint"
+	case reflect.Float32:
+		return "real"
+	case reflect.Float64:
+		return "double precision"
+	case reflect.String:
+		if size > 0 {
+			return fmt.Sprintf("varchar(%d)", size)
+		}
+		return "text"
+	case reflect.Struct:
+		if t == reflect.TypeOf(time.Time{}) {
+			return "timestamp with time zone"
+		}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "bytea"
+		}
+	}
+
+	return "jsonb" // structs, slices, maps and interfaces
+}
+
+func mssqlType(t reflect.Type, size int, autoIncrement bool) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "bit"
+	case reflect.Int8, reflect.Uint8:
+		return "tinyint"
+	case reflect.Int16:
+		return "smallint"
+	case reflect.Int, reflect.Int32, reflect.Uint16:
+		if autoIncrement {
+			return "int IDENTITY(1,1)"
+		}
+		return "int"
+	case reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		if autoIncrement {
+			return "bigint IDENTITY(1,1)"
+		}
+		return "bigint"
+	case reflect.Float32:
+		return "real"
+	case reflect.Float64:
+		return "float"
+	case reflect.String:
+		if size > 0 {
+			return fmt.Sprintf("nvarchar(%d)", size)
+		}
+		return "nvarchar(max)"
+	case reflect.Struct:
+		if t == reflect.TypeOf(time.Time{}) {
+			return "datetime"
+		}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "varbinary(max)"
+		}
+	}
+
+	return "nvarchar(max)" // structs, slices, maps and interfaces are stored as json
+}
+
+// dynamodbType returns the attribute type, which only matters for key attributes
+func dynamodbType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "N"
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "B"
+		}
+	}
+
+	return "S"
+}
@@ -0,0 +1,36 @@
+package schema
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SQL", func() {
+
+	It("should create postgres tables and indexes", func() {
+		statements := Of(&SchemaVehicle{}, Postgres).SQL()
+		Ω(statements).Should(HaveLen(3))
+		Ω(statements[0]).Should(Equal(`CREATE TABLE "vehicles" (` +
+			`"id" serial PRIMARY KEY, "created_at" timestamp with time zone, "updated_at" timestamp with time zone, ` +
+			`"vin" varchar(17) NOT NULL, "make" text NOT NULL, "model" text, "year" bigint NOT NULL, ` +
+			`"safety_rating" double precision, "sold_on" timestamp with time zone NOT NULL, "options" jsonb, ` +
+			`"listed_at" timestamp with time zone NOT NULL)`))
+		Ω(statements[1]).Should(Equal(`CREATE UNIQUE INDEX "idx_vehicles_vin" ON "vehicles" ("vin")`))
+		Ω(statements[2]).Should(Equal(`CREATE INDEX "idx_make_model" ON "vehicles" ("make", "model")`))
+	})
+
+	It("should create mssql tables", func() {
+		statement := Of(&SchemaVehicle{}, MSSQL).CreateTableSQL()
+		Ω(statement).Should(HavePrefix("CREATE TABLE [vehicles] ([i_d] int IDENTITY(1,1) PRIMARY KEY, [created_at] datetime,"))
+		Ω(statement).Should(ContainSubstring("[v_i_n] nvarchar(17) NOT NULL, [make] nvarchar(max) NOT NULL"))
+		Ω(statement).Should(ContainSubstring("[model_year] int NOT NULL"))
+	})
+
+	It("should declare a composite primary key", func() {
+		t := Of(&KeyedVehicle{}, Postgres)
+		t.Column("id").PrimaryKey = false
+		t.Column("dealer").PrimaryKey = true
+		t.Column("vin").PrimaryKey = true
+		Ω(t.CreateTableSQL()).Should(HaveSuffix(`"dealer" text NOT NULL, "vin" text NOT NULL, PRIMARY KEY ("dealer", "vin"))`))
+	})
+})
@@ -0,0 +1,270 @@
+package goar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Session is an identity map scoped to a unit of work, EX: a single http request.
+// Loading the same record twice through a session returns the same instance,
+// and Flush persists every instance that changed since it was loaded.
+type Session struct {
+	mutex     sync.Mutex
+	instances map[string]ActiveRecordInterfacer
+	snapshots map[string][]byte
+	order     []string
+	added     int // new instances, which are tracked by a placeholder key until they're saved
+}
+
+type sessionContextKey struct{}
+
+func NewSession() *Session {
+	return &Session{
+		instances: map[string]ActiveRecordInterfacer{},
+		snapshots: map[string][]byte{},
+	}
+}
+
+// WithSession returns a copy of ctx that carries the session
+func WithSession(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, sessionContextKey{}, s)
+}
+
+// SessionFromContext returns the session carried by ctx, or nil
+func SessionFromContext(ctx context.Context) *Session {
+	s, _ := ctx.Value(sessionContextKey{}).(*Session)
+	return s
+}
+
+// Find returns the instance already loaded for the model's type and id, otherwise
+// it loads the record via the model's Find and tracks it.
+// EX: vehicle, err := session.Find(Vehicle{}.ToActiveRecord(), "vin-1")
+func (s *Session) Find(ari ActiveRecordInterfacer, id interface{}) (ActiveRecordInterfacer, error) {
+	key := cacheKey(ari, fmt.Sprint(id))
+
+	s.mutex.Lock()
+	instance, found := s.instances[key]
+	s.mutex.Unlock()
+	if found {
+		return instance, nil
+	}
+
+	instance = newInstanceOf(ari)
+
+	if err := instance.Find(id, instance); err != nil {
+		return nil, err
+	}
+
+	snapshot, err := json.Marshal(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if loaded, found := s.instances[key]; found { // loaded concurrently, so the first one wins
+		return loaded, nil
+	}
+
+	s.track(key, instance, snapshot)
+	return instance, nil
+}
+
+// Add tracks a new instance so that the next Flush saves it.  Instances without an ID
+// are inserted by the Flush and then tracked by the ID their adapter assigned.
+func (s *Session) Add(ari ActiveRecordInterfacer) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, instance := range s.instances {
+		if instance == ari { // already tracked
+			return nil
+		}
+	}
+
+	id, ok := modelID(ari)
+	if !ok {
+		s.added++
+		s.track(fmt.Sprintf("%s%d", newInstanceKeyPrefix, s.added), ari, nil)
+		return nil
+	}
+
+	key := cacheKey(ari, id)
+	if instance, found := s.instances[key]; found && instance != ari {
+		return errors.New("session: another instance is already loaded for " + key)
+	}
+
+	s.track(key, ari, nil)
+	return nil
+}
+
+// Delete deletes the instance and stops tracking it
+func (s *Session) Delete(ari ActiveRecordInterfacer) error {
+	if err := ari.Delete(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for key, instance := range s.instances {
+		if instance == ari {
+			s.untrack(key)
+		}
+	}
+
+	return nil
+}
+
+// Dirty returns the tracked instances that changed since they were loaded or last flushed
+func (s *Session) Dirty() ([]ActiveRecordInterfacer, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	dirty := []ActiveRecordInterfacer{}
+	for _, key := range s.order {
+		isDirty, err := s.isDirty(key)
+		if err != nil {
+			return nil, err
+		} else if isDirty {
+			dirty = append(dirty, s.instances[key])
+		}
+	}
+
+	return dirty, nil
+}
+
+// Flush saves every dirty instance in the order they were loaded or added.
+// It stops at the first instance that fails validation or can't be saved.
+func (s *Session) Flush() error {
+	s.mutex.Lock()
+	keys := append([]string{}, s.order...)
+	s.mutex.Unlock()
+
+	for _, key := range keys {
+		// NOTE: the lock isn't held while saving b/c callbacks may use the session
+		s.mutex.Lock()
+		instance, found := s.instances[key]
+		isDirty, err := s.isDirty(key)
+		s.mutex.Unlock()
+
+		if err != nil {
+			return err
+		} else if !found || !isDirty {
+			continue
+		}
+
+		success, err := instance.Save()
+		if err != nil {
+			return err
+		} else if !success {
+			return errors.New("session: validation failed for " + key)
+		}
+
+		// the save may have changed timestamps, so take a fresh snapshot
+		snapshot, err := json.Marshal(instance)
+		if err != nil {
+			return err
+		}
+
+		s.mutex.Lock()
+		if s.instances[key] == instance {
+			s.snapshots[key] = snapshot
+			err = s.identify(key)
+		}
+		s.mutex.Unlock()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Clear stops tracking every instance
+func (s *Session) Clear() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.instances = map[string]ActiveRecordInterfacer{}
+	s.snapshots = map[string][]byte{}
+	s.order = nil
+}
+
+func (s *Session) track(key string, ari ActiveRecordInterfacer, snapshot []byte) {
+	if _, found := s.instances[key]; !found {
+		s.order = append(s.order, key)
+	}
+	s.instances[key] = ari
+	s.snapshots[key] = snapshot
+}
+
+// newInstanceKeyPrefix is the placeholder key of instances added w/o an ID, EX: new:1
+const newInstanceKeyPrefix = "new:"
+
+// identify re-tracks an inserted instance by the ID its adapter assigned, so Find returns it
+func (s *Session) identify(key string) error {
+	if !strings.HasPrefix(key, newInstanceKeyPrefix) {
+		return nil
+	}
+
+	instance := s.instances[key]
+	id, ok := modelID(instance)
+	if !ok { // the adapter didn't assign one
+		return nil
+	}
+
+	identity := cacheKey(instance, id)
+	if loaded, found := s.instances[identity]; found && loaded != instance {
+		return errors.New("session: another instance is already loaded for " + identity)
+	}
+
+	for i, k := range s.order {
+		if k == key {
+			s.order[i] = identity
+			break
+		}
+	}
+	s.instances[identity], s.snapshots[identity] = instance, s.snapshots[key]
+	delete(s.instances, key)
+	delete(s.snapshots, key)
+
+	return nil
+}
+
+func (s *Session) untrack(key string) {
+	delete(s.instances, key)
+	delete(s.snapshots, key)
+	for i, k := range s.order {
+		if k == key {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+}
+
+func (s *Session) isDirty(key string) (bool, error) {
+	instance, found := s.instances[key]
+	if !found {
+		return false, nil
+	}
+
+	snapshot := s.snapshots[key]
+	if snapshot == nil { // added, but never saved
+		return true, nil
+	}
+
+	current, err := json.Marshal(instance)
+	if err != nil {
+		return false, err
+	}
+
+	return !bytes.Equal(current, snapshot), nil
+}
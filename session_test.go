@@ -0,0 +1,152 @@
+package goar
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type SessionAutomobile struct {
+	ActiveRecordAutomobile
+	ID string `json:"id,omitempty"`
+}
+
+var sessionAutomobileDb = map[string]SessionAutomobile{}
+var sessionAutomobileSaves = 0
+
+func (m *SessionAutomobile) Find(id interface{}, out interface{}) error {
+	row, found := sessionAutomobileDb[id.(string)]
+	if !found {
+		return errors.New("record not found")
+	}
+
+	out.(*SessionAutomobile).ID = row.ID
+	out.(*SessionAutomobile).Vehicle = row.Vehicle
+	return nil
+}
+
+func (m *SessionAutomobile) DbSave() error {
+	sessionAutomobileSaves++
+	if m.ID == "" { // assigned by the db, like an auto increment
+		m.ID = "auto" + strconv.Itoa(len(sessionAutomobileDb)+1)
+	}
+	sessionAutomobileDb[m.ID] = *m
+	return nil
+}
+
+func (m *SessionAutomobile) DbDelete() error {
+	delete(sessionAutomobileDb, m.ID)
+	return nil
+}
+
+var _ = Describe("Session", func() {
+
+	var session *Session
+
+	BeforeEach(func() {
+		session = NewSession()
+		sessionAutomobileSaves = 0
+		auto := SessionAutomobile{ID: "carrera"}
+		auto.Year, auto.Make, auto.Model = 2007, "porsche", "carrera gt"
+		sessionAutomobileDb = map[string]SessionAutomobile{"carrera": auto}
+	})
+
+	It("should return the already loaded instance", func() {
+		first, err := session.Find(ToAR(&SessionAutomobile{}), "carrera")
+		Ω(err).NotTo(HaveOccurred())
+		Ω(first.(*SessionAutomobile).Model).Should(Equal("carrera gt"))
+
+		second, err := session.Find(ToAR(&SessionAutomobile{}), "carrera")
+		Ω(err).NotTo(HaveOccurred())
+		Ω(second == first).Should(BeTrue())
+	})
+
+	It("should not share instances between sessions", func() {
+		first, _ := session.Find(ToAR(&SessionAutomobile{}), "carrera")
+		other, _ := NewSession().Find(ToAR(&SessionAutomobile{}), "carrera")
+		Ω(other == first).Should(BeFalse())
+	})
+
+	It("should return the model's find error", func() {
+		_, err := session.Find(ToAR(&SessionAutomobile{}), "does not exist")
+		Ω(err).To(HaveOccurred())
+	})
+
+	It("should flush only dirty instances", func() {
+		loaded, _ := session.Find(ToAR(&SessionAutomobile{}), "carrera")
+		Ω(session.Dirty()).Should(BeEmpty())
+		Ω(session.Flush()).Should(Succeed())
+		Ω(sessionAutomobileSaves).Should(Equal(0))
+
+		loaded.(*SessionAutomobile).Model = "918 spyder"
+		Ω(session.Dirty()).Should(HaveLen(1))
+		Ω(session.Flush()).Should(Succeed())
+		Ω(sessionAutomobileSaves).Should(Equal(1))
+		Ω(sessionAutomobileDb["carrera"].Model).Should(Equal("918 spyder"))
+
+		Ω(session.Dirty()).Should(BeEmpty())
+		Ω(session.Flush()).Should(Succeed())
+		Ω(sessionAutomobileSaves).Should(Equal(1))
+	})
+
+	It("should flush added instances", func() {
+		auto := ToAR(&SessionAutomobile{ID: "model s"}).(*SessionAutomobile)
+		auto.Year, auto.Make, auto.Model = 2014, "tesla", "model s"
+		Ω(session.Add(auto)).Should(Succeed())
+
+		Ω(session.Flush()).Should(Succeed())
+		Ω(sessionAutomobileDb).Should(HaveKey("model s"))
+
+		found, _ := session.Find(ToAR(&SessionAutomobile{}), "model s")
+		Ω(found == auto).Should(BeTrue())
+	})
+
+	It("should insert added instances without an ID, then track them by the assigned ID", func() {
+		auto := ToAR(&SessionAutomobile{}).(*SessionAutomobile)
+		auto.Year, auto.Make, auto.Model = 1960, "austin healey", "sprite"
+		Ω(session.Add(auto)).Should(Succeed())
+		Ω(session.Add(auto)).Should(Succeed())
+		Ω(session.Dirty()).Should(HaveLen(1))
+
+		Ω(session.Flush()).Should(Succeed())
+		Ω(sessionAutomobileSaves).Should(Equal(1))
+		Ω(auto.ID).Should(Equal("auto2"))
+		Ω(sessionAutomobileDb).Should(HaveKey("auto2"))
+
+		found, err := session.Find(ToAR(&SessionAutomobile{}), "auto2")
+		Ω(err).NotTo(HaveOccurred())
+		Ω(found == auto).Should(BeTrue())
+
+		auto.Model = "3000"
+		Ω(session.Flush()).Should(Succeed())
+		Ω(sessionAutomobileSaves).Should(Equal(2))
+		Ω(sessionAutomobileDb).Should(HaveLen(2))
+	})
+
+	It("should return an error when a dirty instance is invalid", func() {
+		loaded, _ := session.Find(ToAR(&SessionAutomobile{}), "carrera")
+		loaded.(*SessionAutomobile).Make = ""
+
+		err := session.Flush()
+		Ω(err).To(HaveOccurred())
+		Ω(sessionAutomobileSaves).Should(Equal(0))
+	})
+
+	It("should delete and stop tracking an instance", func() {
+		loaded, _ := session.Find(ToAR(&SessionAutomobile{}), "carrera")
+		Ω(session.Delete(loaded)).Should(Succeed())
+		Ω(sessionAutomobileDb).ShouldNot(HaveKey("carrera"))
+
+		_, err := session.Find(ToAR(&SessionAutomobile{}), "carrera")
+		Ω(err).To(HaveOccurred())
+	})
+
+	It("should carry the session on a context", func() {
+		ctx := WithSession(context.Background(), session)
+		Ω(SessionFromContext(ctx) == session).Should(BeTrue())
+		Ω(SessionFromContext(context.Background())).Should(BeNil())
+	})
+})
@@ -0,0 +1,283 @@
+package goar
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ShardMap maps a shard key onto one of several connection names
+// that share the same adapter, EX: rethinkdb events1, events2, events3
+type ShardMap interface {
+	Shard(key string) (connName string)
+	Shards() (connNames []string)
+}
+
+// Sharder is implemented by models that are partitioned across several connections.
+// Find(id) locates the shard by the id, so models sharded on another field
+// should be looked up via Where(...).Run(...) instead.
+// The adapters' Find reads the shard the id maps to, not the finder's own.
+// NOTE: only the rethinkdb adapter runs All and Where queries across every shard;
+// the others return an error rather than query a single shard.
+type Sharder interface {
+	ShardMap() ShardMap
+	ShardKey() string // the value used to place this instance on a shard
+}
+
+// HashRing is a consistent hashing ShardMap.  Adding a connection only
+// moves roughly 1/n of the keys onto the new shard.
+type HashRing struct {
+	connNames []string
+	hashes    []uint32
+	nodes     map[uint32]string
+}
+
+// NewHashRing creates a ring with the given number of virtual nodes per connection
+func NewHashRing(connNames []string, replicas int) *HashRing {
+	if replicas < 1 {
+		replicas = 1
+	}
+
+	ring := &HashRing{connNames: connNames, nodes: map[uint32]string{}}
+	for _, connName := range connNames {
+		for i := 0; i < replicas; i++ {
+			hash := crc32.ChecksumIEEE([]byte(strconv.Itoa(i) + "_" + connName))
+			ring.hashes = append(ring.hashes, hash)
+			ring.nodes[hash] = connName
+		}
+	}
+	sort.Sort(uint32s(ring.hashes))
+
+	return ring
+}
+
+func (ring *HashRing) Shard(key string) string {
+	if len(ring.hashes) == 0 {
+		return ""
+	}
+
+	hash := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(ring.hashes), func(i int) bool { return ring.hashes[i] >= hash })
+	if i == len(ring.hashes) { // wrap around the ring
+		i = 0
+	}
+
+	return ring.nodes[ring.hashes[i]]
+}
+
+func (ring *HashRing) Shards() []string {
+	return ring.connNames
+}
+
+// ShardRange assigns every key less than or equal to UpperBound (and greater
+// than the previous range's bound) to ConnName.  A blank UpperBound is unbounded.
+type ShardRange struct {
+	UpperBound string
+	ConnName   string
+}
+
+// RangeShardMap is an ordered list of key ranges
+type RangeShardMap []ShardRange
+
+func (ranges RangeShardMap) Shard(key string) string {
+	for _, rng := range ranges {
+		if rng.UpperBound == "" || key <= rng.UpperBound {
+			return rng.ConnName
+		}
+	}
+
+	return ""
+}
+
+func (ranges RangeShardMap) Shards() []string {
+	connNames := []string{}
+	for _, rng := range ranges {
+		connNames = append(connNames, rng.ConnName)
+	}
+
+	return connNames
+}
+
+// FanOut calls fn for every shard in parallel and returns the first error encountered
+func FanOut(shards []string, fn func(connName string) error) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(shards))
+
+	for i, connName := range shards {
+		wg.Add(1)
+		go func(i int, connName string) {
+			defer wg.Done()
+			errs[i] = fn(connName)
+		}(i, connName)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return errors.New(fmt.Sprintf("shard %s: %v", shards[i], err))
+		}
+	}
+
+	return nil
+}
+
+// MergeShardRows merges the rows returned by each shard the way a single
+// database would have returned them: sorted by the query's OrderBys and
+// truncated to limit (a limit less than 1 means no limit)
+func MergeShardRows(shardRows [][]map[string]interface{}, orderBys []OrderBy, limit int) []map[string]interface{} {
+	rows := []map[string]interface{}{}
+	for _, r := range shardRows {
+		rows = append(rows, r...)
+	}
+
+	if len(orderBys) > 0 {
+		sort.Stable(&rowSorter{rows: rows, orderBys: orderBys})
+	}
+
+	if limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+	}
+
+	return rows
+}
+
+// QueryLimit parses the query's limit, returning 0 when no limit was specified
+func QueryLimit(query *Query) (int, error) {
+	if query == nil || query.Limit == "" {
+		return 0, nil
+	}
+
+	limit, err := strconv.Atoi(query.Limit)
+	if err != nil || limit < 1 {
+		return 0, errors.New(fmt.Sprintf("invalid query limit: %s", query.Limit))
+	}
+
+	return limit, nil
+}
+
+type rowSorter struct {
+	rows     []map[string]interface{}
+	orderBys []OrderBy
+}
+
+func (s *rowSorter) Len() int      { return len(s.rows) }
+func (s *rowSorter) Swap(i, j int) { s.rows[i], s.rows[j] = s.rows[j], s.rows[i] }
+func (s *rowSorter) Less(i, j int) bool {
+	for _, orderBy := range s.orderBys {
+		c := compareValues(s.rows[i][orderBy.Key], s.rows[j][orderBy.Key])
+		if c == 0 {
+			continue
+		}
+		if orderBy.SortOrder == DESC {
+			return c > 0
+		}
+		return c < 0
+	}
+
+	return false
+}
+
+// compareValues orders nil before bools before numbers before times before strings,
+// which mirrors how the document stores order mixed types
+func compareValues(a, b interface{}) int {
+	ra, rb := valueRank(a), valueRank(b)
+	if ra != rb {
+		return ra - rb
+	}
+
+	switch va := a.(type) {
+	case bool:
+		vb := b.(bool)
+		if va == vb {
+			return 0
+		} else if !va {
+			return -1
+		}
+		return 1
+	case time.Time:
+		vb := b.(time.Time)
+		if va.Before(vb) {
+			return -1
+		} else if va.After(vb) {
+			return 1
+		}
+		return 0
+	case string:
+		vb := b.(string)
+		if va < vb {
+			return -1
+		} else if va > vb {
+			return 1
+		}
+		return 0
+	}
+
+	if ra == 2 {
+		fa, fb := toFloat(a), toFloat(b)
+		if fa < fb {
+			return -1
+		} else if fa > fb {
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func valueRank(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return 2
+	case time.Time:
+		return 3
+	case string:
+		return 4
+	}
+
+	return 5
+}
+
+func toFloat(v interface{}) float64 {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int8:
+		return float64(n)
+	case int16:
+		return float64(n)
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case uint:
+		return float64(n)
+	case uint8:
+		return float64(n)
+	case uint16:
+		return float64(n)
+	case uint32:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	case float32:
+		return float64(n)
+	case float64:
+		return n
+	}
+
+	return 0
+}
+
+type uint32s []uint32
+
+func (s uint32s) Len() int           { return len(s) }
+func (s uint32s) Less(i, j int) bool { return s[i] < s[j] }
+func (s uint32s) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,166 @@
+package goar
+
+import (
+	"errors"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type ShardedAutomobile struct {
+	ActiveRecordAutomobile
+	VIN string
+}
+
+var shardedAutomobileShards = NewHashRing([]string{"events1", "events2", "events3"}, 50)
+
+func (m *ShardedAutomobile) ShardMap() ShardMap {
+	return shardedAutomobileShards
+}
+
+func (m *ShardedAutomobile) ShardKey() string {
+	return m.VIN
+}
+
+var _ = Describe("Sharding", func() {
+
+	Context("Hash Ring", func() {
+		It("should consistently map a key onto a shard", func() {
+			ring := NewHashRing([]string{"a", "b", "c"}, 50)
+			Ω(ring.Shard("vin-1")).ShouldNot(BeEmpty())
+			Ω(ring.Shard("vin-1")).Should(Equal(ring.Shard("vin-1")))
+			Ω(ring.Shards()).Should(Equal([]string{"a", "b", "c"}))
+		})
+
+		It("should spread keys across every shard", func() {
+			ring := NewHashRing([]string{"a", "b", "c"}, 50)
+			counts := map[string]int{}
+			for i := 0; i < 300; i++ {
+				counts[ring.Shard(string(rune('a'+i%26))+string(rune('a'+i/26)))]++
+			}
+			Ω(counts).Should(HaveLen(3))
+		})
+
+		It("should only move a fraction of the keys when a shard is added", func() {
+			before := NewHashRing([]string{"a", "b", "c"}, 50)
+			after := NewHashRing([]string{"a", "b", "c", "d"}, 50)
+			moved := 0
+			for i := 0; i < 1000; i++ {
+				key := string(rune('a'+i%26)) + string(rune('a'+i/26%26)) + string(rune('a'+i/676))
+				if before.Shard(key) != after.Shard(key) {
+					Ω(after.Shard(key)).Should(Equal("d"))
+					moved++
+				}
+			}
+			Ω(moved).Should(BeNumerically("<", 500))
+		})
+
+		It("should return a blank shard for an empty ring", func() {
+			Ω(NewHashRing(nil, 0).Shard("vin-1")).Should(BeEmpty())
+		})
+	})
+
+	Context("Range Shard Map", func() {
+		It("should map a key onto the first range that contains it", func() {
+			ranges := RangeShardMap{
+				{UpperBound: "h", ConnName: "a_to_h"},
+				{UpperBound: "p", ConnName: "i_to_p"},
+				{ConnName: "q_to_z"},
+			}
+
+			Ω(ranges.Shard("cobra")).Should(Equal("a_to_h"))
+			Ω(ranges.Shard("mustang")).Should(Equal("i_to_p"))
+			Ω(ranges.Shard("viper")).Should(Equal("q_to_z"))
+			Ω(ranges.Shards()).Should(Equal([]string{"a_to_h", "i_to_p", "q_to_z"}))
+		})
+	})
+
+	Context("Routing", func() {
+		It("should route a sharded model by its shard key", func() {
+			auto := &ShardedAutomobile{VIN: "WP0CA2988XS650219"}
+			ToAR(auto)
+
+			connName, env := ResolveConnection(auto)
+			Ω(connName).Should(Equal(shardedAutomobileShards.Shard("WP0CA2988XS650219")))
+			Ω(env).Should(Equal("test"))
+		})
+
+		It("should prefer the instance connection over the shard", func() {
+			auto := &ShardedAutomobile{VIN: "WP0CA2988XS650219"}
+			ToAR(auto).(*ShardedAutomobile).UseConnection("archive", "")
+
+			connName, _ := ResolveConnection(auto)
+			Ω(connName).Should(Equal("archive"))
+		})
+	})
+
+	Context("Fan Out", func() {
+		It("should call every shard", func() {
+			var mutex sync.Mutex
+			called := []string{}
+			err := FanOut([]string{"a", "b", "c"}, func(connName string) error {
+				mutex.Lock()
+				called = append(called, connName)
+				mutex.Unlock()
+				return nil
+			})
+
+			Ω(err).NotTo(HaveOccurred())
+			Ω(called).Should(ConsistOf("a", "b", "c"))
+		})
+
+		It("should return a shard's error", func() {
+			err := FanOut([]string{"a", "b"}, func(connName string) error {
+				if connName == "b" {
+					return errors.New("some error")
+				}
+				return nil
+			})
+
+			Ω(err).To(HaveOccurred())
+			Ω(err.Error()).Should(Equal("shard b: some error"))
+		})
+	})
+
+	Context("Merging", func() {
+		shardRows := func() [][]map[string]interface{} {
+			return [][]map[string]interface{}{
+				{{"Make": "tesla", "Year": 2014.0}, {"Make": "austin healey", "Year": 1960.0}},
+				{{"Make": "porsche", "Year": 2007}, {"Make": "austin healey", "Year": 1959}},
+			}
+		}
+
+		It("should merge rows in order", func() {
+			rows := MergeShardRows(shardRows(), []OrderBy{{Key: "Make"}, {Key: "Year", SortOrder: DESC}}, 0)
+			Ω(rows).Should(HaveLen(4))
+			Ω(rows[0]["Year"]).Should(Equal(1960.0))
+			Ω(rows[1]["Year"]).Should(Equal(1959))
+			Ω(rows[2]["Make"]).Should(Equal("porsche"))
+			Ω(rows[3]["Make"]).Should(Equal("tesla"))
+		})
+
+		It("should apply the limit after merging", func() {
+			rows := MergeShardRows(shardRows(), []OrderBy{{Key: "Year", SortOrder: DESC}}, 2)
+			Ω(rows).Should(HaveLen(2))
+			Ω(rows[0]["Make"]).Should(Equal("tesla"))
+			Ω(rows[1]["Make"]).Should(Equal("porsche"))
+		})
+
+		It("should parse the query limit", func() {
+			auto := validAutomobileFactory()
+			limit, err := QueryLimit(auto.Query())
+			Ω(err).NotTo(HaveOccurred())
+			Ω(limit).Should(Equal(0))
+
+			auto.Limit(25)
+			limit, err = QueryLimit(auto.Query())
+			Ω(err).NotTo(HaveOccurred())
+			Ω(limit).Should(Equal(25))
+
+			auto.Query().Limit = "lots"
+			_, err = QueryLimit(auto.Query())
+			Ω(err).To(HaveOccurred())
+		})
+	})
+})
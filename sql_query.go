@@ -0,0 +1,81 @@
+package goar
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// sqlKey is a column name, EX: safety_rating, which is all a query key can be, b/c it's not a placeholder
+var sqlKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+var sqlOperators = map[EnumRelationalOperators]string{EQ: "=", NE: "<>", LT: "<", LTE: "<=", GT: ">", GTE: ">="}
+
+// SQLWhere compiles the query's where conditions for the sql adapters' DbSearch, w/ ? placeholders, EX:
+//
+//	make = ? AND year IN (?, ?)
+//
+// Conditions are combined in order, like the other adapters, so a OR b AND c is (a OR b) AND c
+func SQLWhere(query *Query) (string, []interface{}, error) {
+	where, args := "", []interface{}{}
+	for i, condition := range query.WhereConditions {
+		if !sqlKey.MatchString(condition.Key) {
+			return "", nil, fmt.Errorf("invalid query key: %q", condition.Key)
+		}
+
+		var clause string
+		switch op := condition.RelationalOperator; {
+		case op == IN: // any of the values, EX: Field.In
+			values, ok := condition.Value.([]interface{})
+			if !ok || len(values) == 0 {
+				return "", nil, fmt.Errorf("%s IN needs a list of values, EX: Field.In", condition.Key)
+			}
+			clause = condition.Key + " IN (?" + strings.Repeat(", ?", len(values)-1) + ")"
+			args = append(args, values...)
+		case condition.Value == nil && (op == EQ || op == NE):
+			clause = condition.Key + " IS NULL"
+			if op == NE {
+				clause = condition.Key + " IS NOT NULL"
+			}
+		case sqlOperators[op] != "":
+			clause = condition.Key + " " + sqlOperators[op] + " ?"
+			args = append(args, condition.Value)
+		default:
+			return "", nil, errors.New(fmt.Sprintf("invalid comparison operator: %v", op))
+		}
+
+		if i == 0 {
+			where = clause
+			continue
+		}
+		switch condition.LogicalOperator {
+		case OR:
+			where = "(" + where + ") OR " + clause
+		case NOT:
+			where = "(" + where + ") AND NOT " + clause
+		default:
+			where = "(" + where + ") AND " + clause
+		}
+	}
+
+	return where, args, nil
+}
+
+// SQLOrder compiles the query's order bys, EX: year DESC, make ASC
+func SQLOrder(query *Query) (string, error) {
+	orders := []string{}
+	for _, orderBy := range query.OrderBys {
+		if !sqlKey.MatchString(orderBy.Key) {
+			return "", fmt.Errorf("invalid order by key: %q", orderBy.Key)
+		}
+
+		if orderBy.SortOrder == DESC {
+			orders = append(orders, orderBy.Key+" DESC")
+		} else {
+			orders = append(orders, orderBy.Key+" ASC")
+		}
+	}
+
+	return strings.Join(orders, ", "), nil
+}
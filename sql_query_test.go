@@ -0,0 +1,45 @@
+package goar
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SQL Query", func() {
+	var q *Query
+
+	BeforeEach(func() {
+		q = NewQuery()
+	})
+
+	It("should compile the where conditions w/ placeholders", func() {
+		q.WhereConditions = []QueryCondition{
+			Field{Key: "make"}.In("tesla", "porsche"),
+			{LogicalOperator: AND, Key: "year", RelationalOperator: GTE, Value: 2010},
+			{LogicalOperator: OR, Key: "deleted_at", RelationalOperator: NE},
+		}
+
+		where, args, err := SQLWhere(q)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(where).Should(Equal("((make IN (?, ?)) AND year >= ?) OR deleted_at IS NOT NULL"))
+		Ω(args).Should(Equal([]interface{}{"tesla", "porsche", 2010}))
+	})
+
+	It("should reject keys that aren't column names, and IN w/o values", func() {
+		q.WhereConditions = []QueryCondition{{Key: "make = '' OR 1=1 --", RelationalOperator: EQ, Value: "tesla"}}
+		_, _, err := SQLWhere(q)
+		Ω(err).Should(HaveOccurred())
+
+		q.WhereConditions = []QueryCondition{Field{Key: "make"}.In()}
+		_, _, err = SQLWhere(q)
+		Ω(err).Should(MatchError("make IN needs a list of values, EX: Field.In"))
+	})
+
+	It("should compile the order bys", func() {
+		q.OrderBys = []OrderBy{{Key: "year", SortOrder: DESC}, {Key: "make"}}
+
+		order, err := SQLOrder(q)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(order).Should(Equal("year DESC, make ASC"))
+	})
+})
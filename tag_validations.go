@@ -0,0 +1,241 @@
+package goar
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Fields can declare their validations via the goar tag, which ActiveRecord.Valid
+// applies before calling the model's own Validate(), EX:
+//
+//	type Vehicle struct {
+//		Year  int    `json:"year" goar:"required,min=1900,max=2100"`
+//		VIN   string `json:"vin" goar:"required,match=^[A-Z0-9]{17}$"`
+//		Email string `json:"email" goar:"email"`
+//	}
+//
+// min and max bound numbers, or the length of strings, slices and maps.
+// match takes the rest of the tag, so its regex may contain commas, and must be the last rule.
+// Errors are keyed by the field's json name.
+//
+// A field's rules can be restricted to validation contexts and to conditions
+// checked via bool methods on the model, EX:
+//
+//	Password    string `goar:"required,on=create"`
+//	Description string `goar:"on=publish|update,if=IsListed,unless=IsDraft,required,min=20"`
+
+type tagRule struct {
+	name   string
+	number float64
+	regex  *regexp.Regexp
+}
+
+type fieldRules struct {
+	index  []int
+	key    string
+	rules  []tagRule
+	on     []string // validation contexts, EX: create, update, publish
+	when   string   // the names of bool methods that must return true
+	unless string   // and false respectively
+}
+
+var (
+	tagRulesCache = map[reflect.Type][]fieldRules{}
+	tagRulesMutex sync.RWMutex
+)
+
+func (ar *ActiveRecord) validateTags() {
+	v := reflect.ValueOf(ar.self).Elem()
+	for _, field := range tagRulesFor(v.Type()) {
+		if !ar.appliesTo(field) {
+			continue
+		}
+
+		f := v.FieldByIndex(field.index)
+		value, isNil := f, f.Kind() == reflect.Ptr && f.IsNil()
+		if f.Kind() == reflect.Ptr && !isNil {
+			value = f.Elem()
+		}
+
+		for _, rule := range field.rules {
+			if rule.name == "required" {
+				var required interface{} // a nil pointer, which Required would see as a value
+				if !isNil {
+					required = value.Interface()
+				}
+				ar.withCode(ar.Validation.Required(field.key, required), ErrRequired, nil)
+			} else if !isNil { // only required applies to nil values, wherever it's declared
+				applyTagRule(ar, field.key, value, rule)
+			}
+		}
+	}
+}
+
+func applyTagRule(ar *ActiveRecord, key string, f reflect.Value, rule tagRule) {
+	switch rule.name {
+	case "min", "max":
+		switch f.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if rule.name == "min" {
+				ar.withCode(ar.Validation.Min(key, int(f.Int()), int(rule.number)), ErrTooSmall, map[string]interface{}{"min": rule.number})
+			} else {
+				ar.withCode(ar.Validation.Max(key, int(f.Int()), int(rule.number)), ErrTooLarge, map[string]interface{}{"max": rule.number})
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if rule.name == "min" {
+				ar.withCode(ar.Validation.Min(key, int(f.Uint()), int(rule.number)), ErrTooSmall, map[string]interface{}{"min": rule.number})
+			} else {
+				ar.withCode(ar.Validation.Max(key, int(f.Uint()), int(rule.number)), ErrTooLarge, map[string]interface{}{"max": rule.number})
+			}
+		case reflect.Float32, reflect.Float64:
+			if rule.name == "min" && f.Float() < rule.number {
+				ar.withCode(ar.Validation.Error(key, "%s", fmt.Sprintln("Minimum is", rule.number)), ErrTooSmall, map[string]interface{}{"min": rule.number})
+			} else if rule.name == "max" && f.Float() > rule.number {
+				ar.withCode(ar.Validation.Error(key, "%s", fmt.Sprintln("Maximum is", rule.number)), ErrTooLarge, map[string]interface{}{"max": rule.number})
+			}
+		case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+			if rule.name == "min" {
+				ar.withCode(ar.Validation.MinSize(key, f.Interface(), int(rule.number)), ErrTooShort, map[string]interface{}{"min": rule.number})
+			} else {
+				ar.withCode(ar.Validation.MaxSize(key, f.Interface(), int(rule.number)), ErrTooLong, map[string]interface{}{"max": rule.number})
+			}
+		}
+	case "email":
+		if f.Kind() == reflect.String && f.String() != "" { // blank values are left to required
+			ar.withCode(ar.Validation.Email(key, f.String()), ErrInvalidEmail, nil)
+		}
+	case "match":
+		if f.Kind() == reflect.String && f.String() != "" {
+			ar.withCode(ar.Validation.Match(key, f.String(), rule.regex), ErrInvalidFormat, map[string]interface{}{"pattern": rule.regex.String()})
+		}
+	}
+}
+
+// tagRulesFor parses the type's goar tags once and caches the result
+func tagRulesFor(t reflect.Type) []fieldRules {
+	tagRulesMutex.RLock()
+	rules, found := tagRulesCache[t]
+	tagRulesMutex.RUnlock()
+	if found {
+		return rules
+	}
+
+	rules = parseTagRules(t, nil)
+
+	tagRulesMutex.Lock()
+	tagRulesCache[t] = rules
+	tagRulesMutex.Unlock()
+
+	return rules
+}
+
+func parseTagRules(t reflect.Type, index []int) []fieldRules {
+	fields := []fieldRules{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && f.Type != reflect.TypeOf(ActiveRecord{}) {
+			fields = append(fields, parseTagRules(f.Type, fieldIndex)...)
+			continue
+		}
+
+		tag := f.Tag.Get("goar")
+		if tag == "" || f.PkgPath != "" {
+			continue
+		}
+
+		field := fieldRules{index: fieldIndex, key: jsonName(f)}
+		parseTag(&field, t, f.Name, tag)
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+// parseTag panics on an invalid tag b/c it's a programming error, EX: goar:"min=one"
+func parseTag(field *fieldRules, t reflect.Type, fieldName string, tag string) {
+	for tag != "" {
+		var part string
+		if strings.HasPrefix(tag, "match=") {
+			part, tag = tag, ""
+		} else if i := strings.Index(tag, ","); i >= 0 {
+			part, tag = tag[:i], tag[i+1:]
+		} else {
+			part, tag = tag, ""
+		}
+
+		name, arg := part, ""
+		if i := strings.Index(part, "="); i >= 0 {
+			name, arg = part[:i], part[i+1:]
+		}
+
+		rule := tagRule{name: strings.TrimSpace(name)}
+		var err error
+		switch rule.name {
+		case "on":
+			field.on = strings.Split(arg, "|")
+			continue
+		case "if":
+			field.when = arg
+			continue
+		case "unless":
+			field.unless = arg
+			continue
+		case "required", "email":
+		case "min", "max":
+			rule.number, err = strconv.ParseFloat(arg, 64)
+		case "match":
+			rule.regex, err = regexp.Compile(arg)
+		default:
+			err = fmt.Errorf("unknown rule %q", rule.name)
+		}
+
+		if err != nil {
+			log.Panicf("invalid goar tag on %s.%s: %v", t.Name(), fieldName, err)
+		}
+		field.rules = append(field.rules, rule)
+	}
+}
+
+// appliesTo checks the field's validation contexts and conditions
+func (ar *ActiveRecord) appliesTo(field fieldRules) bool {
+	if len(field.on) > 0 {
+		found := false
+		for _, name := range field.on {
+			found = found || ar.ValidatingOn(name)
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if field.when != "" && !ar.condition(field.when) {
+		return false
+	}
+
+	return field.unless == "" || !ar.condition(field.unless)
+}
+
+// condition calls a bool method on the model, EX: func (m *Vehicle) IsListed() bool
+func (ar *ActiveRecord) condition(method string) bool {
+	m := reflect.ValueOf(ar.self).MethodByName(method)
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 || m.Type().Out(0).Kind() != reflect.Bool {
+		log.Panicf("invalid goar tag on %s: %s must be a method that returns a bool", ar.self.ModelName(), method)
+	}
+
+	return m.Call(nil)[0].Bool()
+}
+
+func jsonName(f reflect.StructField) string {
+	if name := strings.Split(f.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+		return name
+	}
+
+	return f.Name
+}
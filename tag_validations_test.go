@@ -0,0 +1,119 @@
+package goar
+
+import (
+	"reflect"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type TaggedAutomobile struct {
+	ActiveRecordAutomobile
+	VIN        string   `json:"vin,omitempty" goar:"required,match=^[A-Z0-9]{17}$"`
+	Horsepower int      `json:"horsepower" goar:"min=1,max=2000"`
+	Weight     float64  `json:"weight" goar:"max=10000.5"`
+	Owner      string   `json:"owner_email" goar:"email"`
+	Nickname   *string  `goar:"min=2"`
+	Options    []string `json:"options" goar:"max=2"`
+	Pattern    string   `json:"pattern" goar:"match=^a{1,3}$"`
+}
+
+type TrimmedAutomobile struct {
+	ActiveRecordAutomobile
+	Trim *string `json:"trim" goar:"min=2,required"`
+}
+
+type BadlyTaggedAutomobile struct {
+	ActiveRecordAutomobile
+	Horsepower int `goar:"min=lots"`
+}
+
+func validTaggedAutomobile() *TaggedAutomobile {
+	auto := ToAR(&TaggedAutomobile{VIN: "WP0CA2988XS650219", Horsepower: 605, Weight: 3042, Owner: "obie@example.com"}).(*TaggedAutomobile)
+	auto.Year, auto.Make, auto.Model = 2007, "porsche", "carrera gt"
+	return auto
+}
+
+var _ = Describe("Tag Validations", func() {
+
+	It("should accept a valid model", func() {
+		Ω(validTaggedAutomobile().Valid()).Should(BeTrue())
+	})
+
+	It("should report errors with the json field names", func() {
+		auto := validTaggedAutomobile()
+		auto.VIN = ""
+		auto.Horsepower = 0
+		auto.Weight = 20000
+		auto.Owner = "obie"
+		auto.Options = []string{"sport", "carbon", "leather"}
+
+		Ω(auto.Valid()).Should(BeFalse())
+		Ω(auto.Errors()).Should(HaveKey("vin"))
+		Ω(auto.Errors()).Should(HaveKey("horsepower"))
+		Ω(auto.Errors()).Should(HaveKey("weight"))
+		Ω(auto.Errors()).Should(HaveKey("owner_email"))
+		Ω(auto.Errors()).Should(HaveKey("options"))
+		Ω(auto.Errors()).ShouldNot(HaveKey("Nickname"))
+	})
+
+	It("should apply the model's own validations too", func() {
+		auto := validTaggedAutomobile()
+		auto.Make = ""
+		Ω(auto.Valid()).Should(BeFalse())
+		Ω(auto.Errors()).Should(HaveKey("Make"))
+	})
+
+	It("should validate regular expressions", func() {
+		auto := validTaggedAutomobile()
+		auto.VIN = "not a vin"
+		Ω(auto.Valid()).Should(BeFalse())
+		Ω(auto.Errors()).Should(HaveKey("vin"))
+
+		auto = validTaggedAutomobile()
+		auto.Pattern = "aa"
+		Ω(auto.Valid()).Should(BeTrue())
+		auto.Pattern = "aaaa"
+		Ω(auto.Valid()).Should(BeFalse())
+		Ω(auto.Errors()).Should(HaveKey("pattern"))
+	})
+
+	It("should dereference pointers", func() {
+		nickname := "x"
+		auto := validTaggedAutomobile()
+		auto.Nickname = &nickname
+		Ω(auto.Valid()).Should(BeFalse())
+		Ω(auto.Errors()).Should(HaveKey("Nickname"))
+	})
+
+	It("should require a nil pointer whatever the rule order", func() {
+		trimmed := func(trim *string) *TrimmedAutomobile {
+			auto := ToAR(&TrimmedAutomobile{Trim: trim}).(*TrimmedAutomobile)
+			auto.Year, auto.Make, auto.Model = 2007, "porsche", "carrera gt"
+			return auto
+		}
+
+		auto := trimmed(nil)
+		Ω(auto.Valid()).Should(BeFalse())
+		Ω(auto.Errors()["trim"].Message).Should(Equal("Required"))
+
+		trim := "gt"
+		Ω(trimmed(&trim).Valid()).Should(BeTrue())
+	})
+
+	It("should cache the rules per type", func() {
+		validTaggedAutomobile().Valid()
+
+		t := reflect.TypeOf(TaggedAutomobile{})
+		tagRulesMutex.RLock()
+		rules := tagRulesCache[t]
+		tagRulesMutex.RUnlock()
+		Ω(rules).Should(HaveLen(7))
+		Ω(rules[0].key).Should(Equal("vin"))
+	})
+
+	It("should panic on an invalid tag", func() {
+		auto := ToAR(&BadlyTaggedAutomobile{}).(*BadlyTaggedAutomobile)
+		Ω(func() { auto.Valid() }).Should(Panic())
+	})
+})
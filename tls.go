@@ -0,0 +1,106 @@
+package goar
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// TLS modes, which match postgres' sslmode
+const (
+	TLSDisable    = "disable"     // plain text, the default
+	TLSRequire    = "require"     // encrypted, w/o verifying the server's certificate
+	TLSVerifyCA   = "verify-ca"   // verifies the server's certificate was signed by CAFile, or the system's roots
+	TLSVerifyFull = "verify-full" // also verifies the certificate's host, which defaults to the server's address
+)
+
+// TLSConfig secures a connection, EX: {Mode: "verify-full", CAFile: "/etc/ssl/rds-ca.pem"}
+type TLSConfig struct {
+	Mode       string
+	CAFile     string // PEM bundle
+	CertFile   string // client certificate, PEM
+	KeyFile    string // client certificate's key, PEM
+	ServerName string // the host the server's certificate is verified against
+}
+
+func (t TLSConfig) Enabled() bool {
+	return t.Mode != "" && t.Mode != TLSDisable
+}
+
+// Verified reports whether the server's certificate is verified
+func (t TLSConfig) Verified() bool {
+	return t.Mode == TLSVerifyCA || t.Mode == TLSVerifyFull
+}
+
+// Validate checks the mode, and that a client certificate has its key
+func (t TLSConfig) Validate() error {
+	switch t.Mode {
+	case "", TLSDisable, TLSRequire, TLSVerifyCA, TLSVerifyFull:
+	default:
+		return fmt.Errorf("invalid tls mode %q, expected %s, %s, %s or %s", t.Mode, TLSDisable, TLSRequire, TLSVerifyCA, TLSVerifyFull)
+	}
+
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return errors.New("a tls client certificate needs both its cert and key files")
+	}
+
+	return nil
+}
+
+// Config builds the crypto/tls config for drivers that take one, EX: rethinkdb.
+// It's nil when TLS is disabled.
+func (t TLSConfig) Config() (*tls.Config, error) {
+	if err := t.Validate(); err != nil || !t.Enabled() {
+		return nil, err
+	}
+
+	c := &tls.Config{ServerName: t.ServerName, InsecureSkipVerify: t.Mode != TLSVerifyFull}
+
+	if t.CAFile != "" {
+		pem, err := ioutil.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		c.RootCAs = x509.NewCertPool()
+		if !c.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in tls ca file %s", t.CAFile)
+		}
+	}
+
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		c.Certificates = []tls.Certificate{cert}
+	}
+
+	// NOTE: go's tls either verifies the chain and host, or neither, so verify-ca checks the chain itself
+	if t.Mode == TLSVerifyCA {
+		roots := c.RootCAs
+		c.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			certs := make([]*x509.Certificate, len(rawCerts))
+			for i, raw := range rawCerts {
+				cert, err := x509.ParseCertificate(raw)
+				if err != nil {
+					return err
+				}
+				certs[i] = cert
+			}
+			if len(certs) == 0 {
+				return errors.New("the server sent no tls certificate")
+			}
+
+			opts := x509.VerifyOptions{Roots: roots, Intermediates: x509.NewCertPool()}
+			for _, cert := range certs[1:] {
+				opts.Intermediates.AddCert(cert)
+			}
+			_, err := certs[0].Verify(opts)
+			return err
+		}
+	}
+
+	return c, nil
+}
@@ -0,0 +1,116 @@
+package goar
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// certificate signs a certificate for host, or a CA when parent is nil
+func certificate(host string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Ω(err).NotTo(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if parent == nil {
+		template.IsCA, template.BasicConstraintsValid = true, true
+		template.KeyUsage = x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	Ω(err).NotTo(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	Ω(err).NotTo(HaveOccurred())
+
+	return cert, key
+}
+
+var _ = Describe("TLS", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "goar-tls")
+		Ω(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	writeCert := func(name string, cert *x509.Certificate) string {
+		path := filepath.Join(dir, name)
+		Ω(ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0600)).Should(Succeed())
+		return path
+	}
+
+	It("should validate the mode and client certificate", func() {
+		Ω(TLSConfig{}.Validate()).Should(Succeed())
+		Ω(TLSConfig{Mode: TLSVerifyFull}.Validate()).Should(Succeed())
+		Ω(TLSConfig{Mode: "sometimes"}.Validate()).ShouldNot(Succeed())
+		Ω(TLSConfig{Mode: TLSRequire, CertFile: "client.pem"}.Validate()).ShouldNot(Succeed())
+	})
+
+	It("should build no config when disabled", func() {
+		c, err := TLSConfig{Mode: TLSDisable}.Config()
+		Ω(err).NotTo(HaveOccurred())
+		Ω(c).Should(BeNil())
+	})
+
+	It("should skip verification for require", func() {
+		c, err := TLSConfig{Mode: TLSRequire}.Config()
+		Ω(err).NotTo(HaveOccurred())
+		Ω(c.InsecureSkipVerify).Should(BeTrue())
+		Ω(c.VerifyPeerCertificate).Should(BeNil())
+	})
+
+	It("should verify the host and CA for verify-full", func() {
+		ca, _ := certificate("ca", nil, nil)
+		c, err := TLSConfig{Mode: TLSVerifyFull, CAFile: writeCert("ca.pem", ca), ServerName: "db.internal"}.Config()
+		Ω(err).NotTo(HaveOccurred())
+		Ω(c.InsecureSkipVerify).Should(BeFalse())
+		Ω(c.ServerName).Should(Equal("db.internal"))
+		Ω(c.RootCAs.Subjects()).Should(HaveLen(1))
+	})
+
+	It("should verify only the CA for verify-ca", func() {
+		ca, caKey := certificate("ca", nil, nil)
+		server, _ := certificate("some-other-host", ca, caKey)
+		otherCA, otherCAKey := certificate("other-ca", nil, nil)
+		imposter, _ := certificate("some-other-host", otherCA, otherCAKey)
+
+		c, err := TLSConfig{Mode: TLSVerifyCA, CAFile: writeCert("ca.pem", ca)}.Config()
+		Ω(err).NotTo(HaveOccurred())
+		Ω(c.InsecureSkipVerify).Should(BeTrue())
+		Ω(c.VerifyPeerCertificate([][]byte{server.Raw}, nil)).Should(Succeed())
+		Ω(c.VerifyPeerCertificate([][]byte{imposter.Raw}, nil)).ShouldNot(Succeed())
+	})
+
+	It("should report a missing or empty CA file", func() {
+		_, err := TLSConfig{Mode: TLSVerifyFull, CAFile: filepath.Join(dir, "missing.pem")}.Config()
+		Ω(err).Should(HaveOccurred())
+
+		empty := filepath.Join(dir, "empty.pem")
+		Ω(ioutil.WriteFile(empty, []byte{}, 0600)).Should(Succeed())
+		_, err = TLSConfig{Mode: TLSVerifyFull, CAFile: empty}.Config()
+		Ω(err).Should(MatchError(ContainSubstring("no certificates")))
+	})
+})
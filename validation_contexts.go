@@ -0,0 +1,67 @@
+package goar
+
+import "reflect"
+
+// Every validation runs in either the create or the update context, plus any
+// named contexts passed to SaveWithContext or ValidWithContext.  EX:
+//
+//	func (m *Vehicle) Validate() {
+//		if m.ValidatingOn(goar.ValidateOnCreate) {
+//			m.Validation.Required("VIN", m.VIN)
+//		}
+//		if m.ValidatingOn("publish") {
+//			m.Validation.Required("Description", m.Description)
+//		}
+//	}
+//
+//	success, err := vehicle.SaveWithContext("publish")
+const (
+	ValidateOnCreate = "create"
+	ValidateOnUpdate = "update"
+)
+
+// SaveWithContext saves the model, validating it in the given named contexts
+func (ar *ActiveRecord) SaveWithContext(contexts ...string) (success bool, err error) {
+	ar.validationContexts = contexts
+	defer func() { ar.validationContexts = nil }()
+
+	return ar.Save()
+}
+
+// ValidWithContext validates the model in the given named contexts
+func (ar *ActiveRecord) ValidWithContext(contexts ...string) bool {
+	ar.validationContexts = contexts
+	defer func() { ar.validationContexts = nil }()
+
+	return ar.Valid()
+}
+
+// ValidationContexts returns create or update, followed by the named contexts
+func (ar *ActiveRecord) ValidationContexts() []string {
+	contexts := []string{ValidateOnUpdate}
+	if ar.isNewRecord() {
+		contexts[0] = ValidateOnCreate
+	}
+
+	return append(contexts, ar.validationContexts...)
+}
+
+func (ar *ActiveRecord) ValidatingOn(context string) bool {
+	for _, c := range ar.ValidationContexts() {
+		if c == context {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isNewRecord uses the CreatedAt timestamp when the model has one, otherwise the ID
+func (ar *ActiveRecord) isNewRecord() bool {
+	if f := reflect.ValueOf(ar.self).Elem().FieldByName("CreatedAt"); f.IsValid() {
+		return isBlank(f)
+	}
+
+	_, persisted := modelID(ar.self)
+	return !persisted
+}
@@ -0,0 +1,106 @@
+package goar
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type ListedAutomobile struct {
+	ActiveRecordAutomobile
+	Password    string `json:"password" goar:"required,on=create"`
+	Description string `json:"description" goar:"on=publish,required,min=10"`
+	Price       int    `json:"price" goar:"if=IsListed,unless=IsSold,required"`
+	Listed      bool
+	Sold        bool
+	Reviewer    string
+}
+
+func (m *ListedAutomobile) IsListed() bool {
+	return m.Listed
+}
+
+func (m *ListedAutomobile) IsSold() bool {
+	return m.Sold
+}
+
+func (m *ListedAutomobile) Validate() {
+	m.ActiveRecordAutomobile.Validate()
+	if m.ValidatingOn("publish") && m.ValidatingOn(ValidateOnUpdate) {
+		m.Validation.Required("Reviewer", m.Reviewer)
+	}
+}
+
+func newListedAutomobile() *ListedAutomobile {
+	auto := ToAR(&ListedAutomobile{Password: "secret"}).(*ListedAutomobile)
+	auto.Year, auto.Make, auto.Model = 2007, "porsche", "carrera gt"
+	return auto
+}
+
+func persistedListedAutomobile() *ListedAutomobile {
+	auto := newListedAutomobile()
+	now := time.Now()
+	auto.CreatedAt = &now
+	auto.Password = ""
+	return auto
+}
+
+var _ = Describe("Validation Contexts", func() {
+
+	It("should validate in the create context until the record is saved", func() {
+		auto := newListedAutomobile()
+		Ω(auto.ValidationContexts()).Should(Equal([]string{ValidateOnCreate}))
+		Ω(persistedListedAutomobile().ValidationContexts()).Should(Equal([]string{ValidateOnUpdate}))
+	})
+
+	It("should apply create rules only on create", func() {
+		auto := newListedAutomobile()
+		auto.Password = ""
+		Ω(auto.Valid()).Should(BeFalse())
+		Ω(auto.Errors()).Should(HaveKey("password"))
+
+		Ω(persistedListedAutomobile().Valid()).Should(BeTrue())
+	})
+
+	It("should apply named context rules only in that context", func() {
+		Ω(newListedAutomobile().Valid()).Should(BeTrue())
+
+		auto := newListedAutomobile()
+		Ω(auto.ValidWithContext("publish")).Should(BeFalse())
+		Ω(auto.Errors()).Should(HaveKey("description"))
+		Ω(auto.Errors()).ShouldNot(HaveKey("Reviewer"))
+
+		auto = persistedListedAutomobile()
+		Ω(auto.ValidWithContext("publish")).Should(BeFalse())
+		Ω(auto.Errors()).Should(HaveKey("Reviewer"))
+
+		auto = newListedAutomobile()
+		auto.Description = "mint condition"
+		Ω(auto.ValidWithContext("publish")).Should(BeTrue())
+		Ω(auto.ValidationContexts()).Should(Equal([]string{ValidateOnCreate}))
+	})
+
+	It("should save with a named context", func() {
+		auto := newListedAutomobile()
+		success, err := auto.SaveWithContext("publish")
+		Ω(err).NotTo(HaveOccurred())
+		Ω(success).Should(BeFalse())
+		Ω(auto.Errors()).Should(HaveKey("description"))
+
+		auto = newListedAutomobile()
+		auto.Description = "mint condition"
+		Ω(auto.SaveWithContext("publish")).Should(BeTrue())
+	})
+
+	It("should apply if and unless conditions", func() {
+		auto := newListedAutomobile()
+		auto.Listed = true
+		Ω(auto.Valid()).Should(BeFalse())
+		Ω(auto.Errors()).Should(HaveKey("price"))
+
+		auto = newListedAutomobile()
+		auto.Listed, auto.Sold = true, true
+		Ω(auto.Valid()).Should(BeTrue())
+	})
+})
@@ -0,0 +1,196 @@
+package goar
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	validations "github.com/obieq/goar-validations"
+)
+
+// ErrorCode is a stable, machine readable identifier for a validation error
+type ErrorCode string
+
+const (
+	ErrRequired      ErrorCode = "required"
+	ErrTooSmall      ErrorCode = "too_small"    // params: min
+	ErrTooLarge      ErrorCode = "too_large"    // params: max
+	ErrOutOfRange    ErrorCode = "out_of_range" // params: min, max
+	ErrTooShort      ErrorCode = "too_short"    // params: min
+	ErrTooLong       ErrorCode = "too_long"     // params: max
+	ErrWrongLength   ErrorCode = "wrong_length" // params: length
+	ErrInvalidFormat ErrorCode = "invalid_format"
+	ErrInvalidEmail  ErrorCode = "invalid_email"
+	ErrTaken         ErrorCode = "taken"
+	ErrNotFound      ErrorCode = "not_found"
+	ErrUnverified    ErrorCode = "unverified" // the db couldn't be queried
+	ErrUnknownField  ErrorCode = "unknown_field"
+	ErrInvalid       ErrorCode = "invalid" // any other error
+)
+
+// FieldError is a validation error with its code and parameters, EX:
+// {Field: "vin", Code: "too_short", Params: {"min": 17}, Message: "Minimum size is 17"}
+type FieldError struct {
+	Field   string
+	Code    ErrorCode
+	Params  map[string]interface{}
+	Message string // the message the error was reported with
+}
+
+type errorCode struct {
+	code   ErrorCode
+	params map[string]interface{}
+}
+
+// AddError reports an error with a code, using the code's english message.
+// EX: m.AddError("Price", "below_cost", map[string]interface{}{"cost": m.Cost})
+func (ar *ActiveRecord) AddError(field string, code ErrorCode, params map[string]interface{}) *validations.ValidationResult {
+	message := FieldError{Field: field, Code: code, Params: params}.LocalizedMessage(DefaultLocale)
+	return ar.withCode(ar.Validation.Error(field, "%s", message), code, params)
+}
+
+// FieldErrors returns every validation error in the order they were reported.
+// Errors reported via goar-validations' own methods, EX: Validation.Required,
+// get their code from the method's default message, or ErrInvalid when it was customized.
+func (ar *ActiveRecord) FieldErrors() []FieldError {
+	errs := []FieldError{}
+	for _, e := range ar.Validation.Errors {
+		fe := FieldError{Field: e.Key, Message: strings.TrimSpace(e.Message)}
+		if c, found := ar.errorCodes[e]; found {
+			fe.Code, fe.Params = c.code, c.params
+		} else {
+			fe.Code, fe.Params = inferErrorCode(fe.Message)
+		}
+		errs = append(errs, fe)
+	}
+
+	return errs
+}
+
+// withCode attaches a code to the error of a failed validation result
+func (ar *ActiveRecord) withCode(result *validations.ValidationResult, code ErrorCode, params map[string]interface{}) *validations.ValidationResult {
+	if result == nil || result.Error == nil {
+		return result
+	}
+
+	if ar.errorCodes == nil {
+		ar.errorCodes = map[*validations.ValidationError]errorCode{}
+	}
+	ar.errorCodes[result.Error] = errorCode{code: code, params: params}
+
+	return result
+}
+
+var defaultMessagePatterns = []struct {
+	pattern *regexp.Regexp
+	code    ErrorCode
+	params  []string
+}{
+	{regexp.MustCompile(`^Required$`), ErrRequired, nil},
+	{regexp.MustCompile(`^Minimum is (\S+)$`), ErrTooSmall, []string{"min"}},
+	{regexp.MustCompile(`^Maximum is (\S+)$`), ErrTooLarge, []string{"max"}},
+	{regexp.MustCompile(`^Range is (\S+) to (\S+)$`), ErrOutOfRange, []string{"min", "max"}},
+	{regexp.MustCompile(`^Minimum size is (\S+)$`), ErrTooShort, []string{"min"}},
+	{regexp.MustCompile(`^Maximum size is (\S+)$`), ErrTooLong, []string{"max"}},
+	{regexp.MustCompile(`^Required length is (\S+)$`), ErrWrongLength, []string{"length"}},
+	{regexp.MustCompile(`^Must match (.+)$`), ErrInvalidFormat, []string{"pattern"}},
+	{regexp.MustCompile(`^Must be a valid email address$`), ErrInvalidEmail, nil},
+}
+
+func inferErrorCode(message string) (ErrorCode, map[string]interface{}) {
+	for _, p := range defaultMessagePatterns {
+		if m := p.pattern.FindStringSubmatch(message); m != nil {
+			params := map[string]interface{}{}
+			for i, name := range p.params {
+				params[name] = m[i+1]
+			}
+			return p.code, params
+		}
+	}
+
+	return ErrInvalid, map[string]interface{}{}
+}
+
+// DefaultLocale is used when a message has no translation in the requested locale
+var DefaultLocale = "en"
+
+var (
+	messageCatalog = map[string]map[ErrorCode]string{
+		"en": {
+			ErrRequired:      "is required",
+			ErrTooSmall:      "must be at least {min}",
+			ErrTooLarge:      "must be at most {max}",
+			ErrOutOfRange:    "must be between {min} and {max}",
+			ErrTooShort:      "must be at least {min} characters",
+			ErrTooLong:       "must be at most {max} characters",
+			ErrWrongLength:   "must be exactly {length} characters",
+			ErrInvalidFormat: "is invalid",
+			ErrInvalidEmail:  "must be a valid email address",
+			ErrTaken:         "has already been taken",
+			ErrNotFound:      "does not exist",
+			ErrUnverified:    "could not be verified",
+			ErrUnknownField:  "is not a field",
+			ErrInvalid:       "is invalid",
+		},
+	}
+	messageCatalogMutex sync.RWMutex
+)
+
+// RegisterMessages adds or replaces message templates for a locale, EX:
+//
+//	goar.RegisterMessages("es", map[goar.ErrorCode]string{goar.ErrRequired: "es obligatorio"})
+//
+// Templates reference the error's params by name, EX: "must be at least {min}"
+func RegisterMessages(locale string, messages map[ErrorCode]string) {
+	messageCatalogMutex.Lock()
+	defer messageCatalogMutex.Unlock()
+
+	if messageCatalog[locale] == nil {
+		messageCatalog[locale] = map[ErrorCode]string{}
+	}
+	for code, message := range messages {
+		messageCatalog[locale][code] = message
+	}
+}
+
+// LocalizedMessage renders the error in the locale, falling back from EX: pt-BR
+// to pt to the DefaultLocale, and finally to the message the error was reported with
+func (e FieldError) LocalizedMessage(locale string) string {
+	messageCatalogMutex.RLock()
+	defer messageCatalogMutex.RUnlock()
+
+	for _, l := range localeFallbacks(locale) {
+		if template, found := messageCatalog[l][e.Code]; found {
+			return expandMessage(template, e.Params)
+		}
+	}
+
+	if e.Message != "" {
+		return e.Message
+	}
+
+	return string(e.Code)
+}
+
+func localeFallbacks(locale string) []string {
+	locales := []string{}
+	for locale != "" {
+		locales = append(locales, locale)
+		i := strings.LastIndexAny(locale, "-_")
+		if i < 0 {
+			break
+		}
+		locale = locale[:i]
+	}
+
+	return append(locales, DefaultLocale)
+}
+
+func expandMessage(template string, params map[string]interface{}) string {
+	for name, value := range params {
+		template = strings.Replace(template, "{"+name+"}", fmt.Sprint(value), -1)
+	}
+
+	return template
+}
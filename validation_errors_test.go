@@ -0,0 +1,53 @@
+package goar
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Validation Errors", func() {
+
+	It("should attach codes and params to tag validations", func() {
+		auto := validTaggedAutomobile()
+		auto.VIN = ""
+		auto.Horsepower = 0
+		Ω(auto.Valid()).Should(BeFalse())
+
+		errs := auto.FieldErrors()
+		Ω(errs).Should(HaveLen(2))
+		Ω(errs[0]).Should(Equal(FieldError{Field: "vin", Code: ErrRequired, Message: "Required"}))
+		Ω(errs[1].Code).Should(Equal(ErrTooSmall))
+		Ω(errs[1].Params).Should(Equal(map[string]interface{}{"min": float64(1)}))
+	})
+
+	It("should infer codes from goar-validations' default messages", func() {
+		auto := ToAR(&ActiveRecordAutomobile{}).(*ActiveRecordAutomobile)
+		auto.Validation.MinSize("Make", "vw", 3)
+		auto.Validation.Error("Model", "not a porsche")
+
+		errs := auto.FieldErrors()
+		Ω(errs[0].Code).Should(Equal(ErrTooShort))
+		Ω(errs[0].Params).Should(Equal(map[string]interface{}{"min": "3"}))
+		Ω(errs[1].Code).Should(Equal(ErrInvalid))
+		Ω(errs[1].LocalizedMessage("en")).Should(Equal("is invalid"))
+	})
+
+	It("should add errors with a code", func() {
+		auto := ToAR(&ActiveRecordAutomobile{}).(*ActiveRecordAutomobile)
+		auto.AddError("Year", ErrOutOfRange, map[string]interface{}{"min": 1900, "max": 2100})
+
+		Ω(auto.Errors()["Year"].Message).Should(Equal("must be between 1900 and 2100"))
+		Ω(auto.FieldErrors()[0].Code).Should(Equal(ErrOutOfRange))
+	})
+
+	It("should translate messages with locale fallbacks", func() {
+		RegisterMessages("pt", map[ErrorCode]string{ErrTooShort: "deve ter pelo menos {min} caracteres"})
+
+		e := FieldError{Field: "vin", Code: ErrTooShort, Params: map[string]interface{}{"min": 17}}
+		Ω(e.LocalizedMessage("pt-BR")).Should(Equal("deve ter pelo menos 17 caracteres"))
+		Ω(e.LocalizedMessage("fr")).Should(Equal("must be at least 17 characters"))
+
+		e = FieldError{Field: "vin", Code: "below_cost", Message: "Below cost"}
+		Ω(e.LocalizedMessage("pt")).Should(Equal("Below cost"))
+	})
+})
@@ -0,0 +1,316 @@
+package goar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+type VersionMode string
+
+const (
+	VersionSnapshot VersionMode = "snapshot" // every version stores the full record
+	VersionDiff     VersionMode = "diff"     // every version stores only the fields that changed
+)
+
+const (
+	VersionCreate = "create"
+	VersionUpdate = "update"
+	VersionDelete = "delete"
+)
+
+// Versioned is implemented by models whose creates, updates and deletes are
+// recorded in a version history, EX: func (v *Vehicle) VersionMode() goar.VersionMode { return goar.VersionSnapshot }
+type Versioned interface {
+	VersionMode() VersionMode
+}
+
+// Version is a single entry in a record's history.  Data holds the record's
+// json fields, or only the changed ones when Mode is VersionDiff.
+// NOTE: a field removed by a diff is stored with a nil value
+type Version struct {
+	ID        string                 `json:"id" gorethink:"id"`
+	ModelName string                 `json:"model_name" gorethink:"model_name"`
+	Key       string                 `json:"key" gorethink:"key"`
+	Number    int                    `json:"number" gorethink:"number"` // starts at 1
+	Operation string                 `json:"operation" gorethink:"operation"`
+	ActorID   string                 `json:"actor_id" gorethink:"actor_id"`
+	Mode      VersionMode            `json:"mode" gorethink:"mode"`
+	Data      map[string]interface{} `json:"data" gorethink:"data"`
+	CreatedAt time.Time              `json:"created_at" gorethink:"created_at"`
+}
+
+// VersionStore is implemented by the adapters that record version histories,
+// typically in a companion table or collection, EX: vehicles_versions
+type VersionStore interface {
+	WriteVersion(version *Version) error
+	// ReadVersions returns the record's versions ordered by Number
+	ReadVersions(key string) ([]*Version, error)
+}
+
+// FieldChange is a field that differs between two versions
+type FieldChange struct {
+	Field string
+	From  interface{}
+	To    interface{}
+}
+
+type actorContextKey struct{}
+
+// WithActor returns a copy of ctx that identifies who is making changes.
+// EX: auto.WithContext(goar.WithActor(ctx, user.ID)).Save()
+func WithActor(ctx context.Context, actorID string) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actorID)
+}
+
+// ActorFromContext returns the actor carried by ctx, or a blank string
+func ActorFromContext(ctx context.Context) string {
+	actorID, _ := ctx.Value(actorContextKey{}).(string)
+	return actorID
+}
+
+// WithContext sets the context used by this instance's subsequent operations,
+// EX: the actor recorded in the version history
+func (ar *ActiveRecord) WithContext(ctx context.Context) *ActiveRecord {
+	ar.ctx = ctx
+	return ar
+}
+
+func (ar *ActiveRecord) Context() context.Context {
+	if ar.ctx == nil {
+		return context.Background()
+	}
+
+	return ar.ctx
+}
+
+// Versions returns the record's history, oldest first
+func Versions(ari ActiveRecordInterfacer) ([]*Version, error) {
+	store, key, err := versionStore(ari)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.ReadVersions(key)
+}
+
+// DiffVersions lists the fields that changed between two of the record's versions
+func DiffVersions(ari ActiveRecordInterfacer, from int, to int) ([]FieldChange, error) {
+	versions, err := Versions(ari)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := versionState(versions, from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := versionState(versions, to)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, _ := ChangedFields(before, after) // maps always marshal
+	changes := []FieldChange{}
+	for _, field := range fields {
+		changes = append(changes, FieldChange{Field: field, From: before[field], To: after[field]})
+	}
+
+	return changes, nil
+}
+
+// RevertToVersion restores the record's fields to the given version and saves it,
+// which records a new version.  A deleted record can be restored this way too.
+func RevertToVersion(ari ActiveRecordInterfacer, number int) (success bool, err error) {
+	versions, err := Versions(ari)
+	if err != nil {
+		return false, err
+	}
+
+	for _, version := range versions {
+		if version.Number == number && version.Operation == VersionDelete {
+			return false, errors.New(fmt.Sprintf("version %d is a delete.  revert to the version before it instead", number))
+		}
+	}
+
+	state, err := versionState(versions, number)
+	if err != nil {
+		return false, err
+	}
+
+	// reset the fields so the ones missing from the version are cleared
+	v := reflect.ValueOf(ari).Elem()
+	for name := range fieldsOf(ari) {
+		if f := v.FieldByName(name); f.IsValid() && f.CanSet() {
+			f.Set(reflect.Zero(f.Type()))
+		}
+	}
+
+	b, err := json.Marshal(state)
+	if err != nil {
+		return false, err
+	}
+	if err = json.Unmarshal(b, ari); err != nil {
+		return false, err
+	}
+
+	return ari.Save()
+}
+
+// recordVersion is called by Save and Delete once the db operation has succeeded
+func recordVersion(ari ActiveRecordInterfacer, deleted bool) error {
+	v, ok := ari.(Versioned)
+	if !ok {
+		return nil
+	}
+
+	store, key, err := versionStore(ari)
+	if err != nil {
+		return err
+	}
+
+	// NOTE: concurrent saves of the same record may record the same version number
+	versions, err := store.ReadVersions(key)
+	if err != nil {
+		return err
+	}
+
+	previous := map[string]interface{}{}
+	operation := VersionCreate
+	if n := len(versions); n > 0 && versions[n-1].Operation != VersionDelete {
+		operation = VersionUpdate
+		if previous, err = versionState(versions, versions[n-1].Number); err != nil {
+			return err
+		}
+	}
+
+	current, err := fieldValues(ari)
+	if err != nil {
+		return err
+	}
+
+	data := current
+	if deleted {
+		operation = VersionDelete
+		data = map[string]interface{}{}
+	}
+
+	if v.VersionMode() == VersionDiff {
+		fields, _ := ChangedFields(previous, data)
+		diff := map[string]interface{}{}
+		for _, field := range fields {
+			diff[field] = data[field]
+		}
+		data = diff
+	} else if deleted {
+		data = current // keep the final state
+	}
+
+	actorID := ""
+	if ar, ok := activeRecordOf(ari); ok {
+		actorID = ActorFromContext(ar.Context())
+	}
+
+	return store.WriteVersion(&Version{
+		ID:        newEventID(),
+		ModelName: ari.ModelName(),
+		Key:       key,
+		Number:    len(versions) + 1,
+		Operation: operation,
+		ActorID:   actorID,
+		Mode:      v.VersionMode(),
+		Data:      data,
+		CreatedAt: time.Now().UTC(),
+	})
+}
+
+// checkVersioned fails fast when a model is versioned but its adapter can't record versions
+func checkVersioned(ari ActiveRecordInterfacer) error {
+	if _, ok := ari.(Versioned); !ok {
+		return nil
+	}
+	if _, ok := ari.(VersionStore); !ok {
+		return errors.New(fmt.Sprintf("%s: the adapter does not support versioning", ari.ModelName()))
+	}
+
+	return nil
+}
+
+func versionStore(ari ActiveRecordInterfacer) (VersionStore, string, error) {
+	store, ok := ari.(VersionStore)
+	if !ok {
+		return nil, "", errors.New(fmt.Sprintf("%s: the adapter does not support versioning", ari.ModelName()))
+	}
+
+	key, ok := modelID(ari)
+	if !ok {
+		return nil, "", errors.New(fmt.Sprintf("%s: cannot version a model without an ID", ari.ModelName()))
+	}
+
+	return store, key, nil
+}
+
+// versionState rebuilds the record as of the given version number
+func versionState(versions []*Version, number int) (map[string]interface{}, error) {
+	sort.Sort(byVersionNumber(versions))
+
+	state := map[string]interface{}{}
+	for _, version := range versions {
+		if version.Mode == VersionDiff {
+			if version.Operation == VersionCreate {
+				state = map[string]interface{}{}
+			}
+			for field, value := range version.Data {
+				if value == nil {
+					delete(state, field)
+				} else {
+					state[field] = value
+				}
+			}
+		} else if version.Operation == VersionDelete {
+			state = map[string]interface{}{}
+		} else {
+			state = map[string]interface{}{}
+			for field, value := range version.Data {
+				state[field] = value
+			}
+		}
+
+		if version.Number == number {
+			return state, nil
+		}
+	}
+
+	return nil, errors.New(fmt.Sprintf("version %d not found", number))
+}
+
+// fieldsOf returns the names of the struct fields that are serialized to json
+func fieldsOf(ari ActiveRecordInterfacer) map[string]bool {
+	fields := map[string]bool{}
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.Type == reflect.TypeOf(ActiveRecord{}) {
+				continue
+			} else if f.Anonymous && f.Type.Kind() == reflect.Struct {
+				walk(f.Type)
+			} else if f.PkgPath == "" && f.Tag.Get("json") != "-" {
+				fields[f.Name] = true
+			}
+		}
+	}
+	walk(reflect.TypeOf(ari).Elem())
+
+	return fields
+}
+
+type byVersionNumber []*Version
+
+func (s byVersionNumber) Len() int           { return len(s) }
+func (s byVersionNumber) Less(i, j int) bool { return s[i].Number < s[j].Number }
+func (s byVersionNumber) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
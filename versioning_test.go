@@ -0,0 +1,281 @@
+package goar
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type VersionedAutomobile struct {
+	ActiveRecordAutomobile
+	ID    string `json:"id,omitempty"`
+	Color string `json:"color,omitempty"`
+	mode  VersionMode
+}
+
+type UnstoredVersionedAutomobile struct {
+	ActiveRecordAutomobile
+	ID string `json:"id,omitempty"`
+}
+
+func (m *UnstoredVersionedAutomobile) VersionMode() VersionMode {
+	return VersionSnapshot
+}
+
+var versionedAutomobileDb = struct {
+	sync.Mutex
+	rows     map[string]VersionedAutomobile
+	versions map[string][]*Version
+}{}
+
+func (m *VersionedAutomobile) VersionMode() VersionMode {
+	return m.mode
+}
+
+func (m *VersionedAutomobile) DbSave() error {
+	versionedAutomobileDb.Lock()
+	defer versionedAutomobileDb.Unlock()
+
+	versionedAutomobileDb.rows[m.ID] = *m
+	return nil
+}
+
+func (m *VersionedAutomobile) DbDelete() error {
+	versionedAutomobileDb.Lock()
+	defer versionedAutomobileDb.Unlock()
+
+	delete(versionedAutomobileDb.rows, m.ID)
+	return nil
+}
+
+func (m *VersionedAutomobile) WriteVersion(version *Version) error {
+	versionedAutomobileDb.Lock()
+	defer versionedAutomobileDb.Unlock()
+
+	// round trip through json like a db would
+	data, _ := fieldValues(version.Data)
+	copied := *version
+	copied.Data = data
+	versionedAutomobileDb.versions[version.Key] = append(versionedAutomobileDb.versions[version.Key], &copied)
+	return nil
+}
+
+func (m *VersionedAutomobile) ReadVersions(key string) ([]*Version, error) {
+	versionedAutomobileDb.Lock()
+	defer versionedAutomobileDb.Unlock()
+
+	return append([]*Version{}, versionedAutomobileDb.versions[key]...), nil
+}
+
+// FlakyVersionedAutomobile is cached, and its version store fails once failing is set
+type FlakyVersionedAutomobile struct {
+	ActiveRecordAutomobile
+	ID    string `json:"id,omitempty"`
+	Color string `json:"color,omitempty"`
+}
+
+var flakyVersionedAutomobileDb = struct {
+	sync.Mutex
+	rows       map[string]FlakyVersionedAutomobile
+	versions   []*Version
+	loads      int
+	afterSaves int
+	failing    bool
+}{}
+
+var errVersionStore = errors.New("version store unavailable")
+
+func (m *FlakyVersionedAutomobile) VersionMode() VersionMode { return VersionSnapshot }
+func (m *FlakyVersionedAutomobile) Cache() Cache             { return automobileCache }
+
+func (m *FlakyVersionedAutomobile) AfterSave() error {
+	flakyVersionedAutomobileDb.Lock()
+	defer flakyVersionedAutomobileDb.Unlock()
+
+	flakyVersionedAutomobileDb.afterSaves++
+	return nil
+}
+
+func (m *FlakyVersionedAutomobile) Find(id interface{}, out interface{}) error {
+	return ReadThrough(m, id, out, func() error {
+		flakyVersionedAutomobileDb.Lock()
+		defer flakyVersionedAutomobileDb.Unlock()
+
+		flakyVersionedAutomobileDb.loads++
+		row, found := flakyVersionedAutomobileDb.rows[id.(string)]
+		if !found {
+			return errors.New("record not found")
+		}
+		*out.(*FlakyVersionedAutomobile) = row
+		return nil
+	})
+}
+
+func (m *FlakyVersionedAutomobile) DbSave() error {
+	flakyVersionedAutomobileDb.Lock()
+	defer flakyVersionedAutomobileDb.Unlock()
+
+	flakyVersionedAutomobileDb.rows[m.ID] = *m
+	return nil
+}
+
+func (m *FlakyVersionedAutomobile) DbDelete() error {
+	flakyVersionedAutomobileDb.Lock()
+	defer flakyVersionedAutomobileDb.Unlock()
+
+	delete(flakyVersionedAutomobileDb.rows, m.ID)
+	return nil
+}
+
+func (m *FlakyVersionedAutomobile) WriteVersion(version *Version) error {
+	flakyVersionedAutomobileDb.Lock()
+	defer flakyVersionedAutomobileDb.Unlock()
+
+	if flakyVersionedAutomobileDb.failing {
+		return errVersionStore
+	}
+	flakyVersionedAutomobileDb.versions = append(flakyVersionedAutomobileDb.versions, version)
+	return nil
+}
+
+func (m *FlakyVersionedAutomobile) ReadVersions(key string) ([]*Version, error) {
+	flakyVersionedAutomobileDb.Lock()
+	defer flakyVersionedAutomobileDb.Unlock()
+
+	if flakyVersionedAutomobileDb.failing {
+		return nil, errVersionStore
+	}
+	return append([]*Version{}, flakyVersionedAutomobileDb.versions...), nil
+}
+
+var _ = Describe("Versioning", func() {
+
+	var auto *VersionedAutomobile
+
+	for _, mode := range []VersionMode{VersionSnapshot, VersionDiff} {
+		mode := mode
+
+		Context(string(mode), func() {
+			BeforeEach(func() {
+				versionedAutomobileDb.rows = map[string]VersionedAutomobile{}
+				versionedAutomobileDb.versions = map[string][]*Version{}
+
+				auto = ToAR(&VersionedAutomobile{ID: "carrera", mode: mode}).(*VersionedAutomobile)
+				auto.Year, auto.Make, auto.Model, auto.Color = 2007, "porsche", "carrera gt", "silver"
+				auto.WithContext(WithActor(context.Background(), "obie"))
+				Ω(auto.Save()).Should(BeTrue())
+
+				auto.Model = "918 spyder"
+				auto.Color = ""
+				auto.WithContext(WithActor(context.Background(), "gigi"))
+				Ω(auto.Save()).Should(BeTrue())
+			})
+
+			It("should record a version for every create, update and delete", func() {
+				Ω(auto.Delete()).Should(Succeed())
+
+				versions, err := Versions(auto)
+				Ω(err).NotTo(HaveOccurred())
+				Ω(versions).Should(HaveLen(3))
+
+				Ω(versions[0].Number).Should(Equal(1))
+				Ω(versions[0].Operation).Should(Equal(VersionCreate))
+				Ω(versions[0].ActorID).Should(Equal("obie"))
+				Ω(versions[0].Mode).Should(Equal(mode))
+				Ω(versions[0].Data["color"]).Should(Equal("silver"))
+
+				Ω(versions[1].Number).Should(Equal(2))
+				Ω(versions[1].Operation).Should(Equal(VersionUpdate))
+				Ω(versions[1].ActorID).Should(Equal("gigi"))
+				Ω(versions[1].CreatedAt.IsZero()).Should(BeFalse())
+
+				Ω(versions[2].Number).Should(Equal(3))
+				Ω(versions[2].Operation).Should(Equal(VersionDelete))
+			})
+
+			It("should diff two versions", func() {
+				changes, err := DiffVersions(auto, 1, 2)
+				Ω(err).NotTo(HaveOccurred())
+
+				byField := map[string]FieldChange{}
+				for _, change := range changes {
+					byField[change.Field] = change
+				}
+				Ω(byField["model"]).Should(Equal(FieldChange{Field: "model", From: "carrera gt", To: "918 spyder"}))
+				Ω(byField["color"]).Should(Equal(FieldChange{Field: "color", From: "silver", To: nil}))
+				Ω(byField).ShouldNot(HaveKey("make"))
+
+				_, err = DiffVersions(auto, 1, 5)
+				Ω(err).To(HaveOccurred())
+			})
+
+			It("should revert a record to a prior version", func() {
+				Ω(RevertToVersion(auto, 1)).Should(BeTrue())
+				Ω(auto.Model).Should(Equal("carrera gt"))
+				Ω(auto.Color).Should(Equal("silver"))
+				Ω(versionedAutomobileDb.rows["carrera"].Model).Should(Equal("carrera gt"))
+
+				versions, _ := Versions(auto)
+				Ω(versions).Should(HaveLen(3))
+				Ω(versions[2].Operation).Should(Equal(VersionUpdate))
+			})
+
+			It("should restore a deleted record", func() {
+				Ω(auto.Delete()).Should(Succeed())
+
+				_, err := RevertToVersion(auto, 3)
+				Ω(err).To(HaveOccurred())
+
+				Ω(RevertToVersion(auto, 2)).Should(BeTrue())
+				Ω(versionedAutomobileDb.rows["carrera"].Model).Should(Equal("918 spyder"))
+
+				versions, _ := Versions(auto)
+				Ω(versions[3].Operation).Should(Equal(VersionCreate))
+			})
+		})
+	}
+
+	It("should invalidate the cache and run AfterSave when the version can't be recorded", func() {
+		flakyVersionedAutomobileDb.rows = map[string]FlakyVersionedAutomobile{}
+		flakyVersionedAutomobileDb.versions = nil
+		flakyVersionedAutomobileDb.loads, flakyVersionedAutomobileDb.afterSaves = 0, 0
+		flakyVersionedAutomobileDb.failing = false
+
+		auto := ToAR(&FlakyVersionedAutomobile{ID: "carrera", Color: "silver"}).(*FlakyVersionedAutomobile)
+		auto.Year, auto.Make, auto.Model = 2007, "porsche", "carrera gt"
+		Ω(auto.Save()).Should(BeTrue())
+
+		var out FlakyVersionedAutomobile
+		Ω(ToAR(&FlakyVersionedAutomobile{}).Find("carrera", &out)).Should(Succeed())
+		Ω(flakyVersionedAutomobileDb.loads).Should(Equal(1))
+
+		flakyVersionedAutomobileDb.failing = true
+		auto.Color = "black"
+		success, err := auto.Save()
+		Ω(success).Should(BeFalse())
+		Ω(err).Should(Equal(errVersionStore))
+		Ω(flakyVersionedAutomobileDb.afterSaves).Should(Equal(2))
+
+		Ω(ToAR(&FlakyVersionedAutomobile{}).Find("carrera", &out)).Should(Succeed())
+		Ω(out.Color).Should(Equal("black"))
+		Ω(flakyVersionedAutomobileDb.loads).Should(Equal(2))
+
+		Ω(auto.Delete()).Should(Equal(errVersionStore))
+		Ω(ToAR(&FlakyVersionedAutomobile{}).Find("carrera", &out)).ShouldNot(Succeed())
+	})
+
+	It("should not save a versioned model whose adapter can't record versions", func() {
+		auto := ToAR(&UnstoredVersionedAutomobile{ID: "carrera"}).(*UnstoredVersionedAutomobile)
+		auto.Year, auto.Make, auto.Model = 2007, "porsche", "carrera gt"
+
+		success, err := auto.Save()
+		Ω(success).Should(BeFalse())
+		Ω(err).To(HaveOccurred())
+
+		_, err = Versions(auto)
+		Ω(err).To(HaveOccurred())
+	})
+})